@@ -10,19 +10,49 @@ import (
 	"time"
 
 	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
 	"github.com/shekshuev/gophertalk-backend/internal/handler"
 	"github.com/shekshuev/gophertalk-backend/internal/repository"
 	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/storage"
+	"github.com/shekshuev/gophertalk-backend/internal/worker"
 )
 
 func main() {
 	cfg := config.GetConfig()
-	userRepo := repository.NewUserRepositoryImpl(&cfg)
-	postRepo := repository.NewPostRepositoryImpl(&cfg)
+	var eventBackend events.Backend
+	if cfg.StreamBackend == "postgres" {
+		eventBackend = events.NewPostgresBackend(cfg.DatabaseDSN, cfg.StreamRetention)
+	} else {
+		eventBackend = events.NewMemoryBackend(cfg.StreamReplaySize)
+	}
+	hub := events.NewHub(eventBackend, cfg.StreamBufferSize, cfg.PresenceTTL)
+	userRepo := repository.NewUserRepositoryImpl(&cfg, hub)
+	postRepo := repository.NewPostRepositoryImpl(&cfg, hub)
+	mediaRepo := repository.NewMediaRepositoryImpl(&cfg)
+	listRepo := repository.NewListRepositoryImpl(&cfg)
+	notificationRepo := repository.NewNotificationRepositoryImpl(&cfg)
+	webhookRepo := repository.NewWebhookRepositoryImpl(&cfg)
+	chatRepo := repository.NewChatRepositoryImpl(&cfg, hub)
+	var blobStore storage.BlobStore
+	if cfg.MediaStorage == "s3" {
+		blobStore = storage.NewS3BlobStore(
+			cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PublicURL)
+	} else {
+		blobStore = storage.NewLocalBlobStore(cfg.MediaLocalPath, cfg.MediaBaseURL)
+	}
 	userService := service.NewUserServiceImpl(userRepo, &cfg)
 	authService := service.NewAuthServiceImpl(userRepo, &cfg)
 	postService := service.NewPostServiceImpl(postRepo, &cfg)
-	userHandler := handler.NewHandler(userService, authService, postService, &cfg)
+	mediaPool := worker.NewPool(cfg.MediaWorkers, cfg.MediaQueueSize)
+	mediaService := service.NewMediaServiceImpl(mediaRepo, blobStore, mediaPool, &cfg)
+	listService := service.NewListServiceImpl(listRepo, &cfg)
+	notificationService := service.NewNotificationServiceImpl(notificationRepo, &cfg)
+	webhookService := service.NewWebhookServiceImpl(webhookRepo, &cfg)
+	webhookService.StartDeliveryJob()
+	chatService := service.NewChatServiceImpl(chatRepo, &cfg)
+	userHandler := handler.NewHandler(userService, authService, postService, mediaService,
+		listService, notificationService, webhookService, chatService, hub, &cfg)
 	server := &http.Server{
 		Addr:    cfg.ServerAddress,
 		Handler: userHandler.Router,
@@ -37,10 +67,20 @@ func main() {
 	log.Print("Server listening on ", cfg.ServerAddress)
 	<-done
 	log.Print("Shutting down server...")
+	// open streams and sockets never finish on their own, so end them first
+	if err := hub.Close(); err != nil {
+		log.Printf("Failed to close event hub: %v", err)
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown")
 	}
+	// the server does not track hijacked connections, so wait for sockets here
+	if err := userHandler.WaitSockets(ctx); err != nil {
+		log.Fatal("Sockets forced to close")
+	}
+	mediaPool.Close()
+	webhookService.Close()
 	log.Print("Server shutdown gracefully")
 }
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	vegeta "github.com/tsenart/vegeta/v12/lib"
@@ -89,4 +91,49 @@ func main() {
 	fmt.Printf("Min latency: %s\n", metrics.Latencies.Min)
 	fmt.Printf("Success rate: %.2f%%\n", metrics.Success*100)
 	fmt.Printf("Status codes: %v\n", metrics.StatusCodes)
+
+	// scroll the same depth sequentially with both paging styles
+	offsetLatency := scroll(accessToken, maxPostsToView/postsPerPage, func(page int, _ string) string {
+		return fmt.Sprintf("%s/posts?limit=%d&offset=%d", baseURL, postsPerPage, page*postsPerPage)
+	})
+	cursorLatency := scroll(accessToken, maxPostsToView/postsPerPage, func(_ int, cursor string) string {
+		return fmt.Sprintf("%s/posts?limit=%d&cursor=%s", baseURL, postsPerPage, url.QueryEscape(cursor))
+	})
+	fmt.Printf("Sequential offset scrolling average latency: %s\n", offsetLatency)
+	fmt.Printf("Sequential cursor scrolling average latency: %s\n", cursorLatency)
+}
+
+// scroll requests up to pages pages one after another and returns the mean
+// latency. Cursor responses are followed through their next_cursor.
+func scroll(accessToken string, pages int, pageURL func(page int, cursor string) string) time.Duration {
+	var total time.Duration
+	cursor := ""
+	requests := 0
+	for page := 0; page < pages; page++ {
+		req, err := http.NewRequest(http.MethodGet, pageURL(page, cursor), nil)
+		if err != nil {
+			log.Fatalf("Failed to build request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Fatalf("Failed to fetch page: %v", err)
+		}
+		total += time.Since(start)
+		requests++
+		var envelope struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		// offset pages are plain arrays and simply fail to decode here
+		_ = json.NewDecoder(resp.Body).Decode(&envelope)
+		resp.Body.Close()
+		if strings.Contains(req.URL.RawQuery, "cursor=") {
+			if envelope.NextCursor == "" {
+				break
+			}
+			cursor = envelope.NextCursor
+		}
+	}
+	return total / time.Duration(requests)
 }
@@ -18,6 +18,8 @@ require (
 	github.com/stretchr/testify v1.10.0
 	github.com/tsenart/vegeta/v12 v12.12.0
 	golang.org/x/crypto v0.31.0
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.27.0
 )
 
 require (
@@ -37,7 +39,6 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rs/dnscache v0.0.0-20230804202142-fc85eb664529 // indirect
 	github.com/shopspring/decimal v1.4.0 // indirect
-	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
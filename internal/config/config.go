@@ -10,12 +10,53 @@ import (
 )
 
 type Config struct {
-	ServerAddress       string        `env:"SERVER_ADDRESS"`
-	DatabaseDSN         string        `env:"DATABASE_DSN"`
-	AccessTokenExpires  time.Duration `env:"ACCESS_TOKEN_EXPIRES"`
-	RefreshTokenExpires time.Duration `env:"REFRESH_TOKEN_EXPIRES"`
-	AccessTokenSecret   string        `env:"ACCESS_TOKEN_SECRET"`
-	RefreshTokenSecret  string        `env:"REFRESH_TOKEN_SECRET"`
+	ServerAddress               string        `env:"SERVER_ADDRESS"`
+	DatabaseDSN                 string        `env:"DATABASE_DSN"`
+	AccessTokenExpires          time.Duration `env:"ACCESS_TOKEN_EXPIRES"`
+	RefreshTokenExpires         time.Duration `env:"REFRESH_TOKEN_EXPIRES"`
+	AccessTokenSecret           string        `env:"ACCESS_TOKEN_SECRET"`
+	RefreshTokenSecret          string        `env:"REFRESH_TOKEN_SECRET"`
+	AdminUserIDs                []uint64      `env:"ADMIN_USER_IDS" envSeparator:","`
+	PublisherInterval           time.Duration `env:"PUBLISHER_INTERVAL" envDefault:"10s"`
+	TimelineFanoutLimit         int           `env:"TIMELINE_FANOUT_LIMIT" envDefault:"10000"`
+	TimelineMaxLength           int           `env:"TIMELINE_MAX_LENGTH" envDefault:"800"`
+	TimelineRebuildInterval     time.Duration `env:"TIMELINE_REBUILD_INTERVAL" envDefault:"5s"`
+	TimelineTrimInterval        time.Duration `env:"TIMELINE_TRIM_INTERVAL" envDefault:"10m"`
+	RecommendationsInterval     time.Duration `env:"RECOMMENDATIONS_INTERVAL" envDefault:"1h"`
+	RecommendationsLimit        int           `env:"RECOMMENDATIONS_LIMIT" envDefault:"50"`
+	RecommendationsLikesAge     time.Duration `env:"RECOMMENDATIONS_LIKES_AGE" envDefault:"720h"`
+	RecommendationsLikesPerPost int           `env:"RECOMMENDATIONS_LIKES_PER_POST" envDefault:"200"`
+	RecommendationsBatchSize    int           `env:"RECOMMENDATIONS_BATCH_SIZE" envDefault:"500"`
+	StreamBackend               string        `env:"STREAM_BACKEND" envDefault:"memory"`
+	StreamHeartbeat             time.Duration `env:"STREAM_HEARTBEAT" envDefault:"15s"`
+	StreamRetention             time.Duration `env:"STREAM_RETENTION" envDefault:"1h"`
+	StreamBufferSize            int           `env:"STREAM_BUFFER_SIZE" envDefault:"64"`
+	StreamReplaySize            int           `env:"STREAM_REPLAY_SIZE" envDefault:"1000"`
+	SocketAuthInterval          time.Duration `env:"SOCKET_AUTH_INTERVAL" envDefault:"1m"`
+	SocketWriteTimeout          time.Duration `env:"SOCKET_WRITE_TIMEOUT" envDefault:"10s"`
+	SocketMaxMessageSize        int           `env:"SOCKET_MAX_MESSAGE_SIZE" envDefault:"4096"`
+	PresenceTTL                 time.Duration `env:"PRESENCE_TTL" envDefault:"90s"`
+	WebhookInterval             time.Duration `env:"WEBHOOK_INTERVAL" envDefault:"5s"`
+	WebhookBatchSize            int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"20"`
+	WebhookTimeout              time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookMaxAttempts          int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookRetryBase            time.Duration `env:"WEBHOOK_RETRY_BASE" envDefault:"30s"`
+	WebhookRetryMax             time.Duration `env:"WEBHOOK_RETRY_MAX" envDefault:"6h"`
+	WebhookDisableAfter         int           `env:"WEBHOOK_DISABLE_AFTER" envDefault:"20"`
+	ChatMaxMembers              int           `env:"CHAT_MAX_MEMBERS" envDefault:"50"`
+	MediaStorage                string        `env:"MEDIA_STORAGE" envDefault:"local"`
+	MediaLocalPath              string        `env:"MEDIA_LOCAL_PATH" envDefault:"./media"`
+	MediaBaseURL                string        `env:"MEDIA_BASE_URL" envDefault:"/v1.0/media/files"`
+	MediaMaxSize                int64         `env:"MEDIA_MAX_SIZE" envDefault:"5242880"`
+	MediaMaxPixels              int64         `env:"MEDIA_MAX_PIXELS" envDefault:"40000000"`
+	MediaWorkers                int           `env:"MEDIA_WORKERS" envDefault:"2"`
+	MediaQueueSize              int           `env:"MEDIA_QUEUE_SIZE" envDefault:"64"`
+	S3Endpoint                  string        `env:"S3_ENDPOINT"`
+	S3Region                    string        `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket                    string        `env:"S3_BUCKET"`
+	S3AccessKey                 string        `env:"S3_ACCESS_KEY"`
+	S3SecretKey                 string        `env:"S3_SECRET_KEY"`
+	S3PublicURL                 string        `env:"S3_PUBLIC_URL"`
 }
 
 func GetConfig() Config {
@@ -24,5 +65,8 @@ func GetConfig() Config {
 	if err != nil {
 		log.Fatal("Error starting server", err)
 	}
+	if cfg.PresenceTTL <= 0 {
+		log.Fatal("Error starting server: PRESENCE_TTL must be positive")
+	}
 	return cfg
 }
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// TypePost announces a post that landed in the user's home timeline.
+	TypePost = "post"
+	// TypeNotification announces a new or grown notification.
+	TypeNotification = "notification"
+	// TypeCounters carries the current counters of a post.
+	TypeCounters = "counters"
+	// TypePresence announces that a user came online or went offline.
+	TypePresence = "presence"
+	// TypeTyping announces that a user is typing to the recipient.
+	TypeTyping = "typing"
+	// TypeMessage announces a new message in one of the user's chats.
+	TypeMessage = "message"
+	// TypeChatRead announces that a member read a chat up to a message.
+	TypeChatRead = "chat_read"
+	// TypeMessageUnsent announces that a message was taken back.
+	TypeMessageUnsent = "message_unsent"
+)
+
+// Event is one message on a topic. IDs grow with every published event, so
+// a client can resume after the last ID it saw.
+type Event struct {
+	ID    uint64          `json:"id"`
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Backend stores published events and carries them to the hubs of every
+// instance.
+type Backend interface {
+	// Publish assigns the event its ID and hands it to every receiver.
+	Publish(event Event) error
+	// Announce hands the event to every receiver without storing it. The
+	// event gets no ID and cannot be replayed, which suits frequent,
+	// short-lived events such as presence beats.
+	Announce(event Event) error
+	// Receive sets the function published events are handed to.
+	Receive(deliver func(Event))
+	// Since returns the events on the topics published after lastID,
+	// oldest first.
+	Since(topics []string, lastID uint64) ([]Event, error)
+	Close() error
+}
+
+// UserTopic carries the events meant for one user only.
+func UserTopic(userID uint64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// PresenceTopic carries the presence changes of one user.
+func PresenceTopic(userID uint64) string {
+	return fmt.Sprintf("presence:%d", userID)
+}
+
+// PostTopic carries the counter changes of one post.
+func PostTopic(postID uint64) string {
+	return fmt.Sprintf("post:%d", postID)
+}
@@ -0,0 +1,222 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Hub fans the events received from its backend out to the subscriptions
+// of this instance. A subscriber that falls behind by more than its buffer
+// is dropped rather than slowing everyone else down; it can reconnect and
+// resume from the last event it saw.
+type Hub struct {
+	backend     Backend
+	bufferSize  int
+	presenceTTL time.Duration
+	lock        sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	connections map[uint64]map[string]time.Time
+	closed      bool
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewHub counts a connection open until it was not heard from for
+// presenceTTL.
+func NewHub(backend Backend, bufferSize int, presenceTTL time.Duration) *Hub {
+	h := &Hub{
+		backend:     backend,
+		bufferSize:  bufferSize,
+		presenceTTL: presenceTTL,
+		subscribers: make(map[string]map[*Subscription]struct{}),
+		connections: make(map[uint64]map[string]time.Time),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	backend.Receive(h.deliver)
+	h.startPresenceTimer()
+	return h
+}
+
+// Publish sends data on the topic. Publishing on a nil hub does nothing, so
+// code that emits events keeps working where no hub is wired in.
+func (h *Hub) Publish(topic, eventType string, data interface{}) {
+	if h == nil {
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+	if err := h.backend.Publish(Event{Topic: topic, Type: eventType, Data: raw}); err != nil {
+		log.Printf("Failed to publish %s event: %v", eventType, err)
+	}
+}
+
+// announce sends data on the topic without storing it.
+func (h *Hub) announce(topic, eventType string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+	if err := h.backend.Announce(Event{Topic: topic, Type: eventType, Data: raw}); err != nil {
+		log.Printf("Failed to announce %s event: %v", eventType, err)
+	}
+}
+
+// Since returns the events on the topics published after lastID.
+func (h *Hub) Since(topics []string, lastID uint64) ([]Event, error) {
+	return h.backend.Since(topics, lastID)
+}
+
+// Subscribe starts receiving the events on the topics.
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	s := &Subscription{
+		hub:    h,
+		events: make(chan Event, h.bufferSize),
+		topics: make(map[string]struct{}),
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.closed {
+		close(s.events)
+		s.done = true
+		return s
+	}
+	for _, topic := range topics {
+		h.add(s, topic)
+	}
+	return s
+}
+
+// Close ends every subscription and stops the backend. Streams see their
+// subscription end and return, which lets the HTTP server shut down.
+func (h *Hub) Close() error {
+	close(h.stop)
+	<-h.done
+	h.lock.Lock()
+	h.closed = true
+	for _, subscriptions := range h.subscribers {
+		for s := range subscriptions {
+			h.end(s)
+		}
+	}
+	h.lock.Unlock()
+	return h.backend.Close()
+}
+
+func (h *Hub) deliver(event Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if event.Type == TypePresence {
+		var ok bool
+		if event, ok = h.trackPresence(event); !ok {
+			return
+		}
+	}
+	h.fanOut(event)
+}
+
+// fanOut expects the hub to be locked.
+func (h *Hub) fanOut(event Event) {
+	for s := range h.subscribers[event.Topic] {
+		select {
+		case s.events <- event:
+		default:
+			log.Printf("Dropping slow subscriber of %s", event.Topic)
+			s.dropped = true
+			h.end(s)
+		}
+	}
+}
+
+// add and end expect the hub to be locked.
+func (h *Hub) add(s *Subscription, topic string) {
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[topic][s] = struct{}{}
+	s.topics[topic] = struct{}{}
+}
+
+func (h *Hub) remove(s *Subscription, topic string) {
+	delete(h.subscribers[topic], s)
+	if len(h.subscribers[topic]) == 0 {
+		delete(h.subscribers, topic)
+	}
+	delete(s.topics, topic)
+}
+
+func (h *Hub) end(s *Subscription) {
+	if s.done {
+		return
+	}
+	s.done = true
+	for topic := range s.topics {
+		h.remove(s, topic)
+	}
+	close(s.events)
+}
+
+// Subscription receives the events of its topics until it is closed, its
+// hub closes or it falls too far behind.
+type Subscription struct {
+	hub     *Hub
+	events  chan Event
+	topics  map[string]struct{}
+	done    bool
+	dropped bool
+}
+
+// Events is closed when the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Add starts receiving the events on the topics as well.
+func (s *Subscription) Add(topics ...string) {
+	s.hub.lock.Lock()
+	defer s.hub.lock.Unlock()
+	if s.done {
+		return
+	}
+	for _, topic := range topics {
+		s.hub.add(s, topic)
+	}
+}
+
+// Remove stops receiving the events on the topics.
+func (s *Subscription) Remove(topics ...string) {
+	s.hub.lock.Lock()
+	defer s.hub.lock.Unlock()
+	if s.done {
+		return
+	}
+	for _, topic := range topics {
+		s.hub.remove(s, topic)
+	}
+}
+
+// Topics returns how many topics the subscription receives.
+func (s *Subscription) Topics() int {
+	s.hub.lock.RLock()
+	defer s.hub.lock.RUnlock()
+	return len(s.topics)
+}
+
+// Dropped reports whether the subscription ended because it fell behind.
+func (s *Subscription) Dropped() bool {
+	s.hub.lock.RLock()
+	defer s.hub.lock.RUnlock()
+	return s.dropped
+}
+
+func (s *Subscription) Close() {
+	s.hub.lock.Lock()
+	defer s.hub.lock.Unlock()
+	s.hub.end(s)
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_Publish(t *testing.T) {
+	h := NewHub(NewMemoryBackend(10), 10, time.Minute)
+	defer h.Close()
+	subscription := h.Subscribe(UserTopic(1), PostTopic(7))
+	defer subscription.Close()
+	other := h.Subscribe(UserTopic(2))
+	defer other.Close()
+
+	h.Publish(UserTopic(1), TypeNotification, map[string]int{"id": 3})
+	h.Publish(PostTopic(7), TypeCounters, map[string]int{"likes_count": 1})
+
+	event := <-subscription.Events()
+	assert.Equal(t, uint64(1), event.ID, "ID mismatch")
+	assert.Equal(t, TypeNotification, event.Type, "Type mismatch")
+	assert.JSONEq(t, `{"id":3}`, string(event.Data), "Data mismatch")
+	event = <-subscription.Events()
+	assert.Equal(t, TypeCounters, event.Type, "Type mismatch")
+	assert.Empty(t, other.Events(), "Other topics should not be delivered")
+}
+
+func TestHub_PublishNil(t *testing.T) {
+	var h *Hub
+	assert.NotPanics(t, func() {
+		h.Publish(UserTopic(1), TypePost, nil)
+	})
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	h := NewHub(NewMemoryBackend(10), 1, time.Minute)
+	defer h.Close()
+	subscription := h.Subscribe(UserTopic(1))
+
+	h.Publish(UserTopic(1), TypePost, nil)
+	h.Publish(UserTopic(1), TypePost, nil)
+
+	_, ok := <-subscription.Events()
+	assert.True(t, ok, "Buffered event should be received")
+	_, ok = <-subscription.Events()
+	assert.False(t, ok, "Slow subscriber should be dropped")
+	subscription.Close()
+}
+
+func TestHub_Close(t *testing.T) {
+	h := NewHub(NewMemoryBackend(10), 10, time.Minute)
+	subscription := h.Subscribe(UserTopic(1))
+	assert.Nil(t, h.Close(), "Error is not nil")
+	_, ok := <-subscription.Events()
+	assert.False(t, ok, "Subscription should end with the hub")
+
+	_, ok = <-h.Subscribe(UserTopic(1)).Events()
+	assert.False(t, ok, "Closed hub should not accept subscriptions")
+}
+
+func TestSubscription_AddRemove(t *testing.T) {
+	h := NewHub(NewMemoryBackend(10), 10, time.Minute)
+	defer h.Close()
+	subscription := h.Subscribe(UserTopic(1))
+	defer subscription.Close()
+
+	subscription.Add(PostTopic(7), PostTopic(8))
+	assert.Equal(t, 3, subscription.Topics(), "Topics were not added")
+	subscription.Remove(PostTopic(7))
+	assert.Equal(t, 2, subscription.Topics(), "Topic was not removed")
+
+	h.Publish(PostTopic(7), TypeCounters, nil)
+	h.Publish(PostTopic(8), TypeCounters, nil)
+	event := <-subscription.Events()
+	assert.Equal(t, PostTopic(8), event.Topic, "Removed topic should not be delivered")
+}
+
+func TestHub_Presence(t *testing.T) {
+	h := NewHub(NewMemoryBackend(10), 10, time.Minute)
+	defer h.Close()
+	subscription := h.Subscribe(PresenceTopic(3))
+	defer subscription.Close()
+
+	assert.False(t, h.Online(3), "User should be offline")
+	first := h.Connect(3)
+	second := h.Connect(3)
+	assert.True(t, h.Online(3), "User should be online")
+	first.Heartbeat()
+	first.Disconnect()
+	assert.True(t, h.Online(3), "User with an open connection should stay online")
+	second.Disconnect()
+	assert.False(t, h.Online(3), "User should be offline")
+
+	event := <-subscription.Events()
+	assert.JSONEq(t, `{"user_id":3,"online":true}`, string(event.Data), "Presence mismatch")
+	event = <-subscription.Events()
+	assert.JSONEq(t, `{"user_id":3,"online":false}`, string(event.Data), "Presence mismatch")
+	assert.Empty(t, subscription.Events(), "Only changes should be delivered")
+}
+
+func TestHub_PresenceExpires(t *testing.T) {
+	h := NewHub(NewMemoryBackend(10), 10, 50*time.Millisecond)
+	defer h.Close()
+	subscription := h.Subscribe(PresenceTopic(3))
+	defer subscription.Close()
+
+	// a connection of an instance that died never disconnects
+	connection := h.Connect(3)
+	event := <-subscription.Events()
+	assert.JSONEq(t, `{"user_id":3,"online":true}`, string(event.Data), "Presence mismatch")
+	time.Sleep(30 * time.Millisecond)
+	connection.Heartbeat()
+	assert.True(t, h.Online(3), "Beating connection should keep the user online")
+
+	select {
+	case event = <-subscription.Events():
+		assert.JSONEq(t, `{"user_id":3,"online":false}`, string(event.Data), "Presence mismatch")
+	case <-time.After(time.Second):
+		t.Fatal("Silent connection should expire")
+	}
+	assert.False(t, h.Online(3), "User should be offline")
+
+	// a hub that missed the connect learns of the connection from its beat
+	connection.Heartbeat()
+	assert.True(t, h.Online(3), "Beat should bring the user back online")
+}
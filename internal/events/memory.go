@@ -0,0 +1,76 @@
+package events
+
+import "sync"
+
+// MemoryBackend keeps the latest events in a ring buffer. Events never leave
+// the process, so it suits a single instance.
+type MemoryBackend struct {
+	lock    sync.Mutex
+	lastID  uint64
+	events  []Event
+	next    int
+	deliver func(Event)
+}
+
+func NewMemoryBackend(size int) *MemoryBackend {
+	if size < 1 {
+		size = 1
+	}
+	return &MemoryBackend{events: make([]Event, 0, size)}
+}
+
+// Publish delivers while holding the lock, so receivers see events in ID
+// order.
+func (b *MemoryBackend) Publish(event Event) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.lastID++
+	event.ID = b.lastID
+	if len(b.events) < cap(b.events) {
+		b.events = append(b.events, event)
+	} else {
+		b.events[b.next] = event
+		b.next = (b.next + 1) % len(b.events)
+	}
+	if b.deliver != nil {
+		b.deliver(event)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Announce(event Event) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.deliver != nil {
+		b.deliver(event)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Receive(deliver func(Event)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.deliver = deliver
+}
+
+// Since only finds events still in the buffer; older ones are lost.
+func (b *MemoryBackend) Since(topics []string, lastID uint64) ([]Event, error) {
+	wanted := make(map[string]bool)
+	for _, topic := range topics {
+		wanted[topic] = true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	found := []Event{}
+	for i := 0; i < len(b.events); i++ {
+		event := b.events[(b.next+i)%len(b.events)]
+		if event.ID > lastID && wanted[event.Topic] {
+			found = append(found, event)
+		}
+	}
+	return found, nil
+}
+
+func (b *MemoryBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBackend_Since(t *testing.T) {
+	b := NewMemoryBackend(3)
+	delivered := []uint64{}
+	b.Receive(func(event Event) {
+		delivered = append(delivered, event.ID)
+	})
+	for _, topic := range []string{"a", "b", "a", "a", "b"} {
+		assert.Nil(t, b.Publish(Event{Topic: topic, Type: TypePost}), "Error is not nil")
+	}
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, delivered, "Events were not delivered in order")
+
+	found, err := b.Since([]string{"a"}, 0)
+	assert.Nil(t, err, "Error is not nil")
+	ids := []uint64{}
+	for _, event := range found {
+		ids = append(ids, event.ID)
+	}
+	assert.Equal(t, []uint64{3, 4}, ids, "Events older than the buffer should be gone")
+
+	found, err = b.Since([]string{"a", "b"}, 3)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, found, 2, "Only newer events should be returned")
+
+	assert.Nil(t, b.Announce(Event{Topic: "a", Type: TypePresence}), "Error is not nil")
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 0}, delivered, "Announced event was not delivered")
+	found, err = b.Since([]string{"a"}, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, found, 2, "Announced event should not be stored")
+}
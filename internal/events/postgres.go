@@ -0,0 +1,201 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx"
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+const postgresChannel = "gophertalk_events"
+
+const postgresTrimInterval = time.Minute
+
+// PostgresBackend stores events in the stream_events table and announces
+// them with NOTIFY, so every instance listening on the channel receives
+// them. Events are kept for the retention period to let clients resume.
+type PostgresBackend struct {
+	db        *sql.DB
+	dsn       string
+	retention time.Duration
+	lock      sync.Mutex
+	deliver   func(Event)
+	lastID    uint64
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewPostgresBackend(dsn string, retention time.Duration) *PostgresBackend {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		log.Fatal("Error connecting to database", err)
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &PostgresBackend{db: db, dsn: dsn, retention: retention, cancel: cancel, done: make(chan struct{})}
+	go b.listen(ctx)
+	go b.startTrimTimer(ctx)
+	return b
+}
+
+func (b *PostgresBackend) Publish(event Event) error {
+	query := `
+		with inserted as (
+			insert into stream_events (topic, type, data) values ($1, $2, $3::jsonb)
+			returning id, topic, type, data
+		)
+		select pg_notify($4, json_build_object('id', id, 'topic', topic, 'type', type, 'data', data)::text)
+		from inserted;
+	`
+	_, err := b.db.Exec(query, event.Topic, event.Type, string(event.Data), postgresChannel)
+	return err
+}
+
+// Announce only sends the NOTIFY; nothing is written to stream_events.
+func (b *PostgresBackend) Announce(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = b.db.Exec(`select pg_notify($1, $2);`, postgresChannel, string(payload))
+	return err
+}
+
+func (b *PostgresBackend) Receive(deliver func(Event)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.deliver = deliver
+}
+
+func (b *PostgresBackend) Since(topics []string, lastID uint64) ([]Event, error) {
+	if len(topics) == 0 {
+		return []Event{}, nil
+	}
+	params := []interface{}{lastID}
+	placeholders := []string{}
+	for _, topic := range topics {
+		params = append(params, topic)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+	}
+	return b.query(fmt.Sprintf(`
+		select id, topic, type, data from stream_events
+		where id > $1 and topic in (%s)
+		order by id;
+	`, strings.Join(placeholders, ", ")), params...)
+}
+
+func (b *PostgresBackend) query(query string, params ...interface{}) ([]Event, error) {
+	rows, err := b.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := []Event{}
+	for rows.Next() {
+		var event Event
+		var data []byte
+		if err := rows.Scan(&event.ID, &event.Topic, &event.Type, &data); err != nil {
+			return nil, err
+		}
+		event.Data = data
+		found = append(found, event)
+	}
+	return found, rows.Err()
+}
+
+func (b *PostgresBackend) Close() error {
+	b.cancel()
+	<-b.done
+	return b.db.Close()
+}
+
+// listen keeps a connection listening on the channel. After a reconnect it
+// first hands over the events published while it was away.
+func (b *PostgresBackend) listen(ctx context.Context) {
+	defer close(b.done)
+	for {
+		err := b.listenOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Lost event stream connection: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (b *PostgresBackend) listenOnce(ctx context.Context) error {
+	config, err := pgx.ParseConnectionString(b.dsn)
+	if err != nil {
+		return err
+	}
+	conn, err := pgx.Connect(config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.Listen(postgresChannel); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	lastID := b.lastID
+	b.lock.Unlock()
+	if lastID > 0 {
+		missed, err := b.query(`select id, topic, type, data from stream_events where id > $1 order by id;`, lastID)
+		if err != nil {
+			return err
+		}
+		for _, event := range missed {
+			b.handOver(event)
+		}
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("Failed to decode stream event: %v", err)
+			continue
+		}
+		b.handOver(event)
+	}
+}
+
+func (b *PostgresBackend) handOver(event Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if event.ID > b.lastID {
+		b.lastID = event.ID
+	}
+	if b.deliver != nil {
+		b.deliver(event)
+	}
+}
+
+func (b *PostgresBackend) startTrimTimer(ctx context.Context) {
+	ticker := time.NewTicker(postgresTrimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			query := `delete from stream_events where created_at < $1;`
+			if _, err := b.db.Exec(query, time.Now().Add(-b.retention)); err != nil {
+				log.Printf("Failed to trim stream events: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresBackend_Publish(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	b := &PostgresBackend{db: db}
+	mock.ExpectExec(regexp.QuoteMeta(`
+		with inserted as (
+			insert into stream_events (topic, type, data) values ($1, $2, $3::jsonb)
+			returning id, topic, type, data
+		)
+		select pg_notify($4, json_build_object('id', id, 'topic', topic, 'type', type, 'data', data)::text)
+		from inserted;
+	`)).
+		WithArgs("user:1", TypePost, `{"post_id":2}`, postgresChannel).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = b.Publish(Event{Topic: UserTopic(1), Type: TypePost, Data: []byte(`{"post_id":2}`)})
+	assert.Nil(t, err, "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostgresBackend_Announce(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	b := &PostgresBackend{db: db}
+	mock.ExpectExec(regexp.QuoteMeta(`select pg_notify($1, $2);`)).
+		WithArgs(postgresChannel, `{"id":0,"topic":"presence:1","type":"presence","data":{"user_id":1}}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = b.Announce(Event{Topic: PresenceTopic(1), Type: TypePresence, Data: []byte(`{"user_id":1}`)})
+	assert.Nil(t, err, "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostgresBackend_Since(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	b := &PostgresBackend{db: db}
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		select id, topic, type, data from stream_events
+		where id > $1 and topic in ($2, $3)
+		order by id;
+	`)).
+		WithArgs(uint64(4), "user:1", "post:2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "type", "data"}).
+			AddRow(5, "post:2", TypeCounters, []byte(`{"likes_count":1}`)))
+
+	found, err := b.Since([]string{UserTopic(1), PostTopic(2)}, 4)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, []Event{{ID: 5, Topic: "post:2", Type: TypeCounters, Data: []byte(`{"likes_count":1}`)}}, found)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
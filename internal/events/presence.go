@@ -0,0 +1,157 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Presence tells whether a user has at least one open connection.
+type Presence struct {
+	UserID uint64 `json:"user_id"`
+	Online bool   `json:"online"`
+}
+
+// presenceBeat is published when a connection opens, on every heartbeat
+// while it stays open, and when it closes. Every hub keeps the time it last
+// heard from each connection and counts a user online while one of them was
+// heard from within the presence TTL. Connections of an instance that died
+// without closing them expire on their own, and a hub that started later
+// learns of the open ones from their next beat. Subscribers only see a user
+// go online or offline.
+type presenceBeat struct {
+	UserID     uint64 `json:"user_id"`
+	Connection string `json:"connection"`
+	Closed     bool   `json:"closed,omitempty"`
+}
+
+// Connection keeps the user online while it is open.
+type Connection struct {
+	hub  *Hub
+	beat presenceBeat
+}
+
+// Connect announces a new connection of the user. It has to beat at least
+// once per presence TTL to keep the user online.
+func (h *Hub) Connect(userID uint64) *Connection {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Printf("Failed to generate connection id: %v", err)
+	}
+	c := &Connection{hub: h, beat: presenceBeat{UserID: userID, Connection: hex.EncodeToString(id)}}
+	c.Heartbeat()
+	return c
+}
+
+// Heartbeat tells every hub the connection is still open. Beats are
+// announced rather than published: they are frequent and worthless once the
+// next one arrives, so they are never stored for replay.
+func (c *Connection) Heartbeat() {
+	c.hub.announce(PresenceTopic(c.beat.UserID), TypePresence, c.beat)
+}
+
+// Disconnect announces that the connection closed.
+func (c *Connection) Disconnect() {
+	beat := c.beat
+	beat.Closed = true
+	c.hub.announce(PresenceTopic(beat.UserID), TypePresence, beat)
+}
+
+// Online reports whether the user has an open connection on any instance.
+func (h *Hub) Online(userID uint64) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.online(userID, time.Now())
+}
+
+// online expects the hub to be locked.
+func (h *Hub) online(userID uint64, now time.Time) bool {
+	for _, seenAt := range h.connections[userID] {
+		if now.Sub(seenAt) < h.presenceTTL {
+			return true
+		}
+	}
+	return false
+}
+
+// trackPresence records the beat and turns it into the event subscribers
+// see. It reports false when the user's presence did not change. It expects
+// the hub to be locked.
+//
+// Subscribers are told a user is online while the user has connections on
+// record; expirePresence tells them when the last one is dropped.
+func (h *Hub) trackPresence(event Event) (Event, bool) {
+	var beat presenceBeat
+	if err := json.Unmarshal(event.Data, &beat); err != nil {
+		log.Printf("Failed to decode presence event: %v", err)
+		return event, false
+	}
+	before := len(h.connections[beat.UserID]) > 0
+	if beat.Closed {
+		delete(h.connections[beat.UserID], beat.Connection)
+	} else {
+		if h.connections[beat.UserID] == nil {
+			h.connections[beat.UserID] = make(map[string]time.Time)
+		}
+		h.connections[beat.UserID][beat.Connection] = time.Now()
+	}
+	if len(h.connections[beat.UserID]) == 0 {
+		delete(h.connections, beat.UserID)
+	}
+	after := len(h.connections[beat.UserID]) > 0
+	if before == after {
+		return event, false
+	}
+	return presenceEvent(event, beat.UserID, after)
+}
+
+func presenceEvent(event Event, userID uint64, online bool) (Event, bool) {
+	raw, err := json.Marshal(Presence{UserID: userID, Online: online})
+	if err != nil {
+		return event, false
+	}
+	event.Data = raw
+	return event, true
+}
+
+// expirePresence forgets the connections not heard from within the presence
+// TTL and tells the subscribers about the users that went offline with them.
+func (h *Hub) expirePresence() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	now := time.Now()
+	for userID, connections := range h.connections {
+		for id, seenAt := range connections {
+			if now.Sub(seenAt) >= h.presenceTTL {
+				delete(connections, id)
+			}
+		}
+		if len(connections) > 0 {
+			continue
+		}
+		delete(h.connections, userID)
+		event, ok := presenceEvent(Event{Topic: PresenceTopic(userID), Type: TypePresence}, userID, false)
+		if ok {
+			h.fanOut(event)
+		}
+	}
+}
+
+// startPresenceTimer runs expirePresence until the hub closes.
+func (h *Hub) startPresenceTimer() {
+	ticker := time.NewTicker(h.presenceTTL / 2)
+	go func() {
+		defer close(h.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+				h.expirePresence()
+			}
+		}
+	}()
+}
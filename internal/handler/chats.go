@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+// CreateChat opens the direct chat with a user. It answers 201 when the chat
+// was created and 200 when it already existed.
+func (h *Handler) CreateChat(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var createDTO models.CreateChatDTO
+	if err = json.Unmarshal(body, &createDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	err = h.validate.Struct(createDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTO, created, err := h.chats.CreateDirectChat(userID, createDTO.UserID)
+	if err != nil {
+		switch err {
+		case service.ErrMessageSelf:
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		case repository.ErrBlocked, repository.ErrMessagesRestricted:
+			h.JSONError(w, http.StatusForbidden, err.Error())
+		case repository.ErrNotFound:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		default:
+			h.JSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) GetChats(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	cursor, _, err := cursorParam(r)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.chats.GetChatsPage(userID, cursor, limit)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	setLinkHeader(w, r, page.NextCursor, page.PrevCursor)
+	resp, err := json.Marshal(page)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) GetChatByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTO, err := h.chats.GetChatByID(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// GetUnreadMessagesCount counts the unread messages in the chats the user did
+// not mute.
+func (h *Handler) GetUnreadMessagesCount(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	count, err := h.chats.CountUnread(userID)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := json.Marshal(models.UnreadCountDTO{Count: count})
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// MarkChatRead moves the read position of the user. The body is optional;
+// without a message_id the whole chat is read.
+func (h *Handler) MarkChatRead(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var readDTO models.MarkChatReadDTO
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &readDTO); err != nil {
+			h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+			return
+		}
+	}
+	err = h.chats.MarkRead(id, userID, readDTO.MessageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) MuteChat(w http.ResponseWriter, r *http.Request) {
+	h.changeChatMute(w, r, h.chats.MuteChat)
+}
+
+func (h *Handler) UnmuteChat(w http.ResponseWriter, r *http.Request) {
+	h.changeChatMute(w, r, h.chats.UnmuteChat)
+}
+
+func (h *Handler) changeChatMute(w http.ResponseWriter, r *http.Request, change func(id, userID uint64) error) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = change(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetChatMessages(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 20
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	cursor, _, err := cursorParam(r)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.chats.GetMessagesPage(id, userID, cursor, limit)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	setLinkHeader(w, r, page.NextCursor, page.PrevCursor)
+	resp, err := json.Marshal(page)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var createDTO models.CreateMessageDTO
+	if err = json.Unmarshal(body, &createDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	createDTO.ChatID = id
+	createDTO.SenderID = userID
+	err = h.validate.Struct(createDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	readDTO, err := h.chats.SendMessage(createDTO)
+	if err != nil {
+		switch err {
+		case repository.ErrBlocked, repository.ErrMessagesRestricted:
+			h.JSONError(w, http.StatusForbidden, err.Error())
+		case repository.ErrNotFound:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		default:
+			h.JSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// UnsendMessage takes a message back for every member of the chat.
+func (h *Handler) UnsendMessage(w http.ResponseWriter, r *http.Request) {
+	h.removeMessage(w, r, h.chats.UnsendMessage)
+}
+
+// DeleteMessage hides a message from the current user only.
+func (h *Handler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	h.removeMessage(w, r, h.chats.DeleteMessage)
+}
+
+func (h *Handler) removeMessage(w http.ResponseWriter, r *http.Request, remove func(id, chatID, userID uint64) error) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	messageID, err := strconv.ParseUint(chi.URLParam(r, "message_id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = remove(messageID, id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
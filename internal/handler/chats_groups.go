@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+// CreateGroupChat starts a group with the current user as its admin.
+func (h *Handler) CreateGroupChat(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var createDTO models.CreateGroupChatDTO
+	if err = json.Unmarshal(body, &createDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	createDTO.CreatorID = userID
+	err = h.validate.Struct(createDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	readDTO, err := h.chats.CreateGroupChat(createDTO)
+	if err != nil {
+		h.chatGroupError(w, err)
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// UpdateChat renames a group.
+func (h *Handler) UpdateChat(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var updateDTO models.UpdateChatDTO
+	if err = json.Unmarshal(body, &updateDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	err = h.validate.Struct(updateDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTO, err := h.chats.UpdateChat(id, userID, updateDTO)
+	if err != nil {
+		h.chatGroupError(w, err)
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// AddChatMember lets a group admin add a user.
+func (h *Handler) AddChatMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var addDTO models.AddChatMemberDTO
+	if err = json.Unmarshal(body, &addDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	err = h.validate.Struct(addDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.chats.AddChatMember(id, userID, addDTO.UserID)
+	if err != nil {
+		h.chatGroupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateChatMember lets a group admin change the role of a member.
+func (h *Handler) UpdateChatMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	memberID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var updateDTO models.UpdateChatMemberDTO
+	if err = json.Unmarshal(body, &updateDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	err = h.validate.Struct(updateDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.chats.UpdateChatMember(id, userID, memberID, updateDTO)
+	if err != nil {
+		h.chatGroupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveChatMember lets a group admin remove a member.
+func (h *Handler) RemoveChatMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	memberID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.chats.RemoveChatMember(id, userID, memberID)
+	if err != nil {
+		h.chatGroupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LeaveChat takes the current user out of a group.
+func (h *Handler) LeaveChat(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.chats.LeaveChat(id, userID)
+	if err != nil {
+		h.chatGroupError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) chatGroupError(w http.ResponseWriter, err error) {
+	switch err {
+	case service.ErrMessageSelf, repository.ErrLastChatAdmin:
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+	case repository.ErrBlocked, repository.ErrMessagesRestricted, repository.ErrChatAdminOnly:
+		h.JSONError(w, http.StatusForbidden, err.Error())
+	case repository.ErrChatFull:
+		h.JSONError(w, http.StatusConflict, err.Error())
+	case repository.ErrNotFound:
+		h.JSONError(w, http.StatusNotFound, err.Error())
+	default:
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreateChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chats := mocks.NewMockChatService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, chats, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		created      bool
+		serviceErr   error
+		serviceCall  bool
+	}{
+		{
+			name:         "Success create",
+			body:         `{"user_id": 2}`,
+			expectedCode: http.StatusCreated,
+			created:      true,
+			serviceCall:  true,
+		},
+		{
+			name:         "Existing chat",
+			body:         `{"user_id": 2}`,
+			expectedCode: http.StatusOK,
+			serviceCall:  true,
+		},
+		{
+			name:         "Blocked user",
+			body:         `{"user_id": 2}`,
+			expectedCode: http.StatusForbidden,
+			serviceErr:   repository.ErrBlocked,
+			serviceCall:  true,
+		},
+		{
+			name:         "Followers only",
+			body:         `{"user_id": 2}`,
+			expectedCode: http.StatusForbidden,
+			serviceErr:   repository.ErrMessagesRestricted,
+			serviceCall:  true,
+		},
+		{
+			name:         "Self",
+			body:         `{"user_id": 2}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			serviceErr:   service.ErrMessageSelf,
+			serviceCall:  true,
+		},
+		{
+			name:         "Missing user",
+			body:         `{}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCall {
+				if tc.serviceErr != nil {
+					chats.EXPECT().CreateDirectChat(uint64(1), uint64(2)).Return(nil, false, tc.serviceErr)
+				} else {
+					chats.EXPECT().CreateDirectChat(uint64(1), uint64(2)).Return(&models.ReadChatDTO{ID: 5}, tc.created, nil)
+				}
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/chats"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_SendMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chats := mocks.NewMockChatService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, chats, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		serviceErr   error
+		serviceCall  bool
+	}{
+		{
+			name:         "Success send",
+			body:         `{"text": "Hi"}`,
+			expectedCode: http.StatusCreated,
+			serviceCall:  true,
+		},
+		{
+			name:         "Blocked user",
+			body:         `{"text": "Hi"}`,
+			expectedCode: http.StatusForbidden,
+			serviceErr:   repository.ErrBlocked,
+			serviceCall:  true,
+		},
+		{
+			name:         "Not a member",
+			body:         `{"text": "Hi"}`,
+			expectedCode: http.StatusNotFound,
+			serviceErr:   repository.ErrNotFound,
+			serviceCall:  true,
+		},
+		{
+			name:         "Empty text",
+			body:         `{"text": ""}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCall {
+				call := chats.EXPECT().SendMessage(models.CreateMessageDTO{ChatID: 5, SenderID: 1, Text: "Hi"})
+				if tc.serviceErr != nil {
+					call.Return(nil, tc.serviceErr)
+				} else {
+					call.Return(&models.ReadMessageDTO{ID: 9, ChatID: 5, SenderID: 1, Text: "Hi"}, nil)
+				}
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/chats/5/messages"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_MarkChatRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chats := mocks.NewMockChatService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, chats, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	chats.EXPECT().MarkRead(uint64(5), uint64(1), uint64(0)).Return(nil)
+	chats.EXPECT().MarkRead(uint64(5), uint64(1), uint64(9)).Return(nil)
+	chats.EXPECT().MarkRead(uint64(6), uint64(1), uint64(0)).Return(repository.ErrNotFound)
+
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Post(httpSrv.URL + "/v1.0/chats/5/read")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+
+	resp, err = req.SetBody(`{"message_id": 9}`).Post(httpSrv.URL + "/v1.0/chats/5/read")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+
+	resp, err = resty.New().R().SetHeader("Authorization", "Bearer "+accessToken).Post(httpSrv.URL + "/v1.0/chats/6/read")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Response code didn't match expected")
+}
+
+func TestHandler_CreateGroupChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chats := mocks.NewMockChatService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, chats, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		serviceErr   error
+		serviceCall  bool
+	}{
+		{
+			name:         "Success create",
+			body:         `{"name": "Gophers", "user_ids": [2, 3]}`,
+			expectedCode: http.StatusCreated,
+			serviceCall:  true,
+		},
+		{
+			name:         "Blocked user",
+			body:         `{"name": "Gophers", "user_ids": [2, 3]}`,
+			expectedCode: http.StatusForbidden,
+			serviceErr:   repository.ErrBlocked,
+			serviceCall:  true,
+		},
+		{
+			name:         "Too many members",
+			body:         `{"name": "Gophers", "user_ids": [2, 3]}`,
+			expectedCode: http.StatusConflict,
+			serviceErr:   repository.ErrChatFull,
+			serviceCall:  true,
+		},
+		{
+			name:         "Missing name",
+			body:         `{"user_ids": [2, 3]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Duplicate users",
+			body:         `{"name": "Gophers", "user_ids": [2, 2]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCall {
+				call := chats.EXPECT().CreateGroupChat(models.CreateGroupChatDTO{CreatorID: 1, Name: "Gophers", UserIDs: []uint64{2, 3}})
+				if tc.serviceErr != nil {
+					call.Return(nil, tc.serviceErr)
+				} else {
+					call.Return(&models.ReadChatDTO{ID: 5, IsGroup: true, Name: "Gophers"}, nil)
+				}
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/chats/groups"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_ChatMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chats := mocks.NewMockChatService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, chats, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	chats.EXPECT().AddChatMember(uint64(5), uint64(1), uint64(3)).Return(nil)
+	chats.EXPECT().AddChatMember(uint64(5), uint64(1), uint64(4)).Return(repository.ErrChatAdminOnly)
+	chats.EXPECT().UpdateChatMember(uint64(5), uint64(1), uint64(1), models.UpdateChatMemberDTO{Role: models.ChatRoleMember}).
+		Return(repository.ErrLastChatAdmin)
+	chats.EXPECT().RemoveChatMember(uint64(5), uint64(1), uint64(3)).Return(nil)
+	chats.EXPECT().LeaveChat(uint64(5), uint64(1)).Return(nil)
+	chats.EXPECT().LeaveChat(uint64(6), uint64(1)).Return(repository.ErrNotFound)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{name: "Add member", method: http.MethodPost, path: "/5/members", body: `{"user_id": 3}`, expectedCode: http.StatusNoContent},
+		{name: "Add as member", method: http.MethodPost, path: "/5/members", body: `{"user_id": 4}`, expectedCode: http.StatusForbidden},
+		{name: "Add nobody", method: http.MethodPost, path: "/5/members", body: `{}`, expectedCode: http.StatusUnprocessableEntity},
+		{name: "Last admin steps down", method: http.MethodPut, path: "/5/members/1", body: `{"role": "member"}`, expectedCode: http.StatusUnprocessableEntity},
+		{name: "Unknown role", method: http.MethodPut, path: "/5/members/3", body: `{"role": "owner"}`, expectedCode: http.StatusUnprocessableEntity},
+		{name: "Remove member", method: http.MethodDelete, path: "/5/members/3", expectedCode: http.StatusNoContent},
+		{name: "Leave", method: http.MethodPost, path: "/5/leave", expectedCode: http.StatusNoContent},
+		{name: "Leave direct chat", method: http.MethodPost, path: "/6/leave", expectedCode: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = httpSrv.URL + "/v1.0/chats" + tc.path
+			if tc.body != "" {
+				req.SetBody(tc.body)
+			}
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
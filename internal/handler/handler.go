@@ -5,30 +5,52 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"sync"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-playground/validator/v10"
 	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
 	"github.com/shekshuev/gophertalk-backend/internal/middleware"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
 	"github.com/shekshuev/gophertalk-backend/internal/service"
 	"github.com/shekshuev/gophertalk-backend/internal/utils"
 )
 
 type Handler struct {
-	users    service.UserService
-	auth     service.AuthService
-	posts    service.PostService
-	Router   *chi.Mux
-	validate *validator.Validate
-	cfg      *config.Config
+	users         service.UserService
+	auth          service.AuthService
+	posts         service.PostService
+	media         service.MediaService
+	lists         service.ListService
+	notifications service.NotificationService
+	webhooks      service.WebhookService
+	chats         service.ChatService
+	hub           *events.Hub
+	sockets       sync.WaitGroup
+	Router        *chi.Mux
+	validate      *validator.Validate
+	cfg           *config.Config
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// ProtectedErrorResponse tells the client that the posts it asked for belong
+// to a protected account it does not follow.
+type ProtectedErrorResponse struct {
+	Error     string `json:"error"`
+	Protected bool   `json:"protected"`
+}
+
+type SearchErrorResponse struct {
+	Error  string         `json:"error"`
+	Issues []search.Issue `json:"issues"`
+}
+
 var ErrValidationError = errors.New("validation error")
 var ErrInvalidID = errors.New("invalid ID")
 var ErrInvalidToken = errors.New("invalid token")
@@ -37,24 +59,50 @@ func NewHandler(
 	users service.UserService,
 	auth service.AuthService,
 	posts service.PostService,
+	media service.MediaService,
+	lists service.ListService,
+	notifications service.NotificationService,
+	webhooks service.WebhookService,
+	chats service.ChatService,
+	hub *events.Hub,
 	cfg *config.Config,
 ) *Handler {
 	router := chi.NewRouter()
 	validate := utils.NewValidator()
 	router.Use(chiMiddleware.RequestID)
 	router.Use(chiMiddleware.RealIP)
+	router.Use(middleware.RedactQueryToken)
 	router.Use(chiMiddleware.Logger)
 	router.Use(chiMiddleware.SetHeader("Content-Type", "application/json"))
 	router.Use(chiMiddleware.Recoverer)
 	router.Use(cors.AllowAll().Handler)
-	h := &Handler{users: users, auth: auth, posts: posts, Router: router, validate: validate, cfg: cfg}
+	h := &Handler{users: users, auth: auth, posts: posts, media: media, lists: lists,
+		notifications: notifications, webhooks: webhooks, chats: chats, hub: hub, Router: router, validate: validate, cfg: cfg}
 
 	h.Router.Route("/v1.0/users", func(r chi.Router) {
 		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
 		r.Get("/", h.GetAllUsers)
+		r.Get("/search", h.SearchUsers)
+		r.Get("/by-username/{user_name}", h.GetUserByUserName)
+		r.Get("/follow-requests", h.GetFollowRequests)
+		r.Post("/follow-requests/{id}", h.ApproveFollowRequest)
+		r.Delete("/follow-requests/{id}", h.RejectFollowRequest)
+		r.Get("/blocks", h.GetBlockedUsers)
+		r.Get("/mutes", h.GetMutedUsers)
+		r.Get("/muted-words", h.GetMutedWords)
+		r.Post("/muted-words", h.CreateMutedWord)
+		r.Delete("/muted-words/{id}", h.DeleteMutedWord)
 
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", h.GetUserByID)
+			r.Post("/follow", h.FollowUser)
+			r.Delete("/follow", h.UnfollowUser)
+			r.Get("/followers", h.GetFollowers)
+			r.Get("/following", h.GetFollowing)
+			r.Post("/block", h.BlockUser)
+			r.Delete("/block", h.UnblockUser)
+			r.Post("/mute", h.MuteUser)
+			r.Delete("/mute", h.UnmuteUser)
 			r.With(middleware.RequestAuthSameID(cfg.AccessTokenSecret)).Put("/", h.UpdateUser)
 			r.With(middleware.RequestAuthSameID(cfg.AccessTokenSecret)).Delete("/", h.DeleteUserByID)
 		})
@@ -64,12 +112,123 @@ func NewHandler(
 		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
 		r.Get("/", h.GetAllPosts)
 		r.Post("/", h.CreatePost)
+		r.Get("/mentions", h.GetMentionedPosts)
+		r.Get("/drafts", h.GetDrafts)
 
 		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.GetPostByID)
+			r.Put("/", h.UpdateDraft)
 			r.Delete("/", h.DeletePostByID)
+			r.Post("/publish", h.PublishPost)
+			r.Post("/pin", h.PinPost)
+			r.Delete("/pin", h.UnpinPost)
+			r.Post("/mute", h.MuteConversation)
+			r.Delete("/mute", h.UnmuteConversation)
 			r.Post("/view", h.ViewPost)
 			r.Post("/like", h.LikePost)
 			r.Delete("/like", h.DislikePost)
+			r.Post("/poll/votes", h.VotePoll)
+		})
+	})
+
+	h.Router.Route("/v1.0/timeline", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/home", h.GetHomeTimeline)
+	})
+
+	h.Router.Route("/v1.0/notifications", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/", h.GetNotifications)
+		r.Get("/unread-count", h.GetUnreadNotificationsCount)
+		r.Post("/read", h.MarkAllNotificationsRead)
+		r.Post("/{id}/read", h.MarkNotificationRead)
+		r.Get("/preferences", h.GetNotificationPreferences)
+		r.Put("/preferences", h.UpdateNotificationPreferences)
+	})
+
+	h.Router.Route("/v1.0/stream", func(r chi.Router) {
+		r.Use(middleware.QueryToken)
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/", h.Stream)
+	})
+
+	h.Router.Route("/v1.0/socket", func(r chi.Router) {
+		r.Use(middleware.QueryToken)
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/", h.Socket)
+	})
+
+	h.Router.Route("/v1.0/recommendations", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/users", h.GetRecommendedUsers)
+	})
+
+	h.Router.Route("/v1.0/lists", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/", h.GetLists)
+		r.Post("/", h.CreateList)
+		r.Get("/subscriptions", h.GetSubscribedLists)
+		r.Get("/memberships", h.GetListMemberships)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.GetListByID)
+			r.Put("/", h.UpdateList)
+			r.Delete("/", h.DeleteList)
+			r.Get("/timeline", h.GetListTimeline)
+			r.Get("/members", h.GetListMembers)
+			r.Post("/members/{user_id}", h.AddListMember)
+			r.Delete("/members/{user_id}", h.RemoveListMember)
+			r.Post("/subscribe", h.SubscribeList)
+			r.Delete("/subscribe", h.UnsubscribeList)
+		})
+	})
+
+	h.Router.Route("/v1.0/chats", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/", h.GetChats)
+		r.Post("/", h.CreateChat)
+		r.Get("/unread-count", h.GetUnreadMessagesCount)
+		r.Post("/groups", h.CreateGroupChat)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.GetChatByID)
+			r.Put("/", h.UpdateChat)
+			r.Post("/read", h.MarkChatRead)
+			r.Post("/mute", h.MuteChat)
+			r.Delete("/mute", h.UnmuteChat)
+			r.Post("/members", h.AddChatMember)
+			r.Put("/members/{user_id}", h.UpdateChatMember)
+			r.Delete("/members/{user_id}", h.RemoveChatMember)
+			r.Post("/leave", h.LeaveChat)
+			r.Get("/messages", h.GetChatMessages)
+			r.Post("/messages", h.SendMessage)
+			r.Delete("/messages/{message_id}", h.DeleteMessage)
+			r.Post("/messages/{message_id}/unsend", h.UnsendMessage)
+		})
+	})
+
+	h.Router.Route("/v1.0/webhooks", func(r chi.Router) {
+		r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+		r.Get("/", h.GetWebhooks)
+		r.Post("/", h.CreateWebhook)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.GetWebhookByID)
+			r.Put("/", h.UpdateWebhook)
+			r.Delete("/", h.DeleteWebhook)
+			r.Get("/deliveries", h.GetWebhookDeliveries)
+			r.Post("/deliveries/{delivery_id}/redeliver", h.RedeliverWebhook)
+		})
+	})
+
+	h.Router.Route("/v1.0/media", func(r chi.Router) {
+		r.Get("/files/*", h.GetMediaFile)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequestAuth(cfg.AccessTokenSecret))
+			r.Post("/", h.UploadMedia)
+			r.Get("/{id}", h.GetMediaByID)
+			r.Put("/{id}", h.UpdateMedia)
 		})
 	})
 
@@ -0,0 +1,421 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+func (h *Handler) CreateList(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var createDTO models.CreateListDTO
+	if err = json.Unmarshal(body, &createDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	createDTO.UserID = userID
+	err = h.validate.Struct(createDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	readDTO, err := h.lists.CreateList(createDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) GetListByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTO, err := h.lists.GetListByID(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// GetLists lists the lists owned by the user in user_id, the current user by
+// default.
+func (h *Handler) GetLists(w http.ResponseWriter, r *http.Request) {
+	h.writeLists(w, r, func(dto *models.FilterListDTO) {
+		ownerID, err := strconv.ParseUint(r.URL.Query().Get("user_id"), 10, 64)
+		if err != nil {
+			ownerID = dto.ViewerID
+		}
+		dto.OwnerID = ownerID
+	})
+}
+
+func (h *Handler) GetSubscribedLists(w http.ResponseWriter, r *http.Request) {
+	h.writeLists(w, r, func(dto *models.FilterListDTO) {
+		dto.SubscriberID = dto.ViewerID
+	})
+}
+
+func (h *Handler) GetListMemberships(w http.ResponseWriter, r *http.Request) {
+	h.writeLists(w, r, func(dto *models.FilterListDTO) {
+		dto.MemberID = dto.ViewerID
+	})
+}
+
+func (h *Handler) writeLists(w http.ResponseWriter, r *http.Request, filter func(dto *models.FilterListDTO)) {
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	offset, err := strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		offset = 0
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	filterDTO := models.FilterListDTO{ViewerID: userID, Limit: limit, Offset: offset}
+	filter(&filterDTO)
+	readDTOs, err := h.lists.GetLists(filterDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTOs)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var updateDTO models.UpdateListDTO
+	if err = json.Unmarshal(body, &updateDTO); err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
+	err = h.validate.Struct(updateDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	readDTO, err := h.lists.UpdateList(id, userID, updateDTO)
+	if err != nil {
+		switch err {
+		case repository.ErrNoFieldsToUpdate:
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.lists.DeleteList(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) AddListMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	memberID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.lists.AddListMember(id, userID, memberID)
+	if err != nil {
+		switch err {
+		case repository.ErrBlocked:
+			h.JSONError(w, http.StatusForbidden, err.Error())
+		default:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RemoveListMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	memberID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.lists.RemoveListMember(id, userID, memberID)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetListMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	offset, err := strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		offset = 0
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTOs, err := h.lists.GetListMembers(id, userID, limit, offset)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		default:
+			h.JSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	resp, err := json.Marshal(readDTOs)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) SubscribeList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.lists.SubscribeList(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) UnsubscribeList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.lists.UnsubscribeList(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetListTimeline reads the posts of the list members the way the home
+// timeline reads followed accounts: newest first, without replies.
+func (h *Handler) GetListTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	offset, err := strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		offset = 0
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	if _, err := h.lists.GetListByID(id, userID); err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	filterDTO := models.FilterPostDTO{
+		UserID: userID,
+		ListID: id,
+		Limit:  limit,
+		Offset: offset,
+	}
+	h.writePosts(w, r, filterDTO)
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreateList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	lists := mocks.NewMockListService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, lists, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		serviceCalled bool
+	}{
+		{
+			name:          "Success create",
+			body:          `{"name": "Gophers", "private": true}`,
+			expectedCode:  http.StatusCreated,
+			serviceCalled: true,
+		},
+		{
+			name:         "Empty name",
+			body:         `{"name": ""}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Name too long",
+			body:         fmt.Sprintf(`{"name": "%051d"}`, 0),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				lists.EXPECT().CreateList(models.CreateListDTO{UserID: 1, Name: "Gophers", Private: true}).
+					Return(&models.ReadListDTO{ID: 1, UserID: 1, Name: "Gophers", Private: true}, nil)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/lists"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetLists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	lists := mocks.NewMockListService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, lists, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name   string
+		path   string
+		filter models.FilterListDTO
+	}{
+		{
+			name:   "Own lists",
+			path:   "/v1.0/lists",
+			filter: models.FilterListDTO{ViewerID: 1, OwnerID: 1, Limit: 10},
+		},
+		{
+			name:   "Lists of another user",
+			path:   "/v1.0/lists?user_id=2",
+			filter: models.FilterListDTO{ViewerID: 1, OwnerID: 2, Limit: 10},
+		},
+		{
+			name:   "Subscriptions",
+			path:   "/v1.0/lists/subscriptions",
+			filter: models.FilterListDTO{ViewerID: 1, SubscriberID: 1, Limit: 10},
+		},
+		{
+			name:   "Memberships",
+			path:   "/v1.0/lists/memberships",
+			filter: models.FilterListDTO{ViewerID: 1, MemberID: 1, Limit: 10},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lists.EXPECT().GetLists(tc.filter).Return([]models.ReadListDTO{{ID: 1, Name: "Gophers"}}, nil)
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			resp, err := req.Get(httpSrv.URL + tc.path)
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+			var body []models.ReadListDTO
+			assert.NoError(t, json.Unmarshal(resp.Body(), &body), "error decoding response")
+			assert.Len(t, body, 1, "Lists count mismatch")
+		})
+	}
+}
+
+func TestHandler_ListMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	lists := mocks.NewMockListService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, lists, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		serviceError error
+		expectedCode int
+	}{
+		{
+			name:         "Success add",
+			method:       http.MethodPost,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Add to foreign list",
+			method:       http.MethodPost,
+			serviceError: repository.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Add blocked user",
+			method:       http.MethodPost,
+			serviceError: repository.ErrBlocked,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Success remove",
+			method:       http.MethodDelete,
+			expectedCode: http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				lists.EXPECT().AddListMember(uint64(5), uint64(1), uint64(2)).Return(tc.serviceError)
+			} else {
+				lists.EXPECT().RemoveListMember(uint64(5), uint64(1), uint64(2)).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = httpSrv.URL + "/v1.0/lists/5/members/2"
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_SubscribeList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	lists := mocks.NewMockListService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, lists, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	lists.EXPECT().SubscribeList(uint64(5), uint64(1)).Return(nil)
+	lists.EXPECT().SubscribeList(uint64(6), uint64(1)).Return(repository.ErrNotFound)
+	lists.EXPECT().UnsubscribeList(uint64(5), uint64(1)).Return(nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Post(httpSrv.URL + "/v1.0/lists/5/subscribe")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+	resp, err = req.Post(httpSrv.URL + "/v1.0/lists/6/subscribe")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Private list should not be subscribable")
+	resp, err = req.Delete(httpSrv.URL + "/v1.0/lists/5/subscribe")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+}
+
+func TestHandler_GetListTimeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	lists := mocks.NewMockListService(ctrl)
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, lists, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	lists.EXPECT().GetListByID(uint64(5), uint64(1)).Return(&models.ReadListDTO{ID: 5}, nil)
+	posts.EXPECT().GetAllPosts(models.FilterPostDTO{UserID: 1, ListID: 5, Limit: 10}).
+		Return([]models.ReadPostDTO{{ID: 1, Text: "Lorem Ipsum"}}, nil)
+	lists.EXPECT().GetListByID(uint64(6), uint64(1)).Return(nil, repository.ErrNotFound)
+
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Get(httpSrv.URL + "/v1.0/lists/5/timeline")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	resp, err = req.Get(httpSrv.URL + "/v1.0/lists/6/timeline")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Response code didn't match expected")
+}
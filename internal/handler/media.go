@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+const multipartOverhead = 1 << 20
+
+func (h *Handler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MediaMaxSize+multipartOverhead)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.JSONError(w, http.StatusRequestEntityTooLarge, service.ErrMediaTooLarge.Error())
+			return
+		}
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, h.cfg.MediaMaxSize+1))
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	uploadDTO := models.UploadMediaDTO{
+		UserID:  userID,
+		Data:    data,
+		AltText: r.FormValue("alt_text"),
+	}
+	err = h.validate.Struct(uploadDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	readDTO, err := h.media.UploadMedia(uploadDTO)
+	if err != nil {
+		switch err {
+		case service.ErrMediaTooLarge:
+			h.JSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+		case service.ErrUnsupportedMediaType:
+			h.JSONError(w, http.StatusUnsupportedMediaType, err.Error())
+		case service.ErrImageTooLarge:
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.JSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) GetMediaByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTO, err := h.media.GetMediaByID(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) UpdateMedia(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var updateDTO models.UpdateMediaDTO
+	if err = json.Unmarshal(body, &updateDTO); err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = h.validate.Struct(updateDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	readDTO, err := h.media.UpdateMedia(id, userID, updateDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) GetMediaFile(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+	file, err := h.media.OpenMediaFile(key)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer file.Close()
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, file)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/storage"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_UploadMedia(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	media := mocks.NewMockMediaService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	cfg.MediaMaxSize = 1024
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, media, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		expectedCode  int
+		file          []byte
+		altText       string
+		responseDTO   *models.ReadMediaDTO
+		serviceError  error
+		serviceCalled bool
+	}{
+		{
+			name:          "Success upload",
+			expectedCode:  http.StatusCreated,
+			file:          []byte("image"),
+			altText:       "A gopher",
+			responseDTO:   &models.ReadMediaDTO{ID: 1, URL: "/media/1.png"},
+			serviceError:  nil,
+			serviceCalled: true,
+		},
+		{
+			name:          "Unsupported media type",
+			expectedCode:  http.StatusUnsupportedMediaType,
+			file:          []byte("text"),
+			serviceError:  service.ErrUnsupportedMediaType,
+			serviceCalled: true,
+		},
+		{
+			name:          "Too many pixels",
+			expectedCode:  http.StatusUnprocessableEntity,
+			file:          []byte("image"),
+			serviceError:  service.ErrImageTooLarge,
+			serviceCalled: true,
+		},
+		{
+			name:          "Request body too large",
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			file:          make([]byte, 2<<20),
+			serviceCalled: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				media.EXPECT().UploadMedia(models.UploadMediaDTO{
+					UserID:  1,
+					Data:    tc.file,
+					AltText: tc.altText,
+				}).Return(tc.responseDTO, tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.SetFileReader("file", "image.png", bytes.NewReader(tc.file))
+			req.SetFormData(map[string]string{"alt_text": tc.altText})
+			resp, err := req.Post(httpSrv.URL + "/v1.0/media")
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetMediaByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	media := mocks.NewMockMediaService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, media, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		expectedCode  int
+		mediaID       string
+		responseDTO   *models.ReadMediaDTO
+		serviceError  error
+		serviceCalled bool
+	}{
+		{
+			name:          "Success get media",
+			expectedCode:  http.StatusOK,
+			mediaID:       "1",
+			responseDTO:   &models.ReadMediaDTO{ID: 1, URL: "/media/1.png"},
+			serviceCalled: true,
+		},
+		{
+			name:          "Invalid media ID",
+			expectedCode:  http.StatusNotFound,
+			mediaID:       "abc",
+			serviceCalled: false,
+		},
+		{
+			name:          "Media not found",
+			expectedCode:  http.StatusNotFound,
+			mediaID:       "2",
+			serviceError:  repository.ErrNotFound,
+			serviceCalled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				media.EXPECT().GetMediaByID(gomock.Any(), uint64(1)).Return(tc.responseDTO, tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			resp, err := req.Get(httpSrv.URL + "/v1.0/media/" + tc.mediaID)
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_UpdateMedia(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	media := mocks.NewMockMediaService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, media, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		expectedCode  int
+		body          string
+		responseDTO   *models.ReadMediaDTO
+		serviceError  error
+		serviceCalled bool
+	}{
+		{
+			name:          "Success update alt text",
+			expectedCode:  http.StatusOK,
+			body:          `{"alt_text": "A gopher"}`,
+			responseDTO:   &models.ReadMediaDTO{ID: 1, AltText: "A gopher"},
+			serviceCalled: true,
+		},
+		{
+			name:          "Media of other user",
+			expectedCode:  http.StatusNotFound,
+			body:          `{"alt_text": "A gopher"}`,
+			serviceError:  repository.ErrNotFound,
+			serviceCalled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				media.EXPECT().UpdateMedia(uint64(1), uint64(1), models.UpdateMediaDTO{AltText: "A gopher"}).
+					Return(tc.responseDTO, tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.SetBody(tc.body)
+			resp, err := req.Put(httpSrv.URL + "/v1.0/media/1")
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetMediaFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	media := mocks.NewMockMediaService(ctrl)
+	cfg := config.GetConfig()
+	handler := NewHandler(nil, nil, nil, media, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	media.EXPECT().OpenMediaFile("media/1.png").Return(io.NopCloser(bytes.NewReader([]byte("png"))), nil)
+	resp, err := resty.New().R().Get(httpSrv.URL + "/v1.0/media/files/media/1.png")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	assert.Equal(t, "image/png", resp.Header().Get("Content-Type"), "Content type mismatch")
+	assert.Equal(t, "png", string(resp.Body()), "Body mismatch")
+
+	media.EXPECT().OpenMediaFile("media/2.png").Return(nil, storage.ErrBlobNotFound)
+	resp, err = resty.New().R().Get(httpSrv.URL + "/v1.0/media/files/media/2.png")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Response code didn't match expected")
+}
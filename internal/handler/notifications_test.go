@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_GetNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	notifications := mocks.NewMockNotificationService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, notifications, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	page := &models.NotificationPageDTO{
+		Items:      []models.ReadNotificationDTO{{ID: 3, Type: models.NotificationTypeLike, ActorsCount: 13}},
+		NextCursor: "next",
+	}
+	notifications.EXPECT().GetNotificationsPage(uint64(1), nil, uint64(20)).Return(page, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Get(httpSrv.URL + "/v1.0/notifications?limit=20")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	assert.Contains(t, resp.Header().Get("Link"), `rel="next"`, "Link header is missing")
+	var body models.NotificationPageDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &body), "error decoding response")
+	assert.Equal(t, *page, body, "Page mismatch")
+
+	resp, err = req.Get(httpSrv.URL + "/v1.0/notifications?cursor=garbage")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode(), "Malformed cursor should be rejected")
+}
+
+func TestHandler_NotificationsRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	notifications := mocks.NewMockNotificationService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, notifications, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	notifications.EXPECT().CountUnread(uint64(1)).Return(uint64(4), nil)
+	notifications.EXPECT().MarkRead(uint64(5), uint64(1)).Return(nil)
+	notifications.EXPECT().MarkRead(uint64(6), uint64(1)).Return(repository.ErrNotFound)
+	notifications.EXPECT().MarkAllRead(uint64(1)).Return(nil)
+
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Get(httpSrv.URL + "/v1.0/notifications/unread-count")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	assert.JSONEq(t, `{"count": 4}`, string(resp.Body()), "Unread count mismatch")
+
+	resp, err = req.Post(httpSrv.URL + "/v1.0/notifications/5/read")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+	resp, err = req.Post(httpSrv.URL + "/v1.0/notifications/6/read")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Response code didn't match expected")
+	resp, err = req.Post(httpSrv.URL + "/v1.0/notifications/read")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+}
+
+func TestHandler_UpdateNotificationPreferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	notifications := mocks.NewMockNotificationService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, notifications, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		body         string
+		serviceError error
+		expectedCode int
+		serviceCall  bool
+	}{
+		{
+			name:         "Success update",
+			body:         `{"like": false}`,
+			expectedCode: http.StatusOK,
+			serviceCall:  true,
+		},
+		{
+			name:         "Unknown type",
+			body:         `{"quote": false}`,
+			serviceError: service.ErrInvalidNotificationType,
+			expectedCode: http.StatusUnprocessableEntity,
+			serviceCall:  true,
+		},
+		{
+			name:         "Not a map of flags",
+			body:         `{"like": "off"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCall {
+				var update models.NotificationPreferencesDTO
+				assert.NoError(t, json.Unmarshal([]byte(tc.body), &update))
+				notifications.EXPECT().UpdatePreferences(uint64(1), update).
+					Return(models.NotificationPreferencesDTO{models.NotificationTypeLike: false}, tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPut
+			req.URL = httpSrv.URL + "/v1.0/notifications/preferences"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
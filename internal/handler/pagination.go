@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+// cursorParam reports whether the request asked for cursor pagination and
+// decodes its cursor. An empty cursor parameter requests the first page.
+func cursorParam(r *http.Request) (*models.Cursor, bool, error) {
+	values, ok := r.URL.Query()["cursor"]
+	if !ok {
+		return nil, false, nil
+	}
+	if len(values) == 0 || values[0] == "" {
+		return nil, true, nil
+	}
+	cursor, err := utils.DecodeCursor(values[0])
+	return cursor, true, err
+}
+
+// setLinkHeader advertises the neighbouring pages as RFC 8288 links that
+// repeat the request with a different cursor.
+func setLinkHeader(w http.ResponseWriter, r *http.Request, next, prev string) {
+	links := []string{}
+	for _, link := range []struct{ rel, cursor string }{{"next", next}, {"prev", prev}} {
+		if link.cursor == "" {
+			continue
+		}
+		u := *r.URL
+		query := u.Query()
+		query.Set("cursor", link.cursor)
+		query.Del("offset")
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.String(), link.rel))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
 	"github.com/shekshuev/gophertalk-backend/internal/utils"
 )
 
@@ -28,6 +33,11 @@ func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		ownerID = 0
 	}
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && sort != models.PostSortRelevance && sort != models.PostSortRecent {
+		h.JSONError(w, http.StatusUnprocessableEntity, ErrValidationError.Error())
+		return
+	}
 
 	claims, ok := utils.GetClaimsFromContext(r.Context())
 	if !ok {
@@ -46,13 +56,126 @@ func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 		Offset:    offset,
 		ReplyToID: replyToID,
 		Search:    r.URL.Query().Get("search"),
+		Sort:      sort,
+	}
+	h.writePosts(w, r, filterDTO)
+}
+
+func (h *Handler) GetPostByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
 	}
-	readDTOs, err := h.posts.GetAllPosts(filterDTO)
+	readDTO, err := h.posts.GetPostByID(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTO)
 	if err != nil {
 		h.JSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	resp, err := json.Marshal(readDTOs)
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) GetMentionedPosts(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	offset, err := strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		offset = 0
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	filterDTO := models.FilterPostDTO{
+		UserID:      userID,
+		MentionedID: userID,
+		Limit:       limit,
+		Offset:      offset,
+	}
+	h.writePosts(w, r, filterDTO)
+}
+
+func (h *Handler) GetHomeTimeline(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	offset, err := strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		offset = 0
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	filterDTO := models.FilterPostDTO{
+		UserID: userID,
+		Home:   true,
+		Limit:  limit,
+		Offset: offset,
+	}
+	h.writePosts(w, r, filterDTO)
+}
+
+// writePosts responds with a plain array for offset paging, or with a page
+// envelope and Link header when the request carries a cursor parameter.
+func (h *Handler) writePosts(w http.ResponseWriter, r *http.Request, filterDTO models.FilterPostDTO) {
+	cursor, cursorMode, err := cursorParam(r)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body interface{}
+	if cursorMode {
+		filterDTO.Cursor = cursor
+		page, err := h.posts.GetPostsPage(filterDTO)
+		if err != nil {
+			h.postsError(w, err)
+			return
+		}
+		setLinkHeader(w, r, page.NextCursor, page.PrevCursor)
+		body = page
+	} else {
+		readDTOs, err := h.posts.GetAllPosts(filterDTO)
+		if err != nil {
+			h.postsError(w, err)
+			return
+		}
+		body = readDTOs
+	}
+	resp, err := json.Marshal(body)
 	if err != nil {
 		h.JSONError(w, http.StatusBadRequest, err.Error())
 		return
@@ -63,6 +186,28 @@ func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postsError reports malformed search queries as 422 with the position of
+// every problem so clients can underline them, and a protected profile as 403
+// with a marker so clients can offer a follow request.
+func (h *Handler) postsError(w http.ResponseWriter, err error) {
+	if err == repository.ErrProtected {
+		w.WriteHeader(http.StatusForbidden)
+		if err := json.NewEncoder(w).Encode(ProtectedErrorResponse{Error: err.Error(), Protected: true}); err != nil {
+			log.Fatalf("Error encoding JSON: %v", err)
+		}
+		return
+	}
+	var parseErr *search.ParseError
+	if !errors.As(err, &parseErr) {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(SearchErrorResponse{Error: parseErr.Error(), Issues: parseErr.Issues}); err != nil {
+		log.Fatalf("Error encoding JSON: %v", err)
+	}
+}
+
 func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -92,7 +237,16 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	}
 	readDTO, err := h.posts.CreatePost(createDTO)
 	if err != nil {
-		h.JSONError(w, http.StatusBadRequest, err.Error())
+		switch err {
+		case service.ErrInvalidPollDuration, service.ErrInvalidPublishAt:
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		case repository.ErrNotFound:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		case repository.ErrReplyNotAllowed:
+			h.JSONError(w, http.StatusForbidden, err.Error())
+		default:
+			h.JSONError(w, http.StatusBadRequest, err.Error())
+		}
 		return
 	}
 	resp, err := json.Marshal(readDTO)
@@ -202,3 +356,268 @@ func (h *Handler) DislikePost(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) VotePoll(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var voteDTO models.VotePollDTO
+	if err = json.Unmarshal(body, &voteDTO); err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = h.validate.Struct(voteDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.posts.VotePoll(id, userID, voteDTO)
+	if err != nil {
+		switch err {
+		case repository.ErrAlreadyVoted, repository.ErrPollClosed:
+			h.JSONError(w, http.StatusConflict, err.Error())
+		case repository.ErrInvalidVote:
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+	if err != nil {
+		limit = 10
+	}
+	offset, err := strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		offset = 0
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTOs, err := h.posts.GetDrafts(userID, limit, offset)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := json.Marshal(readDTOs)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var updateDTO models.UpdateDraftDTO
+	if err = json.Unmarshal(body, &updateDTO); err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = h.validate.Struct(updateDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	readDTO, err := h.posts.UpdateDraft(id, userID, updateDTO)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidPublishAt:
+			h.JSONError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	resp, err := json.Marshal(readDTO)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_, err = w.Write(resp)
+	if err != nil {
+		h.JSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.posts.PublishPost(id, userID)
+	if err != nil {
+		switch err {
+		case repository.ErrReplyNotAllowed:
+			h.JSONError(w, http.StatusForbidden, err.Error())
+		case repository.ErrPollClosed:
+			h.JSONError(w, http.StatusConflict, err.Error())
+		default:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) PinPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.posts.PinPost(id, userID)
+	if err != nil {
+		switch err {
+		case repository.ErrTooManyPinned:
+			h.JSONError(w, http.StatusConflict, err.Error())
+		default:
+			h.JSONError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) UnpinPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.posts.UnpinPost(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) MuteConversation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.posts.MuteConversation(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) UnmuteConversation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidID.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	err = h.posts.UnmuteConversation(id, userID)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,12 +6,16 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/shekshuev/gophertalk-backend/internal/config"
 	"github.com/shekshuev/gophertalk-backend/internal/mocks"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
 	"github.com/shekshuev/gophertalk-backend/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,7 +34,7 @@ func TestHandler_GetAllPosts(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(nil, nil, posts, &cfg)
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -39,6 +43,7 @@ func TestHandler_GetAllPosts(t *testing.T) {
 		expectedCode  int
 		limit         string
 		offset        string
+		sort          string
 		responseDTOs  []models.ReadPostDTO
 		serviceError  error
 		serviceCalled bool
@@ -79,6 +84,23 @@ func TestHandler_GetAllPosts(t *testing.T) {
 			serviceError:  assert.AnError,
 			serviceCalled: true,
 		},
+		{
+			name:          "Sort by recency",
+			expectedCode:  http.StatusOK,
+			limit:         "10",
+			offset:        "0",
+			sort:          models.PostSortRecent,
+			responseDTOs:  []models.ReadPostDTO{{ID: 1, Text: "Lorem Ipsum"}},
+			serviceCalled: true,
+		},
+		{
+			name:          "Invalid sort",
+			expectedCode:  http.StatusUnprocessableEntity,
+			limit:         "10",
+			offset:        "0",
+			sort:          "popular",
+			serviceCalled: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -90,10 +112,85 @@ func TestHandler_GetAllPosts(t *testing.T) {
 			req := resty.New().R()
 			req.Header.Set("Authorization", "Bearer "+accessToken)
 			req.Method = http.MethodGet
-			req.URL = httpSrv.URL + "/v1.0/posts?limit=" + tc.limit + "&offset=" + tc.offset
+			req.URL = httpSrv.URL + "/v1.0/posts?limit=" + tc.limit + "&offset=" + tc.offset + "&sort=" + tc.sort
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetMentionedPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		expectedCode  int
+		responseDTOs  []models.ReadPostDTO
+		serviceError  error
+		serviceCalled bool
+	}{
+		{
+			name:         "Success fetching mentions",
+			expectedCode: http.StatusOK,
+			responseDTOs: []models.ReadPostDTO{{
+				ID:   1,
+				Text: "Hello @johndoe",
+				Entities: []models.ReadPostEntityDTO{
+					{Type: models.EntityTypeMention, Offset: 6, Length: 8, UserID: 1, UserName: "johndoe"},
+				},
+			}},
+			serviceError:  nil,
+			serviceCalled: true,
+		},
+		{
+			name:          "Service error",
+			expectedCode:  http.StatusBadRequest,
+			responseDTOs:  nil,
+			serviceError:  assert.AnError,
+			serviceCalled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				posts.EXPECT().GetAllPosts(models.FilterPostDTO{
+					UserID:      1,
+					MentionedID: 1,
+					Limit:       10,
+					Offset:      0,
+				}).Return(tc.responseDTOs, tc.serviceError)
+			}
+
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodGet
+			req.URL = httpSrv.URL + "/v1.0/posts/mentions"
 			resp, err := req.Send()
 			assert.NoError(t, err, "error making HTTP request")
 			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+			if tc.serviceError == nil {
+				var readDTOs []models.ReadPostDTO
+				err = json.Unmarshal(resp.Body(), &readDTOs)
+				assert.NoError(t, err, "error unmarshalling response")
+				assert.Equal(t, tc.responseDTOs, readDTOs, "Response mismatch")
+			}
 		})
 	}
 }
@@ -112,10 +209,11 @@ func TestHandler_CreatePost(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(nil, nil, posts, &cfg)
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
+	replyToID := uint64(2)
 	testCases := []struct {
 		name          string
 		expectedCode  int
@@ -152,6 +250,35 @@ func TestHandler_CreatePost(t *testing.T) {
 			serviceError:  assert.AnError,
 			serviceCalled: true,
 		},
+		{
+			name:         "Invalid reply policy",
+			expectedCode: http.StatusUnprocessableEntity,
+			createDTO: models.CreatePostDTO{
+				Text:        "Lorem ipsum",
+				ReplyPolicy: "friends",
+			},
+			serviceCalled: false,
+		},
+		{
+			name:         "Reply not allowed",
+			expectedCode: http.StatusForbidden,
+			createDTO: models.CreatePostDTO{
+				Text:      "Lorem ipsum",
+				ReplyToID: &replyToID,
+			},
+			serviceError:  repository.ErrReplyNotAllowed,
+			serviceCalled: true,
+		},
+		{
+			name:         "Reply to invisible post",
+			expectedCode: http.StatusNotFound,
+			createDTO: models.CreatePostDTO{
+				Text:      "Lorem ipsum",
+				ReplyToID: &replyToID,
+			},
+			serviceError:  repository.ErrNotFound,
+			serviceCalled: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -185,7 +312,7 @@ func TestHandler_DeletePostByID(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(nil, nil, posts, &cfg)
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -249,7 +376,7 @@ func TestHandler_ViewPost(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(nil, nil, posts, &cfg)
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -306,7 +433,7 @@ func TestHandler_LikePost(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(nil, nil, posts, &cfg)
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -363,7 +490,7 @@ func TestHandler_DislikePost(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(nil, nil, posts, &cfg)
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -405,3 +532,623 @@ func TestHandler_DislikePost(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_VotePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		expectedCode  int
+		postID        string
+		body          string
+		serviceError  error
+		serviceCalled bool
+	}{
+		{
+			name:          "Success vote",
+			expectedCode:  http.StatusCreated,
+			postID:        "1",
+			body:          `{"option_ids": [3]}`,
+			serviceCalled: true,
+		},
+		{
+			name:          "Already voted",
+			expectedCode:  http.StatusConflict,
+			postID:        "1",
+			body:          `{"option_ids": [3]}`,
+			serviceError:  repository.ErrAlreadyVoted,
+			serviceCalled: true,
+		},
+		{
+			name:          "Poll is closed",
+			expectedCode:  http.StatusConflict,
+			postID:        "1",
+			body:          `{"option_ids": [3]}`,
+			serviceError:  repository.ErrPollClosed,
+			serviceCalled: true,
+		},
+		{
+			name:          "Invalid option",
+			expectedCode:  http.StatusUnprocessableEntity,
+			postID:        "1",
+			body:          `{"option_ids": [42]}`,
+			serviceError:  repository.ErrInvalidVote,
+			serviceCalled: true,
+		},
+		{
+			name:          "Post without poll",
+			expectedCode:  http.StatusNotFound,
+			postID:        "1",
+			body:          `{"option_ids": [3]}`,
+			serviceError:  repository.ErrNotFound,
+			serviceCalled: true,
+		},
+		{
+			name:          "No options",
+			expectedCode:  http.StatusUnprocessableEntity,
+			postID:        "1",
+			body:          `{"option_ids": []}`,
+			serviceCalled: false,
+		},
+		{
+			name:          "Invalid post ID",
+			expectedCode:  http.StatusNotFound,
+			postID:        "abc",
+			body:          `{"option_ids": [3]}`,
+			serviceCalled: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				posts.EXPECT().VotePoll(uint64(1), uint64(1), gomock.Any()).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/posts/" + tc.postID + "/poll/votes"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetDrafts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	readDTOs := []models.ReadPostDTO{{ID: 1, Text: "Draft", State: models.PostStateDraft}}
+	posts.EXPECT().GetDrafts(uint64(1), uint64(10), uint64(0)).Return(readDTOs, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/posts/drafts"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	var drafts []models.ReadPostDTO
+	err = json.Unmarshal(resp.Body(), &drafts)
+	assert.NoError(t, err, "error unmarshalling response")
+	assert.Equal(t, readDTOs, drafts, "Drafts mismatch")
+}
+
+func TestHandler_UpdateDraft(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name          string
+		expectedCode  int
+		body          string
+		serviceError  error
+		serviceCalled bool
+	}{
+		{
+			name:          "Success update draft",
+			expectedCode:  http.StatusOK,
+			body:          `{"text": "Edited"}`,
+			serviceCalled: true,
+		},
+		{
+			name:          "Publish time in the past",
+			expectedCode:  http.StatusUnprocessableEntity,
+			body:          `{"text": "Edited", "publish_at": "2020-01-01T00:00:00Z"}`,
+			serviceError:  service.ErrInvalidPublishAt,
+			serviceCalled: true,
+		},
+		{
+			name:          "Published post",
+			expectedCode:  http.StatusNotFound,
+			body:          `{"text": "Edited"}`,
+			serviceError:  repository.ErrNotFound,
+			serviceCalled: true,
+		},
+		{
+			name:          "Empty text",
+			expectedCode:  http.StatusUnprocessableEntity,
+			body:          `{"text": ""}`,
+			serviceCalled: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCalled {
+				if tc.serviceError != nil {
+					posts.EXPECT().UpdateDraft(uint64(1), uint64(1), gomock.Any()).Return(nil, tc.serviceError)
+				} else {
+					posts.EXPECT().UpdateDraft(uint64(1), uint64(1), gomock.Any()).
+						Return(&models.ReadPostDTO{ID: 1, Text: "Edited", State: models.PostStateDraft}, nil)
+				}
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPut
+			req.URL = httpSrv.URL + "/v1.0/posts/1"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_PublishPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		expectedCode int
+		serviceError error
+	}{
+		{
+			name:         "Success publish",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Post not found",
+			expectedCode: http.StatusNotFound,
+			serviceError: repository.ErrNotFound,
+		},
+		{
+			name:         "Reply no longer allowed",
+			expectedCode: http.StatusForbidden,
+			serviceError: repository.ErrReplyNotAllowed,
+		},
+		{
+			name:         "Poll closed",
+			expectedCode: http.StatusConflict,
+			serviceError: repository.ErrPollClosed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			posts.EXPECT().PublishPost(uint64(1), uint64(1)).Return(tc.serviceError)
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/posts/1/publish"
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_PinPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		expectedCode int
+		serviceError error
+	}{
+		{
+			name:         "Success pin",
+			method:       http.MethodPost,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Too many pinned posts",
+			method:       http.MethodPost,
+			expectedCode: http.StatusConflict,
+			serviceError: repository.ErrTooManyPinned,
+		},
+		{
+			name:         "Pin foreign post",
+			method:       http.MethodPost,
+			expectedCode: http.StatusNotFound,
+			serviceError: repository.ErrNotFound,
+		},
+		{
+			name:         "Success unpin",
+			method:       http.MethodDelete,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Unpin not pinned post",
+			method:       http.MethodDelete,
+			expectedCode: http.StatusNotFound,
+			serviceError: repository.ErrNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				posts.EXPECT().PinPost(uint64(1), uint64(1)).Return(tc.serviceError)
+			} else {
+				posts.EXPECT().UnpinPost(uint64(1), uint64(1)).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = httpSrv.URL + "/v1.0/posts/1/pin"
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_MuteConversation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		expectedCode int
+		serviceError error
+	}{
+		{
+			name:         "Success mute",
+			method:       http.MethodPost,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Mute invisible post",
+			method:       http.MethodPost,
+			expectedCode: http.StatusNotFound,
+			serviceError: repository.ErrNotFound,
+		},
+		{
+			name:         "Success unmute",
+			method:       http.MethodDelete,
+			expectedCode: http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				posts.EXPECT().MuteConversation(uint64(1), uint64(1)).Return(tc.serviceError)
+			} else {
+				posts.EXPECT().UnmuteConversation(uint64(1), uint64(1)).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = httpSrv.URL + "/v1.0/posts/1/mute"
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetPostByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		readDTO      *models.ReadPostDTO
+		expectedCode int
+		serviceError error
+	}{
+		{
+			name:         "Success get post",
+			readDTO:      &models.ReadPostDTO{ID: 1, Text: "Hello", Visibility: models.PostVisibilityFollowers},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invisible post",
+			expectedCode: http.StatusNotFound,
+			serviceError: repository.ErrNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			posts.EXPECT().GetPostByID(uint64(1), uint64(1)).Return(tc.readDTO, tc.serviceError)
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodGet
+			req.URL = httpSrv.URL + "/v1.0/posts/1"
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+			if tc.readDTO != nil {
+				var post models.ReadPostDTO
+				err = json.Unmarshal(resp.Body(), &post)
+				assert.NoError(t, err, "error unmarshalling response")
+				assert.Equal(t, *tc.readDTO, post, "Post mismatch")
+			}
+		})
+	}
+}
+
+func TestHandler_GetAllPosts_Cursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	cursor := models.Cursor{CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), ID: 10}
+	testCases := []struct {
+		name         string
+		cursor       string
+		expectedCode int
+		page         *models.PostPageDTO
+		link         string
+	}{
+		{
+			name:         "Page with both cursors",
+			cursor:       utils.EncodeCursor(cursor),
+			expectedCode: http.StatusOK,
+			page: &models.PostPageDTO{
+				Items:      []models.ReadPostDTO{{ID: 9, Text: "Hello"}},
+				NextCursor: "n",
+				PrevCursor: "p",
+			},
+			link: `</v1.0/posts/?cursor=n&limit=1&owner_id=2>; rel="next", </v1.0/posts/?cursor=p&limit=1&owner_id=2>; rel="prev"`,
+		},
+		{
+			name:         "Invalid cursor",
+			cursor:       "!!",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.page != nil {
+				posts.EXPECT().GetPostsPage(models.FilterPostDTO{
+					UserID:  1,
+					OwnerID: 2,
+					Limit:   1,
+					Offset:  3,
+					Cursor:  &cursor,
+				}).Return(tc.page, nil)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodGet
+			req.URL = httpSrv.URL + "/v1.0/posts/?limit=1&offset=3&owner_id=2&cursor=" + tc.cursor
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+			if tc.page != nil {
+				assert.Equal(t, tc.link, resp.Header().Get("Link"), "Link header mismatch")
+				var body models.PostPageDTO
+				err = json.Unmarshal(resp.Body(), &body)
+				assert.NoError(t, err, "error unmarshalling response")
+				assert.Equal(t, *tc.page, body, "Page mismatch")
+			}
+		})
+	}
+}
+
+func TestHandler_GetAllPosts_SearchError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	parseErr := &search.ParseError{Issues: []search.Issue{
+		{Offset: 0, Token: "since:yesterday", Message: "since: needs a date like 2026-01-31"},
+	}}
+	posts.EXPECT().GetAllPosts(gomock.Any()).Return(nil, parseErr)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/posts?search=since:yesterday"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode(), "Response code didn't match expected")
+	var body SearchErrorResponse
+	err = json.Unmarshal(resp.Body(), &body)
+	assert.NoError(t, err, "error unmarshalling response")
+	assert.Equal(t, parseErr.Error(), body.Error)
+	assert.Equal(t, parseErr.Issues, body.Issues)
+}
+
+func TestHandler_GetAllPosts_Protected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	posts.EXPECT().GetAllPosts(gomock.Any()).Return(nil, repository.ErrProtected)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/posts?owner_id=2"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode(), "Response code didn't match expected")
+	var body ProtectedErrorResponse
+	err = json.Unmarshal(resp.Body(), &body)
+	assert.NoError(t, err, "error unmarshalling response")
+	assert.True(t, body.Protected, "Protected marker is missing")
+}
+
+func TestHandler_GetHomeTimeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	readDTOs := []models.ReadPostDTO{{ID: 2, Text: "From a followed user"}}
+	posts.EXPECT().GetAllPosts(models.FilterPostDTO{UserID: 1, Home: true, Limit: 20, Offset: 40}).Return(readDTOs, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/timeline/home?limit=20&offset=40"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	var body []models.ReadPostDTO
+	err = json.Unmarshal(resp.Body(), &body)
+	assert.NoError(t, err, "error unmarshalling response")
+	assert.Equal(t, readDTOs, body, "Posts mismatch")
+}
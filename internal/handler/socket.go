@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"golang.org/x/net/websocket"
+)
+
+// socketMaxTopics caps how many posts and users a connection can subscribe
+// to at once.
+const socketMaxTopics = 100
+
+// socketTypingInterval is the shortest time between two typing indicators a
+// connection passes on to the same chat; the ones in between are dropped.
+const socketTypingInterval = 3 * time.Second
+
+var ErrInvalidMessage = errors.New("invalid message")
+var ErrUnknownCommand = errors.New("unknown command")
+var ErrTooManyTopics = errors.New("too many subscriptions")
+var ErrNotFollowed = errors.New("user is not followed")
+var ErrConnectionTooSlow = errors.New("connection too slow")
+var ErrServerShutdown = errors.New("server shutting down")
+
+// Socket serves a WebSocket that carries the same events as the stream plus
+// presence and typing indicators, and accepts commands to change what it
+// receives. The access token is checked at the handshake and its expiry again
+// every SocketAuthInterval; the client renews it with the auth command.
+func (h *Handler) Socket(w http.ResponseWriter, r *http.Request) {
+	if h.hub == nil {
+		h.JSONError(w, http.StatusInternalServerError, ErrStreamUnsupported.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	s := &socket{h: h, userID: userID, typedAt: make(map[uint64]time.Time)}
+	if claims.ExpiresAt != nil {
+		s.expiresAt = claims.ExpiresAt.Time
+	}
+	// no Handshake, so any origin is accepted like the CORS settings do
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		h.sockets.Add(1)
+		defer h.sockets.Done()
+		ws.MaxPayloadBytes = h.cfg.SocketMaxMessageSize
+		s.ws = ws
+		s.serve()
+	}}
+	server.ServeHTTP(w, r)
+}
+
+// WaitSockets waits until every WebSocket is closed or ctx is done. Sockets
+// close once the hub does.
+func (h *Handler) WaitSockets(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.sockets.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type socket struct {
+	h            *Handler
+	ws           *websocket.Conn
+	userID       uint64
+	expiresAt    time.Time
+	subscription *events.Subscription
+	typedAt      map[uint64]time.Time
+}
+
+// serve writes from this goroutine only. Events wait in the subscription's
+// buffer while a write is blocked; a client that cannot keep up is
+// disconnected, and a write taking longer than SocketWriteTimeout ends the
+// connection.
+func (s *socket) serve() {
+	defer s.ws.Close()
+	s.subscription = s.h.hub.Subscribe(events.UserTopic(s.userID))
+	defer s.subscription.Close()
+	connection := s.h.hub.Connect(s.userID)
+	defer connection.Disconnect()
+
+	commands := make(chan models.SocketCommandDTO)
+	done := make(chan struct{})
+	defer close(done)
+	go s.read(commands, done)
+
+	// beat a few times per TTL so one lost beat does not drop the user
+	presence := time.NewTicker(s.h.cfg.PresenceTTL / 3)
+	defer presence.Stop()
+	var authCheck, heartbeat <-chan time.Time
+	if s.h.cfg.SocketAuthInterval > 0 {
+		ticker := time.NewTicker(s.h.cfg.SocketAuthInterval)
+		defer ticker.Stop()
+		authCheck = ticker.C
+	}
+	if s.h.cfg.StreamHeartbeat > 0 {
+		ticker := time.NewTicker(s.h.cfg.StreamHeartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		var err error
+		select {
+		case command, ok := <-commands:
+			if !ok {
+				return
+			}
+			err = s.handle(command)
+		case event, ok := <-s.subscription.Events():
+			if !ok {
+				reason := ErrServerShutdown
+				if s.subscription.Dropped() {
+					reason = ErrConnectionTooSlow
+				}
+				s.send(models.SocketMessageDTO{Type: models.SocketMessageError, Error: reason.Error()})
+				return
+			}
+			err = s.send(models.SocketMessageDTO{ID: event.ID, Type: event.Type, Topic: event.Topic, Data: event.Data})
+		case <-authCheck:
+			if !s.expiresAt.IsZero() && !time.Now().Before(s.expiresAt) {
+				s.send(models.SocketMessageDTO{Type: models.SocketMessageError, Error: utils.ErrTokenExpired.Error()})
+				return
+			}
+		case <-heartbeat:
+			err = s.send(models.SocketMessageDTO{Type: models.SocketMessagePing})
+		case <-presence.C:
+			connection.Heartbeat()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// read passes the client's commands on until the connection breaks.
+func (s *socket) read(commands chan<- models.SocketCommandDTO, done <-chan struct{}) {
+	defer close(commands)
+	for {
+		var command models.SocketCommandDTO
+		err := websocket.JSON.Receive(s.ws, &command)
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, websocket.ErrFrameTooLarge) {
+			command = models.SocketCommandDTO{}
+		} else if err != nil {
+			return
+		}
+		select {
+		case commands <- command:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *socket) send(message models.SocketMessageDTO) error {
+	if err := s.ws.SetWriteDeadline(time.Now().Add(s.h.cfg.SocketWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(s.ws, message)
+}
+
+// handle runs the command and replies to it. It only fails when the reply
+// cannot be written.
+func (s *socket) handle(command models.SocketCommandDTO) error {
+	var replies []models.SocketMessageDTO
+	var err error
+	switch command.Type {
+	case models.SocketCommandSubscribe:
+		replies, err = s.subscribe(command)
+	case models.SocketCommandUnsubscribe:
+		topics := []string{}
+		for _, postID := range command.PostIDs {
+			topics = append(topics, events.PostTopic(postID))
+		}
+		for _, userID := range command.UserIDs {
+			topics = append(topics, events.PresenceTopic(userID))
+		}
+		s.subscription.Remove(topics...)
+	case models.SocketCommandTyping:
+		err = s.typing(command.ChatID)
+	case models.SocketCommandAuth:
+		err = s.auth(command.Token)
+	case models.SocketCommandPing:
+		return s.send(models.SocketMessageDTO{Ref: command.Ref, Type: models.SocketMessagePong})
+	case "":
+		err = ErrInvalidMessage
+	default:
+		err = ErrUnknownCommand
+	}
+	if err != nil {
+		return s.send(models.SocketMessageDTO{Ref: command.Ref, Type: models.SocketMessageError, Error: err.Error()})
+	}
+	if err := s.send(models.SocketMessageDTO{Ref: command.Ref, Type: models.SocketMessageAck}); err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if err := s.send(reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscribe adds the counters of posts the user can see and the presence of
+// users they follow. Nothing is added unless every topic is allowed. It
+// returns the current presence of the users.
+func (s *socket) subscribe(command models.SocketCommandDTO) ([]models.SocketMessageDTO, error) {
+	// the user's own topic is always there and does not count
+	if s.subscription.Topics()-1+len(command.PostIDs)+len(command.UserIDs) > socketMaxTopics {
+		return nil, ErrTooManyTopics
+	}
+	topics := []string{}
+	for _, postID := range command.PostIDs {
+		if _, err := s.h.posts.GetPostByID(postID, s.userID); err != nil {
+			return nil, err
+		}
+		topics = append(topics, events.PostTopic(postID))
+	}
+	presence := []models.SocketMessageDTO{}
+	for _, userID := range command.UserIDs {
+		if err := s.canReach(userID); err != nil {
+			return nil, err
+		}
+		topic := events.PresenceTopic(userID)
+		topics = append(topics, topic)
+		data, err := json.Marshal(events.Presence{UserID: userID, Online: s.h.hub.Online(userID)})
+		if err != nil {
+			return nil, err
+		}
+		presence = append(presence, models.SocketMessageDTO{Type: events.TypePresence, Topic: topic, Data: data})
+	}
+	s.subscription.Add(topics...)
+	return presence, nil
+}
+
+// typing tells the other members of the chat that this user is typing in
+// it, at most once per socketTypingInterval.
+func (s *socket) typing(chatID uint64) error {
+	if time.Since(s.typedAt[chatID]) < socketTypingInterval {
+		return nil
+	}
+	chat, err := s.h.chats.GetChatByID(chatID, s.userID)
+	if err != nil {
+		return err
+	}
+	s.typedAt[chatID] = time.Now()
+	for _, member := range chat.Members {
+		if member.User.ID != s.userID {
+			s.h.hub.Publish(events.UserTopic(member.User.ID), events.TypeTyping, models.StreamTypingDTO{ChatID: chatID, UserID: s.userID})
+		}
+	}
+	return nil
+}
+
+// canReach allows presence only towards users the connected user follows.
+// Blocks in either direction end the follow.
+func (s *socket) canReach(userID uint64) error {
+	user, err := s.h.users.GetUserByID(userID, s.userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsFollowedByMe {
+		return ErrNotFollowed
+	}
+	return nil
+}
+
+// auth renews the connection with a fresh access token of the same user.
+func (s *socket) auth(token string) error {
+	claims, err := utils.GetToken(token, s.h.cfg.AccessTokenSecret)
+	if err != nil {
+		return err
+	}
+	if claims.Subject != strconv.FormatUint(s.userID, 10) {
+		return ErrInvalidToken
+	}
+	s.expiresAt = time.Time{}
+	if claims.ExpiresAt != nil {
+		s.expiresAt = claims.ExpiresAt.Time
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func dialSocket(t *testing.T, serverURL, accessToken string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(serverURL, "http") + "/v1.0/socket"
+	wsConfig, err := websocket.NewConfig(url, serverURL)
+	assert.NoError(t, err, "error creating socket config")
+	wsConfig.Header.Set("Authorization", "Bearer "+accessToken)
+	ws, err := websocket.DialConfig(wsConfig)
+	if err != nil {
+		t.Fatalf("Error dialing socket: %v", err)
+	}
+	return ws
+}
+
+func receiveSocket(t *testing.T, ws *websocket.Conn) models.SocketMessageDTO {
+	var message models.SocketMessageDTO
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.NoError(t, websocket.JSON.Receive(ws, &message), "error receiving message")
+	return message
+}
+
+func TestHandler_Socket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	posts := mocks.NewMockPostService(ctrl)
+	chats := mocks.NewMockChatService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	handler := NewHandler(users, nil, posts, nil, nil, nil, nil, chats, hub, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	ws := dialSocket(t, httpSrv.URL, accessToken)
+	defer ws.Close()
+
+	posts.EXPECT().GetPostByID(uint64(7), uint64(1)).Return(&models.ReadPostDTO{ID: 7}, nil)
+	users.EXPECT().GetUserByID(uint64(3), uint64(1)).Return(&models.ReadUserDTO{ID: 3, IsFollowedByMe: true}, nil)
+	assert.NoError(t, websocket.JSON.Send(ws, models.SocketCommandDTO{
+		Ref: "1", Type: models.SocketCommandSubscribe, PostIDs: []uint64{7}, UserIDs: []uint64{3},
+	}))
+	message := receiveSocket(t, ws)
+	assert.Equal(t, models.SocketMessageDTO{Ref: "1", Type: models.SocketMessageAck}, message, "Subscribe was not acknowledged")
+	message = receiveSocket(t, ws)
+	assert.Equal(t, events.TypePresence, message.Type, "Current presence should follow the ack")
+	assert.JSONEq(t, `{"user_id":3,"online":false}`, string(message.Data), "Presence mismatch")
+
+	hub.Publish(events.PostTopic(7), events.TypeCounters, models.StreamCountersDTO{PostID: 7, LikesCount: 2})
+	message = receiveSocket(t, ws)
+	assert.Equal(t, events.TypeCounters, message.Type, "Event type mismatch")
+	assert.Equal(t, events.PostTopic(7), message.Topic, "Event topic mismatch")
+	hub.Connect(3)
+	message = receiveSocket(t, ws)
+	assert.JSONEq(t, `{"user_id":3,"online":true}`, string(message.Data), "Presence mismatch")
+
+	chats.EXPECT().GetChatByID(uint64(5), uint64(1)).Return(&models.ReadChatDTO{ID: 5, Members: []models.ReadChatMemberDTO{
+		{User: models.ReadUserDTO{ID: 1}}, {User: models.ReadUserDTO{ID: 3}},
+	}}, nil)
+	typing := hub.Subscribe(events.UserTopic(3))
+	own := hub.Subscribe(events.UserTopic(1))
+	assert.NoError(t, websocket.JSON.Send(ws, models.SocketCommandDTO{Ref: "2", Type: models.SocketCommandTyping, ChatID: 5}))
+	assert.NoError(t, websocket.JSON.Send(ws, models.SocketCommandDTO{Ref: "3", Type: models.SocketCommandTyping, ChatID: 5}))
+	assert.Equal(t, models.SocketMessageAck, receiveSocket(t, ws).Type, "Typing was not acknowledged")
+	assert.Equal(t, models.SocketMessageAck, receiveSocket(t, ws).Type, "Typing was not acknowledged")
+	event := <-typing.Events()
+	assert.JSONEq(t, `{"chat_id":5,"user_id":1}`, string(event.Data), "Typing mismatch")
+	assert.Empty(t, typing.Events(), "Typing should be throttled")
+	assert.Empty(t, own.Events(), "Typing should not be sent back to the typist")
+
+	users.EXPECT().GetUserByID(uint64(4), uint64(1)).Return(&models.ReadUserDTO{ID: 4}, nil)
+	chats.EXPECT().GetChatByID(uint64(6), uint64(1)).Return(nil, repository.ErrNotFound)
+	testCases := []struct {
+		name    string
+		command models.SocketCommandDTO
+		err     error
+	}{
+		{name: "Presence of user not followed", command: models.SocketCommandDTO{Type: models.SocketCommandSubscribe, UserIDs: []uint64{4}}, err: ErrNotFollowed},
+		{name: "Typing in chat of others", command: models.SocketCommandDTO{Type: models.SocketCommandTyping, ChatID: 6}, err: repository.ErrNotFound},
+		{name: "Unknown command", command: models.SocketCommandDTO{Type: "dance"}, err: ErrUnknownCommand},
+		{name: "Empty command", command: models.SocketCommandDTO{}, err: ErrInvalidMessage},
+		{name: "Invalid token", command: models.SocketCommandDTO{Type: models.SocketCommandAuth, Token: "garbage"}, err: utils.ErrTokenInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.command.Ref = "e"
+			assert.NoError(t, websocket.JSON.Send(ws, tc.command))
+			message := receiveSocket(t, ws)
+			assert.Equal(t, models.SocketMessageDTO{Ref: "e", Type: models.SocketMessageError, Error: tc.err.Error()}, message)
+		})
+	}
+
+	assert.NoError(t, websocket.JSON.Send(ws, models.SocketCommandDTO{Ref: "4", Type: models.SocketCommandUnsubscribe, PostIDs: []uint64{7}}))
+	assert.Equal(t, models.SocketMessageAck, receiveSocket(t, ws).Type, "Unsubscribe was not acknowledged")
+	hub.Publish(events.PostTopic(7), events.TypeCounters, models.StreamCountersDTO{PostID: 7})
+	assert.NoError(t, websocket.JSON.Send(ws, models.SocketCommandDTO{Ref: "5", Type: models.SocketCommandPing}))
+	assert.Equal(t, models.SocketMessagePong, receiveSocket(t, ws).Type, "Unsubscribed events should not be sent")
+
+	assert.NoError(t, hub.Close(), "error closing hub")
+	message = receiveSocket(t, ws)
+	assert.Equal(t, ErrServerShutdown.Error(), message.Error, "Shutdown should be announced")
+	assert.NoError(t, handler.WaitSockets(context.Background()), "Socket did not close")
+}
+
+func TestHandler_SocketExpiry(t *testing.T) {
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	cfg.SocketAuthInterval = 100 * time.Millisecond
+	accessToken, err := utils.CreateToken(cfg.AccessTokenSecret, "1", time.Second)
+	assert.NoError(t, err, "error creating token")
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, nil, nil, hub, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	ws := dialSocket(t, httpSrv.URL, accessToken)
+	defer ws.Close()
+	message := receiveSocket(t, ws)
+	assert.Equal(t, utils.ErrTokenExpired.Error(), message.Error, "Expired token should close the socket")
+
+	var next models.SocketMessageDTO
+	assert.Error(t, websocket.JSON.Receive(ws, &next), "Socket should be closed")
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+// streamMaxPosts caps how many posts a stream can follow the counters of.
+const streamMaxPosts = 50
+
+var ErrStreamUnsupported = errors.New("streaming unsupported")
+var ErrTooManyPosts = errors.New("too many posts")
+
+// Stream serves the user's live events as server-sent events: new timeline
+// posts and notifications, plus counter changes of the posts listed in the
+// posts parameter. A client that reconnects with Last-Event-ID, or the
+// last_event_id parameter, first receives the events it missed.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok || h.hub == nil {
+		h.JSONError(w, http.StatusInternalServerError, ErrStreamUnsupported.Error())
+		return
+	}
+	claims, ok := utils.GetClaimsFromContext(r.Context())
+	if !ok {
+		h.JSONError(w, http.StatusUnauthorized, ErrInvalidToken.Error())
+		return
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		h.JSONError(w, http.StatusNotFound, ErrInvalidToken.Error())
+		return
+	}
+	topics := []string{events.UserTopic(userID)}
+	if posts := r.URL.Query().Get("posts"); posts != "" {
+		ids := strings.Split(posts, ",")
+		if len(ids) > streamMaxPosts {
+			h.JSONError(w, http.StatusBadRequest, ErrTooManyPosts.Error())
+			return
+		}
+		for _, rawID := range ids {
+			postID, err := strconv.ParseUint(rawID, 10, 64)
+			if err != nil {
+				h.JSONError(w, http.StatusBadRequest, ErrInvalidID.Error())
+				return
+			}
+			// only posts the user can see
+			if _, err := h.posts.GetPostByID(postID, userID); err != nil {
+				h.JSONError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			topics = append(topics, events.PostTopic(postID))
+		}
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			h.JSONError(w, http.StatusBadRequest, ErrInvalidID.Error())
+			return
+		}
+	}
+	// subscribe before replaying, so nothing published in between is lost
+	subscription := h.hub.Subscribe(topics...)
+	defer subscription.Close()
+	missed := []events.Event{}
+	if lastID > 0 {
+		if missed, err = h.hub.Since(topics, lastID); err != nil {
+			h.JSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+		lastID = event.ID
+	}
+	replayedID := lastID
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.cfg.StreamHeartbeat > 0 {
+		ticker := time.NewTicker(h.cfg.StreamHeartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			// already sent during the replay
+			if event.ID <= replayedID {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func readStreamEvent(t *testing.T, reader *bufio.Reader) string {
+	lines := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err, "error reading stream")
+		if line == "\n" || err != nil {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestHandler_Stream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, hub, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	hub.Publish(events.UserTopic(1), events.TypeNotification, models.StreamNotificationDTO{ID: 1, Type: "like"})
+	hub.Publish(events.UserTopic(1), events.TypePost, models.StreamPostDTO{PostID: 2, UserID: 3})
+	hub.Publish(events.UserTopic(2), events.TypePost, models.StreamPostDTO{PostID: 2, UserID: 3})
+
+	posts.EXPECT().GetPostByID(uint64(7), uint64(1)).Return(&models.ReadPostDTO{ID: 7}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpSrv.URL+"/v1.0/stream?posts=7&access_token="+accessToken, nil)
+	assert.NoError(t, err, "error creating request")
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err, "error making HTTP request")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Response code didn't match expected")
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"), "Content type mismatch")
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "id: 2\nevent: post\ndata: {\"post_id\":2,\"user_id\":3}\n", readStreamEvent(t, reader),
+		"Missed event should be replayed")
+	hub.Publish(events.PostTopic(7), events.TypeCounters, models.StreamCountersDTO{PostID: 7, LikesCount: 1})
+	assert.Equal(t,
+		"id: 4\nevent: counters\ndata: {\"post_id\":7,\"likes_count\":1,\"views_count\":0,\"replies_count\":0}\n",
+		readStreamEvent(t, reader), "Live event mismatch")
+}
+
+func TestHandler_StreamErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	posts := mocks.NewMockPostService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	handler := NewHandler(nil, nil, posts, nil, nil, nil, nil, nil, hub, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name   string
+		query  string
+		token  bool
+		status int
+	}{
+		{name: "No token", query: "", token: false, status: http.StatusUnauthorized},
+		{name: "Invalid post id", query: "posts=abc", token: true, status: http.StatusBadRequest},
+		{name: "Invisible post", query: "posts=5", token: true, status: http.StatusNotFound},
+		{name: "Invalid last event id", query: "last_event_id=abc", token: true, status: http.StatusBadRequest},
+	}
+	posts.EXPECT().GetPostByID(uint64(5), uint64(1)).Return(nil, repository.ErrNotFound)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, httpSrv.URL+"/v1.0/stream?"+tc.query, nil)
+			assert.NoError(t, err, "error creating request")
+			if tc.token {
+				req.Header.Set("Authorization", "Bearer "+accessToken)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err, "error making HTTP request")
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode, "Response code didn't match expected")
+		})
+	}
+}
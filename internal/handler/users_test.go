@@ -2,16 +2,20 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/shekshuev/gophertalk-backend/internal/config"
 	"github.com/shekshuev/gophertalk-backend/internal/mocks"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
 	"github.com/shekshuev/gophertalk-backend/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,7 +34,7 @@ func TestHandler_GetAllUsers(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(users, nil, nil, &cfg)
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -75,7 +79,7 @@ func TestHandler_GetAllUsers(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.serviceCalled {
-				users.EXPECT().GetAllUsers(gomock.Any(), gomock.Any()).Return(tc.responseDTOs, tc.serviceError)
+				users.EXPECT().GetAllUsers(gomock.Any(), gomock.Any(), gomock.Any()).Return(tc.responseDTOs, tc.serviceError)
 			}
 
 			req := resty.New().R()
@@ -103,7 +107,7 @@ func TestHandler_GetUserByID(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(users, nil, nil, &cfg)
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -144,7 +148,7 @@ func TestHandler_GetUserByID(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.serviceCalled {
-				users.EXPECT().GetUserByID(gomock.Any()).Return(tc.responseDTO, tc.serviceError)
+				users.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Return(tc.responseDTO, tc.serviceError)
 			}
 			req := resty.New().R()
 			req.Header.Set("Authorization", "Bearer "+accessToken)
@@ -171,7 +175,7 @@ func TestHandler_DeleteUserByID(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(users, nil, nil, &cfg)
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -235,7 +239,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 		cfg.AccessTokenExpires,
 	)
 	assert.NoError(t, err, "error creating token")
-	handler := NewHandler(users, nil, nil, &cfg)
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
 	httpSrv := httptest.NewServer(handler.Router)
 	defer httpSrv.Close()
 
@@ -302,3 +306,628 @@ func TestHandler_UpdateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetAllUsers_Cursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	page := &models.UserPageDTO{
+		Items:      []models.ReadUserDTO{{ID: 1, UserName: "john"}},
+		NextCursor: "next",
+	}
+	users.EXPECT().GetUsersPage(uint64(1), nil, uint64(5)).Return(page, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/users?limit=5&cursor="
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	assert.Equal(t, `</v1.0/users?cursor=next&limit=5>; rel="next"`, resp.Header().Get("Link"), "Link header mismatch")
+	var body models.UserPageDTO
+	err = json.Unmarshal(resp.Body(), &body)
+	assert.NoError(t, err, "error unmarshalling response")
+	assert.Equal(t, *page, body, "Page mismatch")
+}
+
+func TestHandler_SearchUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		query        string
+		readDTOs     []models.ReadUserDTO
+		expectedCode int
+	}{
+		{
+			name:         "Success search",
+			query:        "@joh",
+			readDTOs:     []models.ReadUserDTO{{ID: 1, UserName: "john"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Empty query",
+			query:        "@",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.readDTOs != nil {
+				users.EXPECT().SearchUsers(uint64(1), "joh", uint64(10), uint64(0)).Return(tc.readDTOs, nil)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodGet
+			req.URL = httpSrv.URL + "/v1.0/users/search"
+			req.SetQueryParam("q", tc.query)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+			if tc.readDTOs != nil {
+				var body []models.ReadUserDTO
+				err = json.Unmarshal(resp.Body(), &body)
+				assert.NoError(t, err, "error unmarshalling response")
+				assert.Equal(t, tc.readDTOs, body, "Users mismatch")
+			}
+		})
+	}
+}
+
+func TestHandler_GetUserByUserName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	users.EXPECT().GetUserByUserName("john", uint64(1)).Return(&models.ReadUserDTO{ID: 1, UserName: "john"}, nil)
+	users.EXPECT().GetUserByUserName("ghost", uint64(1)).Return(nil, repository.ErrNotFound)
+	for name, code := range map[string]int{"john": http.StatusOK, "ghost": http.StatusNotFound} {
+		req := resty.New().R()
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Method = http.MethodGet
+		req.URL = httpSrv.URL + "/v1.0/users/by-username/" + name
+		resp, err := req.Send()
+		assert.NoError(t, err, "error making HTTP request")
+		assert.Equal(t, code, resp.StatusCode(), "Response code didn't match expected")
+	}
+}
+
+func TestHandler_FollowUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		id           uint64
+		pending      bool
+		serviceError error
+		expectedCode int
+	}{
+		{
+			name:         "Success follow",
+			method:       http.MethodPost,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Follow yourself",
+			method:       http.MethodPost,
+			id:           1,
+			serviceError: service.ErrFollowSelf,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Follow missing user",
+			method:       http.MethodPost,
+			id:           3,
+			serviceError: repository.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Follow blocked user",
+			method:       http.MethodPost,
+			id:           4,
+			serviceError: repository.ErrBlocked,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Follow protected user",
+			method:       http.MethodPost,
+			id:           5,
+			pending:      true,
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "Success unfollow",
+			method:       http.MethodDelete,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				users.EXPECT().FollowUser(uint64(1), tc.id).Return(tc.pending, tc.serviceError)
+			} else {
+				users.EXPECT().UnfollowUser(uint64(1), tc.id).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = fmt.Sprintf("%s/v1.0/users/%d/follow", httpSrv.URL, tc.id)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_BlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		id           uint64
+		serviceError error
+		expectedCode int
+	}{
+		{
+			name:         "Success block",
+			method:       http.MethodPost,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Block yourself",
+			method:       http.MethodPost,
+			id:           1,
+			serviceError: service.ErrBlockSelf,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Block missing user",
+			method:       http.MethodPost,
+			id:           3,
+			serviceError: repository.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Success unblock",
+			method:       http.MethodDelete,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				users.EXPECT().BlockUser(uint64(1), tc.id).Return(tc.serviceError)
+			} else {
+				users.EXPECT().UnblockUser(uint64(1), tc.id).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = fmt.Sprintf("%s/v1.0/users/%d/block", httpSrv.URL, tc.id)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_GetBlockedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	now := time.Now()
+	blocked := []models.ReadUserDTO{{ID: 2, UserName: "jane", BlockedAt: &now}}
+	users.EXPECT().GetBlockedUsers(uint64(1), uint64(10), uint64(0)).Return(blocked, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/users/blocks"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	var body []models.ReadUserDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &body), "error decoding response")
+	assert.Len(t, body, 1, "Users count mismatch")
+	assert.NotNil(t, body[0].BlockedAt, "Block time is missing")
+}
+
+func TestHandler_MuteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		id           uint64
+		serviceError error
+		expectedCode int
+	}{
+		{
+			name:         "Success mute",
+			method:       http.MethodPost,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Mute yourself",
+			method:       http.MethodPost,
+			id:           1,
+			serviceError: service.ErrMuteSelf,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Mute missing user",
+			method:       http.MethodPost,
+			id:           3,
+			serviceError: repository.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Success unmute",
+			method:       http.MethodDelete,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				users.EXPECT().MuteUser(uint64(1), tc.id).Return(tc.serviceError)
+			} else {
+				users.EXPECT().UnmuteUser(uint64(1), tc.id).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = fmt.Sprintf("%s/v1.0/users/%d/mute", httpSrv.URL, tc.id)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_MutedWords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		body         string
+		serviceError error
+		expectedCode int
+	}{
+		{
+			name:         "Success mute word",
+			body:         `{"word": "#spoilers"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Invalid word",
+			body:         `{"word": "?!"}`,
+			serviceError: service.ErrInvalidMutedWord,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Empty word",
+			body:         `{"word": ""}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var dto models.CreateMutedWordDTO
+			assert.NoError(t, json.Unmarshal([]byte(tc.body), &dto), "error decoding body")
+			if dto.Word != "" {
+				var readDTO *models.ReadMutedWordDTO
+				if tc.serviceError == nil {
+					readDTO = &models.ReadMutedWordDTO{ID: 1, Word: dto.Word}
+				}
+				users.EXPECT().CreateMutedWord(uint64(1), dto).Return(readDTO, tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/users/muted-words"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+
+	users.EXPECT().GetMutedWords(uint64(1)).Return([]models.ReadMutedWordDTO{{ID: 1, Word: "#spoilers"}}, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Get(httpSrv.URL + "/v1.0/users/muted-words")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+
+	users.EXPECT().DeleteMutedWord(uint64(1), uint64(1)).Return(nil)
+	users.EXPECT().DeleteMutedWord(uint64(2), uint64(1)).Return(repository.ErrNotFound)
+	resp, err = req.Delete(httpSrv.URL + "/v1.0/users/muted-words/1")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode(), "Response code didn't match expected")
+	resp, err = req.Delete(httpSrv.URL + "/v1.0/users/muted-words/2")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Response code didn't match expected")
+}
+
+func TestHandler_FollowRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		id           uint64
+		serviceError error
+		expectedCode int
+	}{
+		{
+			name:         "Success approve",
+			method:       http.MethodPost,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Approve missing request",
+			method:       http.MethodPost,
+			id:           3,
+			serviceError: repository.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Success reject",
+			method:       http.MethodDelete,
+			id:           2,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Reject missing request",
+			method:       http.MethodDelete,
+			id:           3,
+			serviceError: repository.ErrNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == http.MethodPost {
+				users.EXPECT().ApproveFollowRequest(uint64(1), tc.id).Return(tc.serviceError)
+			} else {
+				users.EXPECT().RejectFollowRequest(uint64(1), tc.id).Return(tc.serviceError)
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = tc.method
+			req.URL = fmt.Sprintf("%s/v1.0/users/follow-requests/%d", httpSrv.URL, tc.id)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+
+	now := time.Now()
+	users.EXPECT().GetFollowRequests(uint64(1), uint64(10), uint64(0)).
+		Return([]models.ReadUserDTO{{ID: 2, UserName: "jane", RequestedAt: &now}}, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Get(httpSrv.URL + "/v1.0/users/follow-requests")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	var body []models.ReadUserDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &body), "error decoding response")
+	assert.NotNil(t, body[0].RequestedAt, "Request time is missing")
+}
+
+func TestHandler_GetFollowers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	followers := []models.ReadUserDTO{{ID: 3, UserName: "jane", FollowersCount: 1, IsFollowedByMe: true}}
+	users.EXPECT().GetFollows(models.FilterFollowDTO{UserID: 2, ViewerID: 1, Limit: 5, Offset: 10}).Return(followers, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/users/2/followers?limit=5&offset=10"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	var body []models.ReadUserDTO
+	err = json.Unmarshal(resp.Body(), &body)
+	assert.NoError(t, err, "error unmarshalling response")
+	assert.Equal(t, followers, body, "Followers mismatch")
+
+	page := &models.UserPageDTO{Items: []models.ReadUserDTO{{ID: 4}}, NextCursor: "next"}
+	users.EXPECT().GetFollowsPage(models.FilterFollowDTO{UserID: 2, ViewerID: 1, Following: true, Limit: 10}).Return(page, nil)
+	req = resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/users/2/following?cursor="
+	resp, err = req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	assert.Equal(t, `</v1.0/users/2/following?cursor=next>; rel="next"`, resp.Header().Get("Link"), "Link header mismatch")
+}
+
+func TestHandler_GetRecommendedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(users, nil, nil, nil, nil, nil, nil, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	recommended := []models.ReadUserDTO{{ID: 3, UserName: "john", RecommendationReason: "follows"}}
+	users.EXPECT().GetRecommendedUsers(uint64(1), uint64(5), uint64(0)).Return(recommended, nil)
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Method = http.MethodGet
+	req.URL = httpSrv.URL + "/v1.0/recommendations/users?limit=5"
+	resp, err := req.Send()
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "Response code didn't match expected")
+	var body []models.ReadUserDTO
+	assert.NoError(t, json.Unmarshal(resp.Body(), &body), "error decoding response")
+	assert.Len(t, body, 1, "Users count mismatch")
+	assert.Equal(t, "follows", body[0].RecommendationReason, "Recommendation reason mismatch")
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/service"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, webhooks, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		serviceErr   error
+		serviceCall  bool
+	}{
+		{
+			name:         "Success create",
+			body:         `{"url": "https://example.com/hook", "events": ["post.created"]}`,
+			expectedCode: http.StatusCreated,
+			serviceCall:  true,
+		},
+		{
+			name:         "Unknown event",
+			body:         `{"url": "https://example.com/hook", "events": ["post.created"]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			serviceErr:   service.ErrInvalidWebhookEvent,
+			serviceCall:  true,
+		},
+		{
+			name:         "All users without admin rights",
+			body:         `{"url": "https://example.com/hook", "events": ["post.created"]}`,
+			expectedCode: http.StatusForbidden,
+			serviceErr:   service.ErrAdminOnly,
+			serviceCall:  true,
+		},
+		{
+			name:         "Invalid URL",
+			body:         `{"url": "example", "events": ["post.created"]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "No events",
+			body:         `{"url": "https://example.com/hook", "events": []}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCall {
+				dto := models.CreateWebhookDTO{UserID: 1, URL: "https://example.com/hook", Events: []string{"post.created"}}
+				if tc.serviceErr != nil {
+					webhooks.EXPECT().CreateWebhook(dto).Return(nil, tc.serviceErr)
+				} else {
+					webhooks.EXPECT().CreateWebhook(dto).Return(&models.ReadWebhookDTO{ID: 1, Secret: "secret"}, nil)
+				}
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPost
+			req.URL = httpSrv.URL + "/v1.0/webhooks"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_UpdateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, webhooks, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	active := true
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		serviceErr   error
+		serviceCall  bool
+	}{
+		{
+			name:         "Success reactivate",
+			body:         `{"active": true}`,
+			expectedCode: http.StatusOK,
+			serviceCall:  true,
+		},
+		{
+			name:         "Foreign webhook",
+			body:         `{"active": true}`,
+			expectedCode: http.StatusNotFound,
+			serviceErr:   repository.ErrNotFound,
+			serviceCall:  true,
+		},
+		{
+			name:         "Invalid URL",
+			body:         `{"url": "example"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.serviceCall {
+				call := webhooks.EXPECT().UpdateWebhook(uint64(2), uint64(1), models.UpdateWebhookDTO{Active: &active})
+				if tc.serviceErr != nil {
+					call.Return(nil, tc.serviceErr)
+				} else {
+					call.Return(&models.ReadWebhookDTO{ID: 2, Active: true}, nil)
+				}
+			}
+			req := resty.New().R()
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Method = http.MethodPut
+			req.URL = httpSrv.URL + "/v1.0/webhooks/2"
+			req.SetBody(tc.body)
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+			assert.Equal(t, tc.expectedCode, resp.StatusCode(), "Response code didn't match expected")
+		})
+	}
+}
+
+func TestHandler_RedeliverWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	accessTokenSecret := "test"
+	os.Setenv("ACCESS_TOKEN_SECRET", accessTokenSecret)
+	os.Setenv("ACCESS_TOKEN_EXPIRES", "1h")
+	cfg := config.GetConfig()
+	accessToken, err := utils.CreateToken(
+		cfg.AccessTokenSecret,
+		"1",
+		cfg.AccessTokenExpires,
+	)
+	assert.NoError(t, err, "error creating token")
+	handler := NewHandler(nil, nil, nil, nil, nil, nil, webhooks, nil, nil, &cfg)
+	httpSrv := httptest.NewServer(handler.Router)
+	defer httpSrv.Close()
+
+	webhooks.EXPECT().Redeliver(uint64(7), uint64(2), uint64(1)).
+		Return(&models.ReadWebhookDeliveryDTO{ID: 8, WebhookID: 2, Status: models.WebhookDeliveryPending}, nil)
+	webhooks.EXPECT().Redeliver(uint64(7), uint64(3), uint64(1)).Return(nil, repository.ErrNotFound)
+
+	req := resty.New().R()
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := req.Post(httpSrv.URL + "/v1.0/webhooks/2/deliveries/7/redeliver")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode(), "Response code didn't match expected")
+
+	resp, err = req.Post(httpSrv.URL + "/v1.0/webhooks/3/deliveries/7/redeliver")
+	assert.NoError(t, err, "error making HTTP request")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "Response code didn't match expected")
+}
@@ -0,0 +1,96 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Blurhash computes a BlurHash (https://blurha.sh) placeholder for img with
+// xComponents×yComponents DCT components, each between 1 and 9.
+func Blurhash(img image.Image, xComponents, yComponents int) string {
+	// the hash only needs a rough picture, so work on a small copy
+	w, h := Fit(img.Bounds().Dx(), img.Bounds().Dy(), 64, 64)
+	src := Resize(img, w, h)
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+			var r, g, b float64
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					basis := normalisation *
+						math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(h))
+					o := src.PixOffset(x, y)
+					r += basis * sRGBToLinear(src.Pix[o])
+					g += basis * sRGBToLinear(src.Pix[o+1])
+					b += basis * sRGBToLinear(src.Pix[o+2])
+				}
+			}
+			scale := 1 / float64(w*h)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encode83((xComponents-1)+(yComponents-1)*9, 1))
+
+	maximumValue := 1.0
+	if len(factors) > 1 {
+		actualMax := 0.0
+		for _, f := range factors[1:] {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maximumValue = float64(quantisedMax+1) / 166
+		hash.WriteString(encode83(quantisedMax, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	dc := factors[0]
+	hash.WriteString(encode83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4))
+	for _, f := range factors[1:] {
+		quant := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+		}
+		hash.WriteString(encode83(quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2))
+	}
+	return hash.String()
+}
+
+func encode83(value, length int) string {
+	out := make([]byte, length)
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		out[i-1] = base83Chars[digit]
+	}
+	return string(out)
+}
+
+func sRGBToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
@@ -0,0 +1,37 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlurhash(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	hash := Blurhash(img, 4, 3)
+	// 1 size flag + 1 max AC + 4 DC + 2 per AC component
+	assert.Len(t, hash, 6+2*(4*3-1), "Hash length mismatch")
+	assert.Equal(t, "L", hash[:1], "Size flag mismatch")
+	// the average color of a solid image is the image color
+	assert.Equal(t, encode83(0xFFFFFF, 4), hash[2:6], "DC component mismatch")
+
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 16; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{A: 255})
+		}
+	}
+	assert.NotEqual(t, hash, Blurhash(img, 4, 3), "Different images should have different hashes")
+}
+
+func TestEncode83(t *testing.T) {
+	assert.Equal(t, "00", encode83(0, 2))
+	assert.Equal(t, "0~", encode83(82, 2))
+	assert.Equal(t, "10", encode83(83, 2))
+}
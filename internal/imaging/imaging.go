@@ -0,0 +1,44 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	FormatJPEG = "image/jpeg"
+	FormatPNG  = "image/png"
+	FormatWebP = "image/webp"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatWebP:
+		err = EncodeWebP(&buf, img)
+	default:
+		err = ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,115 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit returns the size of a w×h image scaled down to fit into maxW×maxH
+// while keeping the aspect ratio. Images that already fit are left as is.
+func Fit(w, h, maxW, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return w, h
+	}
+	if w*maxH > h*maxW {
+		return maxW, max(1, h*maxW/w)
+	}
+	return max(1, w*maxH/h), maxH
+}
+
+// Resize scales src to w×h using area averaging, which gives good quality
+// for the downscaling we do for thumbnails and responsive variants.
+func Resize(src image.Image, w, h int) *image.NRGBA {
+	in := toNRGBA(src)
+	sw, sh := in.Rect.Dx(), in.Rect.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xScale := float64(sw) / float64(w)
+	yScale := float64(sh) / float64(h)
+	for dy := 0; dy < h; dy++ {
+		y0 := float64(dy) * yScale
+		y1 := y0 + yScale
+		for dx := 0; dx < w; dx++ {
+			x0 := float64(dx) * xScale
+			x1 := x0 + xScale
+			var r, g, b, a, total float64
+			for sy := int(y0); sy < sh && float64(sy) < y1; sy++ {
+				wy := overlap(float64(sy), y0, y1)
+				for sx := int(x0); sx < sw && float64(sx) < x1; sx++ {
+					wx := overlap(float64(sx), x0, x1)
+					weight := wx * wy
+					i := in.PixOffset(sx+in.Rect.Min.X, sy+in.Rect.Min.Y)
+					pa := float64(in.Pix[i+3])
+					// premultiply so transparent pixels do not bleed color
+					r += float64(in.Pix[i]) * pa * weight
+					g += float64(in.Pix[i+1]) * pa * weight
+					b += float64(in.Pix[i+2]) * pa * weight
+					a += pa * weight
+					total += weight
+				}
+			}
+			o := dst.PixOffset(dx, dy)
+			if a > 0 {
+				dst.Pix[o] = clamp8(r / a)
+				dst.Pix[o+1] = clamp8(g / a)
+				dst.Pix[o+2] = clamp8(b / a)
+			}
+			dst.Pix[o+3] = clamp8(a / total)
+		}
+	}
+	return dst
+}
+
+// Thumbnail crops the center square of src and scales it to size×size.
+func Thumbnail(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	square := image.NewNRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Rect, src, image.Pt(x0, y0), draw.Src)
+	return Resize(square, min(size, side), min(size, side))
+}
+
+func HasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xFFFF {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toNRGBA(src image.Image) *image.NRGBA {
+	if n, ok := src.(*image.NRGBA); ok {
+		return n
+	}
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(x-b.Min.X, y-b.Min.Y, color.NRGBAModel.Convert(src.At(x, y)))
+		}
+	}
+	return dst
+}
+
+func overlap(pixel, from, to float64) float64 {
+	return min(pixel+1, to) - max(pixel, from)
+}
+
+func clamp8(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,66 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFit(t *testing.T) {
+	testCases := []struct {
+		name       string
+		w, h       int
+		maxW, maxH int
+		outW, outH int
+	}{
+		{name: "Already fits", w: 100, h: 50, maxW: 320, maxH: 320, outW: 100, outH: 50},
+		{name: "Landscape", w: 1920, h: 1080, maxW: 640, maxH: 640, outW: 640, outH: 360},
+		{name: "Portrait", w: 1080, h: 1920, maxW: 640, maxH: 640, outW: 360, outH: 640},
+		{name: "Very thin", w: 5000, h: 1, maxW: 320, maxH: 320, outW: 320, outH: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, h := Fit(tc.w, tc.h, tc.maxW, tc.maxH)
+			assert.Equal(t, tc.outW, w, "Width mismatch")
+			assert.Equal(t, tc.outH, h, "Height mismatch")
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		src.SetNRGBA(x, 0, color.NRGBA{R: 255, A: 255})
+		src.SetNRGBA(x, 1, color.NRGBA{B: 255, A: 255})
+	}
+	dst := Resize(src, 2, 1)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), dst.Rect, "Size mismatch")
+	assert.Equal(t, color.NRGBA{R: 128, B: 128, A: 255}, dst.NRGBAAt(0, 0), "Pixels should be averaged")
+}
+
+func TestResize_Transparent(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	src.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 0})
+	dst := Resize(src, 1, 1)
+	assert.Equal(t, color.NRGBA{R: 255, A: 128}, dst.NRGBAAt(0, 0), "Transparent pixels should not bleed color")
+}
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	assert.Equal(t, image.Rect(0, 0, 150, 150), Thumbnail(src, 150).Rect, "Thumbnail size mismatch")
+	small := image.NewNRGBA(image.Rect(0, 0, 100, 60))
+	assert.Equal(t, image.Rect(0, 0, 60, 60), Thumbnail(small, 150).Rect, "Thumbnail must not upscale")
+}
+
+func TestHasAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	assert.False(t, HasAlpha(img), "Opaque image reported as transparent")
+	img.SetNRGBA(1, 1, color.NRGBA{A: 10})
+	assert.True(t, HasAlpha(img), "Transparent image reported as opaque")
+}
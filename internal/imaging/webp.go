@@ -0,0 +1,282 @@
+package imaging
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"sort"
+)
+
+// EncodeWebP writes img as a lossless (VP8L) WebP. The encoder applies the
+// subtract-green transform and per-channel prefix codes built from the
+// image histograms; it does not use backward references or a color cache.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	src := toNRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return errors.New("webp: invalid image size")
+	}
+
+	n := width * height
+	green := make([]byte, n)
+	red := make([]byte, n)
+	blue := make([]byte, n)
+	alpha := make([]byte, n)
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := src.PixOffset(x, y)
+			p := y*width + x
+			green[p] = src.Pix[i+1]
+			red[p] = src.Pix[i] - src.Pix[i+1]
+			blue[p] = src.Pix[i+2] - src.Pix[i+1]
+			alpha[p] = src.Pix[i+3]
+			if alpha[p] != 0xFF {
+				hasAlpha = true
+			}
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	bw.write(1, 1) // transform present
+	bw.write(2, 2) // subtract green
+	bw.write(0, 1) // no more transforms
+
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // no meta prefix codes
+
+	channels := [][]byte{green, red, blue, alpha}
+	alphabetSizes := []int{256 + 24, 256, 256, 256}
+	codes := make([][]prefixCode, len(channels))
+	for c, data := range channels {
+		counts := make([]int, alphabetSizes[c])
+		for _, v := range data {
+			counts[v]++
+		}
+		codes[c] = writePrefixCode(bw, counts, 15)
+	}
+	writePrefixCode(bw, make([]int, 40), 15) // unused distance code
+
+	for p := 0; p < n; p++ {
+		for c, data := range channels {
+			code := codes[c][data[p]]
+			bw.write(code.bits, code.length)
+		}
+	}
+
+	payload := bw.bytes()
+	var buf bytes.Buffer
+	chunkSize := len(payload)
+	padding := chunkSize % 2
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+chunkSize+padding))
+	buf.WriteString("WEBPVP8L")
+	binary.Write(&buf, binary.LittleEndian, uint32(chunkSize))
+	buf.Write(payload)
+	if padding == 1 {
+		buf.WriteByte(0)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type prefixCode struct {
+	bits   uint32 // already bit-reversed, ready to be written LSB first
+	length uint
+}
+
+var codeLengthCodeOrder = []int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// writePrefixCode writes the code for the given symbol counts and returns
+// the code table.
+func writePrefixCode(bw *bitWriter, counts []int, maxLength int) []prefixCode {
+	used := make([]int, 0, 2)
+	for s, c := range counts {
+		if c > 0 {
+			used = append(used, s)
+		}
+	}
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		// simple code: one or two 8-bit symbols
+		symbols := used
+		if len(symbols) == 0 {
+			symbols = []int{0}
+		}
+		bw.write(1, 1)
+		bw.write(uint32(len(symbols)-1), 1)
+		bw.write(1, 1) // first symbol uses 8 bits
+		bw.write(uint32(symbols[0]), 8)
+		lengths := make([]int, len(counts))
+		if len(symbols) == 2 {
+			bw.write(uint32(symbols[1]), 8)
+			lengths[symbols[0]], lengths[symbols[1]] = 1, 1
+		}
+		return canonicalCodes(lengths)
+	}
+
+	lengths := huffmanLengths(counts, maxLength)
+	lengthCounts := make([]int, 19)
+	for _, l := range lengths {
+		lengthCounts[l]++
+	}
+	lengthCodeLengths := huffmanLengths(lengthCounts, 7)
+	numCodeLengths := len(codeLengthCodeOrder)
+	for numCodeLengths > 4 && lengthCodeLengths[codeLengthCodeOrder[numCodeLengths-1]] == 0 {
+		numCodeLengths--
+	}
+	bw.write(0, 1)
+	bw.write(uint32(numCodeLengths-4), 4)
+	for i := 0; i < numCodeLengths; i++ {
+		bw.write(uint32(lengthCodeLengths[codeLengthCodeOrder[i]]), 3)
+	}
+	bw.write(0, 1) // max_symbol is the alphabet size
+	lengthCodes := canonicalCodes(lengthCodeLengths)
+	for _, l := range lengths {
+		bw.write(lengthCodes[l].bits, lengthCodes[l].length)
+	}
+	return canonicalCodes(lengths)
+}
+
+// huffmanLengths builds code lengths limited to maxLength. A single used
+// symbol gets length 1, which decoders treat as a zero-bit code.
+func huffmanLengths(counts []int, maxLength int) []int {
+	lengths := make([]int, len(counts))
+	weights := append([]int(nil), counts...)
+	for {
+		h := &nodeHeap{}
+		for s, w := range weights {
+			if w > 0 {
+				*h = append(*h, &node{weight: w, symbol: s})
+			}
+		}
+		if h.Len() == 0 {
+			return lengths
+		}
+		if h.Len() == 1 {
+			lengths[(*h)[0].symbol] = 1
+			return lengths
+		}
+		heap.Init(h)
+		for h.Len() > 1 {
+			a := heap.Pop(h).(*node)
+			b := heap.Pop(h).(*node)
+			heap.Push(h, &node{weight: a.weight + b.weight, left: a, right: b, symbol: min(a.symbol, b.symbol)})
+		}
+		tooLong := false
+		var walk func(n *node, depth int)
+		walk = func(n *node, depth int) {
+			if n.left == nil {
+				lengths[n.symbol] = depth
+				tooLong = tooLong || depth > maxLength
+				return
+			}
+			walk(n.left, depth+1)
+			walk(n.right, depth+1)
+		}
+		walk((*h)[0], 0)
+		if !tooLong {
+			return lengths
+		}
+		// flatten the distribution and try again
+		for s, w := range weights {
+			if w > 0 {
+				weights[s] = w/2 + 1
+			}
+		}
+	}
+}
+
+// canonicalCodes assigns canonical prefix codes, as in DEFLATE.
+func canonicalCodes(lengths []int) []prefixCode {
+	codes := make([]prefixCode, len(lengths))
+	symbols := make([]int, 0, len(lengths))
+	for s, l := range lengths {
+		if l > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 1 {
+		return codes // zero-bit code
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return lengths[symbols[i]] < lengths[symbols[j]]
+	})
+	code, prevLength := uint32(0), 0
+	for _, s := range symbols {
+		l := lengths[s]
+		code <<= uint(l - prevLength)
+		prevLength = l
+		codes[s] = prefixCode{bits: reverseBits(code, uint(l)), length: uint(l)}
+		code++
+	}
+	return codes
+}
+
+func reverseBits(v uint32, n uint) uint32 {
+	var r uint32
+	for i := uint(0); i < n; i++ {
+		r = r<<1 | (v>>i)&1
+	}
+	return r
+}
+
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (b *bitWriter) write(v uint32, n uint) {
+	b.acc |= uint64(v&(1<<n-1)) << b.nbits
+	b.nbits += n
+	for b.nbits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nbits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nbits = 0, 0
+	}
+	return b.buf
+}
+
+type node struct {
+	weight      int
+	symbol      int
+	left, right *node
+}
+
+type nodeHeap []*node
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].weight == h[j].weight {
+		return h[i].symbol < h[j].symbol
+	}
+	return h[i].weight < h[j].weight
+}
+func (h nodeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x interface{}) { *h = append(*h, x.(*node)) }
+func (h *nodeHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
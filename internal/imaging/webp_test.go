@@ -0,0 +1,171 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bitReader and decodeLosslessWebP implement the subset of the VP8L format
+// produced by EncodeWebP, so that tests can check a full round trip.
+type bitReader struct {
+	data []byte
+	pos  uint
+}
+
+func (r *bitReader) read(n uint) uint32 {
+	var v uint32
+	for i := uint(0); i < n; i++ {
+		bit := (r.data[r.pos/8] >> (r.pos % 8)) & 1
+		v |= uint32(bit) << i
+		r.pos++
+	}
+	return v
+}
+
+type testCode struct {
+	lengths []int
+}
+
+func (c testCode) readSymbol(r *bitReader) int {
+	used := []int{}
+	for s, l := range c.lengths {
+		if l > 0 {
+			used = append(used, s)
+		}
+	}
+	if len(used) == 1 {
+		return used[0]
+	}
+	codes := canonicalCodes(c.lengths)
+	var code uint32
+	for length := uint(1); length <= 15; length++ {
+		code |= r.read(1) << (length - 1)
+		for _, s := range used {
+			if codes[s].length == length && codes[s].bits == code {
+				return s
+			}
+		}
+	}
+	panic("invalid prefix code")
+}
+
+func readTestCode(t *testing.T, r *bitReader, alphabetSize int) testCode {
+	lengths := make([]int, alphabetSize)
+	if r.read(1) == 1 {
+		numSymbols := r.read(1) + 1
+		firstBits := uint(1)
+		if r.read(1) == 1 {
+			firstBits = 8
+		}
+		s0 := r.read(firstBits)
+		lengths[s0] = 1
+		if numSymbols == 2 {
+			lengths[r.read(8)] = 1
+		}
+		return testCode{lengths: lengths}
+	}
+	numCodeLengths := int(r.read(4)) + 4
+	lengthCodeLengths := make([]int, 19)
+	for i := 0; i < numCodeLengths; i++ {
+		lengthCodeLengths[codeLengthCodeOrder[i]] = int(r.read(3))
+	}
+	assert.Equal(t, uint32(0), r.read(1), "max_symbol is not used by the encoder")
+	lengthCode := testCode{lengths: lengthCodeLengths}
+	for i := range lengths {
+		l := lengthCode.readSymbol(r)
+		assert.Less(t, l, 16, "repeat codes are not used by the encoder")
+		lengths[i] = l
+	}
+	return testCode{lengths: lengths}
+}
+
+func decodeLosslessWebP(t *testing.T, data []byte) *image.NRGBA {
+	assert.Equal(t, "RIFF", string(data[:4]))
+	assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+	assert.Equal(t, "WEBPVP8L", string(data[8:16]))
+	size := binary.LittleEndian.Uint32(data[16:20])
+	r := &bitReader{data: data[20 : 20+size]}
+	assert.Equal(t, uint32(0x2f), r.read(8), "signature")
+	width := int(r.read(14)) + 1
+	height := int(r.read(14)) + 1
+	r.read(1)
+	assert.Equal(t, uint32(0), r.read(3), "version")
+	assert.Equal(t, uint32(1), r.read(1), "transform present")
+	assert.Equal(t, uint32(2), r.read(2), "subtract green transform")
+	assert.Equal(t, uint32(0), r.read(1), "single transform")
+	assert.Equal(t, uint32(0), r.read(1), "no color cache")
+	assert.Equal(t, uint32(0), r.read(1), "no meta prefix codes")
+	green := readTestCode(t, r, 280)
+	red := readTestCode(t, r, 256)
+	blue := readTestCode(t, r, 256)
+	alpha := readTestCode(t, r, 256)
+	readTestCode(t, r, 40)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			g := green.readSymbol(r)
+			assert.Less(t, g, 256, "backward references are not used by the encoder")
+			rr := red.readSymbol(r)
+			b := blue.readSymbol(r)
+			a := alpha.readSymbol(r)
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(rr + g),
+				G: uint8(g),
+				B: uint8(b + g),
+				A: uint8(a),
+			})
+		}
+	}
+	return img
+}
+
+func TestEncodeWebP(t *testing.T) {
+	gradient := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	for y := 0; y < 23; y++ {
+		for x := 0; x < 37; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 11), B: uint8(x * y), A: uint8(255 - x)})
+		}
+	}
+	solid := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range solid.Pix {
+		solid.Pix[i] = 200
+	}
+	twoColors := image.NewNRGBA(image.Rect(0, 0, 8, 1))
+	for x := 0; x < 8; x++ {
+		twoColors.SetNRGBA(x, 0, color.NRGBA{R: uint8(x % 2 * 255), A: 255})
+	}
+
+	for name, img := range map[string]*image.NRGBA{"gradient": gradient, "solid": solid, "two colors": twoColors} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := EncodeWebP(&buf, img)
+			assert.NoError(t, err, "Error encoding WebP")
+			decoded := decodeLosslessWebP(t, buf.Bytes())
+			assert.Equal(t, img.Rect, decoded.Rect, "Size mismatch")
+			assert.Equal(t, img.Pix, decoded.Pix, "Pixels mismatch")
+		})
+	}
+}
+
+func TestHuffmanLengths(t *testing.T) {
+	// Fibonacci weights produce a degenerate tree deeper than the limit.
+	counts := make([]int, 30)
+	a, b := 1, 1
+	for i := range counts {
+		counts[i] = a
+		a, b = b, a+b
+	}
+	lengths := huffmanLengths(counts, 15)
+	kraft := 0.0
+	for _, l := range lengths {
+		assert.LessOrEqual(t, l, 15, "Length limit exceeded")
+		kraft += 1 / float64(int(1)<<l)
+	}
+	assert.Equal(t, 1.0, kraft, "Code must be complete")
+}
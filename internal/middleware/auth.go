@@ -55,3 +55,33 @@ func RequestAuthSameID(secret string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// QueryToken accepts the access token in the access_token query parameter
+// when the request has no Authorization header. Browsers cannot set headers
+// on an EventSource, so streams need it.
+func QueryToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RedactQueryToken masks the access_token query parameter in RequestURI, which
+// the request logger prints, so live tokens do not end up in access logs. It
+// has to run before the logger. Routing and QueryToken read r.URL, which is
+// left as is.
+func RedactQueryToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Has("access_token") {
+			query.Set("access_token", "REDACTED")
+			redacted := *r.URL
+			redacted.RawQuery = query.Encode()
+			r = r.WithContext(r.Context())
+			r.RequestURI = redacted.RequestURI()
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,11 @@
+create table if not exists post_mentions (
+    post_id bigint not null,
+    user_id bigint not null,
+    start_offset int not null,
+    length int not null,
+    constraint pk__post_mentions primary key (post_id, start_offset),
+    constraint fk__post_mentions__post_id foreign key (post_id) references posts(id),
+    constraint fk__post_mentions__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__post_mentions__user_id on post_mentions(user_id, post_id);
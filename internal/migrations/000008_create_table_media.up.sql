@@ -0,0 +1,19 @@
+create table if not exists media (
+    id bigserial,
+    user_id bigint not null,
+    post_id bigint,
+    position smallint,
+    storage_key varchar(255) not null,
+    url varchar(1024) not null,
+    mime_type varchar(50) not null,
+    size_bytes bigint not null,
+    width int not null,
+    height int not null,
+    alt_text varchar(1000) not null default '',
+    created_at timestamp not null default now(),
+    constraint pk__media primary key (id),
+    constraint fk__media__user_id foreign key (user_id) references users(id),
+    constraint fk__media__post_id foreign key (post_id) references posts(id)
+);
+
+create index idx__media__post_id on media(post_id) where (post_id is not null);
@@ -0,0 +1,5 @@
+alter table users drop constraint if exists fk__users__avatar_media_id;
+alter table users drop column if exists avatar_media_id;
+
+alter table media drop column if exists variants;
+alter table media drop column if exists blurhash;
@@ -0,0 +1,5 @@
+alter table media add column if not exists blurhash varchar(64) not null default '';
+alter table media add column if not exists variants jsonb not null default '[]';
+
+alter table users add column if not exists avatar_media_id bigint;
+alter table users add constraint fk__users__avatar_media_id foreign key (avatar_media_id) references media(id);
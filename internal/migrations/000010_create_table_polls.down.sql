@@ -0,0 +1,4 @@
+drop table if exists poll_votes;
+drop table if exists poll_voters;
+drop table if exists poll_options;
+drop table if exists polls;
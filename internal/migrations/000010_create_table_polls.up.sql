@@ -0,0 +1,43 @@
+create table if not exists polls (
+    id bigserial,
+    post_id bigint not null,
+    multiple boolean not null default false,
+    closes_at timestamptz not null,
+    voters_count int not null default 0,
+    created_at timestamp not null default now(),
+    constraint pk__polls primary key (id),
+    constraint uq__polls__post_id unique (post_id),
+    constraint fk__polls__post_id foreign key (post_id) references posts(id)
+);
+
+create table if not exists poll_options (
+    id bigserial,
+    poll_id bigint not null,
+    position smallint not null,
+    text varchar(100) not null,
+    votes_count int not null default 0,
+    constraint pk__poll_options primary key (id),
+    constraint uq__poll_options__poll_id_position unique (poll_id, position),
+    constraint fk__poll_options__poll_id foreign key (poll_id) references polls(id)
+);
+
+create table if not exists poll_voters (
+    poll_id bigint not null,
+    user_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__poll_voters primary key (poll_id, user_id),
+    constraint fk__poll_voters__poll_id foreign key (poll_id) references polls(id),
+    constraint fk__poll_voters__user_id foreign key (user_id) references users(id)
+);
+
+create table if not exists poll_votes (
+    poll_id bigint not null,
+    option_id bigint not null,
+    user_id bigint not null,
+    constraint pk__poll_votes primary key (option_id, user_id),
+    constraint fk__poll_votes__poll_id foreign key (poll_id) references polls(id),
+    constraint fk__poll_votes__option_id foreign key (option_id) references poll_options(id),
+    constraint fk__poll_votes__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__poll_votes__user_id on poll_votes(user_id, poll_id);
@@ -0,0 +1,7 @@
+drop index if exists idx__posts__user_id_state;
+drop index if exists idx__posts__publish_at;
+
+alter table posts
+drop constraint if exists chk__posts__state,
+drop column if exists publish_at,
+drop column if exists state;
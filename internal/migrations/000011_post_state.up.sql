@@ -0,0 +1,7 @@
+alter table posts
+add column state varchar(16) not null default 'published',
+add column publish_at timestamptz,
+add constraint chk__posts__state check (state in ('draft', 'scheduled', 'published'));
+
+create index idx__posts__publish_at on posts(publish_at) where (state = 'scheduled');
+create index idx__posts__user_id_state on posts(user_id, state) where (state <> 'published');
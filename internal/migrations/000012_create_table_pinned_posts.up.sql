@@ -0,0 +1,9 @@
+create table if not exists pinned_posts (
+    user_id bigint not null,
+    post_id bigint not null,
+    pinned_at timestamp not null default now(),
+    constraint pk__pinned_posts primary key (user_id, post_id),
+    constraint uq__pinned_posts__post_id unique (post_id),
+    constraint fk__pinned_posts__user_id foreign key (user_id) references users(id),
+    constraint fk__pinned_posts__post_id foreign key (post_id) references posts(id)
+);
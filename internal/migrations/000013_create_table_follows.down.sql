@@ -0,0 +1,2 @@
+drop index if exists idx__follows__following_id;
+drop table if exists follows;
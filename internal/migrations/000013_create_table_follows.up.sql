@@ -0,0 +1,10 @@
+create table if not exists follows (
+    follower_id bigint not null,
+    following_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__follows primary key (follower_id, following_id),
+    constraint fk__follows__follower_id foreign key (follower_id) references users(id),
+    constraint fk__follows__following_id foreign key (following_id) references users(id)
+);
+
+create index idx__follows__following_id on follows(following_id, follower_id);
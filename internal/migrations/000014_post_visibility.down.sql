@@ -0,0 +1,3 @@
+alter table posts
+drop constraint if exists chk__posts__visibility,
+drop column if exists visibility;
@@ -0,0 +1,3 @@
+alter table posts
+add column visibility varchar(16) not null default 'public',
+add constraint chk__posts__visibility check (visibility in ('public', 'followers', 'mentioned'));
@@ -0,0 +1,3 @@
+alter table posts
+drop constraint if exists chk__posts__reply_policy,
+drop column if exists reply_policy;
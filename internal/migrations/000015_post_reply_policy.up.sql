@@ -0,0 +1,3 @@
+alter table posts
+add column reply_policy varchar(16) not null default 'everyone',
+add constraint chk__posts__reply_policy check (reply_policy in ('everyone', 'following', 'mentioned'));
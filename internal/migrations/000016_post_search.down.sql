@@ -0,0 +1,5 @@
+drop index if exists idx__posts__search_vector;
+
+alter table posts
+drop column if exists search_vector,
+drop column if exists search_config;
@@ -0,0 +1,5 @@
+alter table posts
+add column search_config regconfig not null default 'simple',
+add column search_vector tsvector generated always as (to_tsvector(search_config, text)) stored;
+
+create index idx__posts__search_vector on posts using gin(search_vector);
@@ -0,0 +1,3 @@
+drop index if exists idx__users__last_name_trgm;
+drop index if exists idx__users__first_name_trgm;
+drop index if exists idx__users__user_name_trgm;
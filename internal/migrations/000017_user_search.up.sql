@@ -0,0 +1,5 @@
+create extension if not exists pg_trgm;
+
+create index idx__users__user_name_trgm on users using gin(user_name gin_trgm_ops);
+create index idx__users__first_name_trgm on users using gin(first_name gin_trgm_ops);
+create index idx__users__last_name_trgm on users using gin(last_name gin_trgm_ops);
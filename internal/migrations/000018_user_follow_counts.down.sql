@@ -0,0 +1,6 @@
+alter table follows
+drop constraint if exists ck__follows__not_self;
+
+alter table users
+drop column if exists following_count,
+drop column if exists followers_count;
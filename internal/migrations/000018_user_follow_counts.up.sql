@@ -0,0 +1,10 @@
+alter table users
+add column followers_count int not null default 0,
+add column following_count int not null default 0;
+
+alter table follows
+add constraint ck__follows__not_self check (follower_id <> following_id);
+
+update users set
+    followers_count = (select count(*) from follows f where f.following_id = users.id),
+    following_count = (select count(*) from follows f where f.follower_id = users.id);
@@ -0,0 +1,3 @@
+drop table if exists timeline_rebuilds;
+drop index if exists idx__timelines__user_id_created_at;
+drop table if exists timelines;
@@ -0,0 +1,20 @@
+create table if not exists timelines (
+    user_id bigint not null,
+    post_id bigint not null,
+    author_id bigint not null,
+    created_at timestamp not null,
+    constraint pk__timelines primary key (user_id, post_id),
+    constraint fk__timelines__user_id foreign key (user_id) references users(id),
+    constraint fk__timelines__post_id foreign key (post_id) references posts(id)
+);
+
+create index idx__timelines__user_id_created_at on timelines(user_id, created_at desc, post_id desc);
+
+create table if not exists timeline_rebuilds (
+    user_id bigint not null,
+    requested_at timestamp not null default now(),
+    constraint pk__timeline_rebuilds primary key (user_id),
+    constraint fk__timeline_rebuilds__user_id foreign key (user_id) references users(id)
+);
+
+insert into timeline_rebuilds (user_id) select distinct follower_id from follows;
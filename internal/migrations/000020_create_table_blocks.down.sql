@@ -0,0 +1,2 @@
+drop index if exists idx__blocks__blocked_id;
+drop table if exists blocks;
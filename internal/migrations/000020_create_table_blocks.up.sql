@@ -0,0 +1,11 @@
+create table if not exists blocks (
+    blocker_id bigint not null,
+    blocked_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__blocks primary key (blocker_id, blocked_id),
+    constraint ck__blocks__not_self check (blocker_id <> blocked_id),
+    constraint fk__blocks__blocker_id foreign key (blocker_id) references users(id),
+    constraint fk__blocks__blocked_id foreign key (blocked_id) references users(id)
+);
+
+create index idx__blocks__blocked_id on blocks(blocked_id, blocker_id);
@@ -0,0 +1,4 @@
+drop table if exists muted_conversations;
+drop index if exists idx__muted_words__user_id__word;
+drop table if exists muted_words;
+drop table if exists muted_users;
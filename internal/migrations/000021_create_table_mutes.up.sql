@@ -0,0 +1,30 @@
+create table if not exists muted_users (
+    user_id bigint not null,
+    muted_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__muted_users primary key (user_id, muted_id),
+    constraint ck__muted_users__not_self check (user_id <> muted_id),
+    constraint fk__muted_users__user_id foreign key (user_id) references users(id),
+    constraint fk__muted_users__muted_id foreign key (muted_id) references users(id)
+);
+
+create table if not exists muted_words (
+    id bigserial,
+    user_id bigint not null,
+    word varchar(100) not null,
+    created_at timestamp not null default now(),
+    expires_at timestamp,
+    constraint pk__muted_words primary key (id),
+    constraint fk__muted_words__user_id foreign key (user_id) references users(id)
+);
+
+create unique index idx__muted_words__user_id__word on muted_words(user_id, word);
+
+create table if not exists muted_conversations (
+    user_id bigint not null,
+    post_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__muted_conversations primary key (user_id, post_id),
+    constraint fk__muted_conversations__user_id foreign key (user_id) references users(id),
+    constraint fk__muted_conversations__post_id foreign key (post_id) references posts(id)
+);
@@ -0,0 +1,3 @@
+drop index if exists idx__follow_requests__target_id;
+drop table if exists follow_requests;
+alter table users drop column if exists protected;
@@ -0,0 +1,13 @@
+alter table users add column if not exists protected boolean not null default false;
+
+create table if not exists follow_requests (
+    requester_id bigint not null,
+    target_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__follow_requests primary key (requester_id, target_id),
+    constraint ck__follow_requests__not_self check (requester_id <> target_id),
+    constraint fk__follow_requests__requester_id foreign key (requester_id) references users(id),
+    constraint fk__follow_requests__target_id foreign key (target_id) references users(id)
+);
+
+create index idx__follow_requests__target_id on follow_requests(target_id, created_at desc);
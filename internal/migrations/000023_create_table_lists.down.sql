@@ -0,0 +1,6 @@
+drop index if exists idx__list_subscriptions__user_id;
+drop table if exists list_subscriptions;
+drop index if exists idx__list_members__user_id;
+drop table if exists list_members;
+drop index if exists idx__lists__user_id;
+drop table if exists lists;
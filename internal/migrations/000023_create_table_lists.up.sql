@@ -0,0 +1,35 @@
+create table if not exists lists (
+    id bigserial,
+    user_id bigint not null,
+    name varchar(50) not null,
+    description varchar(160) not null default '',
+    private boolean not null default false,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    constraint pk__lists primary key (id),
+    constraint fk__lists__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__lists__user_id on lists(user_id, created_at desc);
+
+create table if not exists list_members (
+    list_id bigint not null,
+    user_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__list_members primary key (list_id, user_id),
+    constraint fk__list_members__list_id foreign key (list_id) references lists(id) on delete cascade,
+    constraint fk__list_members__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__list_members__user_id on list_members(user_id, list_id);
+
+create table if not exists list_subscriptions (
+    list_id bigint not null,
+    user_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__list_subscriptions primary key (list_id, user_id),
+    constraint fk__list_subscriptions__list_id foreign key (list_id) references lists(id) on delete cascade,
+    constraint fk__list_subscriptions__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__list_subscriptions__user_id on list_subscriptions(user_id, list_id);
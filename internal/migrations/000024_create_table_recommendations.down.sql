@@ -0,0 +1,2 @@
+drop index if exists idx__recommendations__user_id_score;
+drop table if exists recommendations;
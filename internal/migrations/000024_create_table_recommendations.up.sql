@@ -0,0 +1,13 @@
+create table if not exists recommendations (
+    user_id bigint not null,
+    recommended_id bigint not null,
+    score double precision not null,
+    reason varchar(20) not null,
+    computed_at timestamp not null default now(),
+    constraint pk__recommendations primary key (user_id, recommended_id),
+    constraint ck__recommendations__not_self check (user_id <> recommended_id),
+    constraint fk__recommendations__user_id foreign key (user_id) references users(id),
+    constraint fk__recommendations__recommended_id foreign key (recommended_id) references users(id)
+);
+
+create index idx__recommendations__user_id_score on recommendations(user_id, score desc, recommended_id);
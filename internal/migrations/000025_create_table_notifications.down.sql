@@ -0,0 +1,5 @@
+drop table if exists notification_preferences;
+drop table if exists notification_actors;
+drop index if exists idx__notifications__unread;
+drop index if exists idx__notifications__user_id_updated_at;
+drop table if exists notifications;
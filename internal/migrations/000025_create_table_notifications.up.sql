@@ -0,0 +1,35 @@
+create table if not exists notifications (
+    id bigserial,
+    user_id bigint not null,
+    type varchar(20) not null,
+    post_id bigint,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    read_at timestamp,
+    constraint pk__notifications primary key (id),
+    constraint fk__notifications__user_id foreign key (user_id) references users(id),
+    constraint fk__notifications__post_id foreign key (post_id) references posts(id)
+);
+
+create index idx__notifications__user_id_updated_at on notifications(user_id, updated_at desc, id desc);
+
+-- events join the unread notification of the same type and post
+create unique index idx__notifications__unread on notifications(user_id, type, (coalesce(post_id, 0)))
+where read_at is null;
+
+create table if not exists notification_actors (
+    notification_id bigint not null,
+    actor_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__notification_actors primary key (notification_id, actor_id),
+    constraint fk__notification_actors__notification_id foreign key (notification_id) references notifications(id) on delete cascade,
+    constraint fk__notification_actors__actor_id foreign key (actor_id) references users(id)
+);
+
+create table if not exists notification_preferences (
+    user_id bigint not null,
+    type varchar(20) not null,
+    enabled boolean not null,
+    constraint pk__notification_preferences primary key (user_id, type),
+    constraint fk__notification_preferences__user_id foreign key (user_id) references users(id)
+);
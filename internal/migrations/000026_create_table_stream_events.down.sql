@@ -0,0 +1,3 @@
+drop index if exists idx__stream_events__created_at;
+drop index if exists idx__stream_events__topic_id;
+drop table if exists stream_events;
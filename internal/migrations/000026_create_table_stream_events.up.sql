@@ -0,0 +1,11 @@
+create table if not exists stream_events (
+    id bigserial,
+    topic varchar(64) not null,
+    type varchar(32) not null,
+    data jsonb not null,
+    created_at timestamp not null default now(),
+    constraint pk__stream_events primary key (id)
+);
+
+create index idx__stream_events__topic_id on stream_events(topic, id);
+create index idx__stream_events__created_at on stream_events(created_at);
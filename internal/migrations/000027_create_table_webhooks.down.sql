@@ -0,0 +1,7 @@
+drop index if exists idx__webhook_deliveries__pending;
+drop index if exists idx__webhook_deliveries__webhook_id;
+drop table if exists webhook_deliveries;
+drop index if exists idx__webhook_events__event;
+drop table if exists webhook_events;
+drop index if exists idx__webhooks__user_id;
+drop table if exists webhooks;
@@ -0,0 +1,47 @@
+create table if not exists webhooks (
+    id bigserial,
+    user_id bigint not null,
+    url varchar(2048) not null,
+    secret varchar(64) not null,
+    all_users boolean not null default false,
+    active boolean not null default true,
+    failures int not null default 0,
+    disabled_at timestamp,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    constraint pk__webhooks primary key (id),
+    constraint fk__webhooks__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__webhooks__user_id on webhooks(user_id);
+
+create table if not exists webhook_events (
+    webhook_id bigint not null,
+    event varchar(32) not null,
+    constraint pk__webhook_events primary key (webhook_id, event),
+    constraint fk__webhook_events__webhook_id foreign key (webhook_id) references webhooks(id) on delete cascade
+);
+
+create index idx__webhook_events__event on webhook_events(event);
+
+create table if not exists webhook_deliveries (
+    id bigserial,
+    webhook_id bigint not null,
+    event varchar(32) not null,
+    payload jsonb not null,
+    status varchar(16) not null default 'pending',
+    attempts int not null default 0,
+    next_attempt_at timestamp not null default now(),
+    last_attempt_at timestamp,
+    response_status int,
+    error text,
+    created_at timestamp not null default now(),
+    constraint pk__webhook_deliveries primary key (id),
+    constraint fk__webhook_deliveries__webhook_id foreign key (webhook_id) references webhooks(id) on delete cascade
+);
+
+create index idx__webhook_deliveries__webhook_id on webhook_deliveries(webhook_id, id desc);
+
+-- the queue: deliveries still waiting for a successful attempt
+create index idx__webhook_deliveries__pending on webhook_deliveries(next_attempt_at)
+where status = 'pending';
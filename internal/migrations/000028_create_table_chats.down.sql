@@ -0,0 +1,7 @@
+drop table if exists message_deletions;
+drop index if exists idx__messages__chat_id_created_at;
+drop table if exists messages;
+drop index if exists idx__chat_members__user_id;
+drop table if exists chat_members;
+drop table if exists chats;
+alter table users drop column if exists messages_from_followers_only;
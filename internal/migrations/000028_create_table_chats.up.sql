@@ -0,0 +1,53 @@
+alter table users add column if not exists messages_from_followers_only boolean not null default false;
+
+create table if not exists chats (
+    id bigserial,
+    -- the two members of a direct chat, lowest id first, so that a pair has one chat
+    direct_low_id bigint,
+    direct_high_id bigint,
+    created_at timestamp not null default now(),
+    last_message_at timestamp not null default now(),
+    constraint pk__chats primary key (id),
+    constraint ck__chats__direct check (direct_low_id < direct_high_id),
+    constraint uq__chats__direct unique (direct_low_id, direct_high_id),
+    constraint fk__chats__direct_low_id foreign key (direct_low_id) references users(id),
+    constraint fk__chats__direct_high_id foreign key (direct_high_id) references users(id)
+);
+
+create table if not exists chat_members (
+    chat_id bigint not null,
+    user_id bigint not null,
+    last_read_message_id bigint not null default 0,
+    read_at timestamp,
+    muted_at timestamp,
+    created_at timestamp not null default now(),
+    constraint pk__chat_members primary key (chat_id, user_id),
+    constraint fk__chat_members__chat_id foreign key (chat_id) references chats(id) on delete cascade,
+    constraint fk__chat_members__user_id foreign key (user_id) references users(id)
+);
+
+create index idx__chat_members__user_id on chat_members(user_id);
+
+create table if not exists messages (
+    id bigserial,
+    chat_id bigint not null,
+    sender_id bigint not null,
+    text varchar(1000) not null,
+    created_at timestamp not null default now(),
+    unsent_at timestamp,
+    constraint pk__messages primary key (id),
+    constraint fk__messages__chat_id foreign key (chat_id) references chats(id) on delete cascade,
+    constraint fk__messages__sender_id foreign key (sender_id) references users(id)
+);
+
+create index idx__messages__chat_id_created_at on messages(chat_id, created_at desc, id desc);
+
+-- messages a member deleted for themselves only
+create table if not exists message_deletions (
+    message_id bigint not null,
+    user_id bigint not null,
+    created_at timestamp not null default now(),
+    constraint pk__message_deletions primary key (message_id, user_id),
+    constraint fk__message_deletions__message_id foreign key (message_id) references messages(id) on delete cascade,
+    constraint fk__message_deletions__user_id foreign key (user_id) references users(id)
+);
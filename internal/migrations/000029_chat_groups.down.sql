@@ -0,0 +1,10 @@
+delete from chats where is_group;
+
+alter table messages drop constraint if exists fk__messages__subject_id;
+alter table messages drop column if exists subject_id;
+alter table messages drop column if exists type;
+
+alter table chat_members drop column if exists role;
+
+alter table chats drop column if exists name;
+alter table chats drop column if exists is_group;
@@ -0,0 +1,8 @@
+alter table chats add column if not exists is_group boolean not null default false;
+alter table chats add column if not exists name varchar(100);
+
+alter table chat_members add column if not exists role varchar(10) not null default 'member';
+
+alter table messages add column if not exists type varchar(20) not null default 'text';
+alter table messages add column if not exists subject_id bigint;
+alter table messages add constraint fk__messages__subject_id foreign key (subject_id) references users(id);
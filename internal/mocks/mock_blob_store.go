@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/storage (interfaces: BlobStore)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBlobStore is a mock of BlobStore interface.
+type MockBlobStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobStoreMockRecorder
+}
+
+// MockBlobStoreMockRecorder is the mock recorder for MockBlobStore.
+type MockBlobStoreMockRecorder struct {
+	mock *MockBlobStore
+}
+
+// NewMockBlobStore creates a new mock instance.
+func NewMockBlobStore(ctrl *gomock.Controller) *MockBlobStore {
+	mock := &MockBlobStore{ctrl: ctrl}
+	mock.recorder = &MockBlobStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobStore) EXPECT() *MockBlobStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockBlobStore) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBlobStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobStore)(nil).Delete), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *MockBlobStore) Get(arg0 context.Context, arg1 string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockBlobStoreMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBlobStore)(nil).Get), arg0, arg1)
+}
+
+// Put mocks base method.
+func (m *MockBlobStore) Put(arg0 context.Context, arg1 string, arg2 io.Reader, arg3 int64, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobStoreMockRecorder) Put(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobStore)(nil).Put), arg0, arg1, arg2, arg3, arg4)
+}
+
+// URL mocks base method.
+func (m *MockBlobStore) URL(arg0 string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "URL", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// URL indicates an expected call of URL.
+func (mr *MockBlobStoreMockRecorder) URL(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URL", reflect.TypeOf((*MockBlobStore)(nil).URL), arg0)
+}
@@ -0,0 +1,267 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/repository (interfaces: ChatRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockChatRepository is a mock of ChatRepository interface.
+type MockChatRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatRepositoryMockRecorder
+}
+
+// MockChatRepositoryMockRecorder is the mock recorder for MockChatRepository.
+type MockChatRepositoryMockRecorder struct {
+	mock *MockChatRepository
+}
+
+// NewMockChatRepository creates a new mock instance.
+func NewMockChatRepository(ctrl *gomock.Controller) *MockChatRepository {
+	mock := &MockChatRepository{ctrl: ctrl}
+	mock.recorder = &MockChatRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatRepository) EXPECT() *MockChatRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddChatMember mocks base method.
+func (m *MockChatRepository) AddChatMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChatMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddChatMember indicates an expected call of AddChatMember.
+func (mr *MockChatRepositoryMockRecorder) AddChatMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChatMember", reflect.TypeOf((*MockChatRepository)(nil).AddChatMember), arg0, arg1, arg2)
+}
+
+// CountUnread mocks base method.
+func (m *MockChatRepository) CountUnread(arg0 uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockChatRepositoryMockRecorder) CountUnread(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockChatRepository)(nil).CountUnread), arg0)
+}
+
+// CreateDirectChat mocks base method.
+func (m *MockChatRepository) CreateDirectChat(arg0, arg1 uint64) (uint64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDirectChat", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateDirectChat indicates an expected call of CreateDirectChat.
+func (mr *MockChatRepositoryMockRecorder) CreateDirectChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDirectChat", reflect.TypeOf((*MockChatRepository)(nil).CreateDirectChat), arg0, arg1)
+}
+
+// CreateGroupChat mocks base method.
+func (m *MockChatRepository) CreateGroupChat(arg0 models.CreateGroupChatDTO) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroupChat", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroupChat indicates an expected call of CreateGroupChat.
+func (mr *MockChatRepositoryMockRecorder) CreateGroupChat(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupChat", reflect.TypeOf((*MockChatRepository)(nil).CreateGroupChat), arg0)
+}
+
+// CreateMessage mocks base method.
+func (m *MockChatRepository) CreateMessage(arg0 models.CreateMessageDTO) (*models.ReadMessageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMessage", arg0)
+	ret0, _ := ret[0].(*models.ReadMessageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMessage indicates an expected call of CreateMessage.
+func (mr *MockChatRepositoryMockRecorder) CreateMessage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockChatRepository)(nil).CreateMessage), arg0)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockChatRepository) DeleteMessage(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockChatRepositoryMockRecorder) DeleteMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockChatRepository)(nil).DeleteMessage), arg0, arg1, arg2)
+}
+
+// GetChatByID mocks base method.
+func (m *MockChatRepository) GetChatByID(arg0, arg1 uint64) (*models.ReadChatDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadChatDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatByID indicates an expected call of GetChatByID.
+func (mr *MockChatRepositoryMockRecorder) GetChatByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatByID", reflect.TypeOf((*MockChatRepository)(nil).GetChatByID), arg0, arg1)
+}
+
+// GetChats mocks base method.
+func (m *MockChatRepository) GetChats(arg0 uint64, arg1 *models.Cursor, arg2 uint64) ([]models.ReadChatDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChats", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadChatDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChats indicates an expected call of GetChats.
+func (mr *MockChatRepositoryMockRecorder) GetChats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChats", reflect.TypeOf((*MockChatRepository)(nil).GetChats), arg0, arg1, arg2)
+}
+
+// GetMessages mocks base method.
+func (m *MockChatRepository) GetMessages(arg0, arg1 uint64, arg2 *models.Cursor, arg3 uint64) ([]models.ReadMessageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadMessageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockChatRepositoryMockRecorder) GetMessages(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockChatRepository)(nil).GetMessages), arg0, arg1, arg2, arg3)
+}
+
+// MarkRead mocks base method.
+func (m *MockChatRepository) MarkRead(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockChatRepositoryMockRecorder) MarkRead(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockChatRepository)(nil).MarkRead), arg0, arg1, arg2)
+}
+
+// MuteChat mocks base method.
+func (m *MockChatRepository) MuteChat(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteChat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteChat indicates an expected call of MuteChat.
+func (mr *MockChatRepositoryMockRecorder) MuteChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteChat", reflect.TypeOf((*MockChatRepository)(nil).MuteChat), arg0, arg1)
+}
+
+// RemoveChatMember mocks base method.
+func (m *MockChatRepository) RemoveChatMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveChatMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveChatMember indicates an expected call of RemoveChatMember.
+func (mr *MockChatRepositoryMockRecorder) RemoveChatMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChatMember", reflect.TypeOf((*MockChatRepository)(nil).RemoveChatMember), arg0, arg1, arg2)
+}
+
+// UnmuteChat mocks base method.
+func (m *MockChatRepository) UnmuteChat(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteChat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteChat indicates an expected call of UnmuteChat.
+func (mr *MockChatRepositoryMockRecorder) UnmuteChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteChat", reflect.TypeOf((*MockChatRepository)(nil).UnmuteChat), arg0, arg1)
+}
+
+// UnsendMessage mocks base method.
+func (m *MockChatRepository) UnsendMessage(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsendMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsendMessage indicates an expected call of UnsendMessage.
+func (mr *MockChatRepositoryMockRecorder) UnsendMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsendMessage", reflect.TypeOf((*MockChatRepository)(nil).UnsendMessage), arg0, arg1, arg2)
+}
+
+// UpdateChat mocks base method.
+func (m *MockChatRepository) UpdateChat(arg0, arg1 uint64, arg2 models.UpdateChatDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChat", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChat indicates an expected call of UpdateChat.
+func (mr *MockChatRepositoryMockRecorder) UpdateChat(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChat", reflect.TypeOf((*MockChatRepository)(nil).UpdateChat), arg0, arg1, arg2)
+}
+
+// UpdateChatMember mocks base method.
+func (m *MockChatRepository) UpdateChatMember(arg0, arg1, arg2 uint64, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChatMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChatMember indicates an expected call of UpdateChatMember.
+func (mr *MockChatRepositoryMockRecorder) UpdateChatMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChatMember", reflect.TypeOf((*MockChatRepository)(nil).UpdateChatMember), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,282 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/service (interfaces: ChatService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockChatService is a mock of ChatService interface.
+type MockChatService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatServiceMockRecorder
+}
+
+// MockChatServiceMockRecorder is the mock recorder for MockChatService.
+type MockChatServiceMockRecorder struct {
+	mock *MockChatService
+}
+
+// NewMockChatService creates a new mock instance.
+func NewMockChatService(ctrl *gomock.Controller) *MockChatService {
+	mock := &MockChatService{ctrl: ctrl}
+	mock.recorder = &MockChatServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatService) EXPECT() *MockChatServiceMockRecorder {
+	return m.recorder
+}
+
+// AddChatMember mocks base method.
+func (m *MockChatService) AddChatMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChatMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddChatMember indicates an expected call of AddChatMember.
+func (mr *MockChatServiceMockRecorder) AddChatMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChatMember", reflect.TypeOf((*MockChatService)(nil).AddChatMember), arg0, arg1, arg2)
+}
+
+// CountUnread mocks base method.
+func (m *MockChatService) CountUnread(arg0 uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockChatServiceMockRecorder) CountUnread(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockChatService)(nil).CountUnread), arg0)
+}
+
+// CreateDirectChat mocks base method.
+func (m *MockChatService) CreateDirectChat(arg0, arg1 uint64) (*models.ReadChatDTO, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDirectChat", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadChatDTO)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateDirectChat indicates an expected call of CreateDirectChat.
+func (mr *MockChatServiceMockRecorder) CreateDirectChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDirectChat", reflect.TypeOf((*MockChatService)(nil).CreateDirectChat), arg0, arg1)
+}
+
+// CreateGroupChat mocks base method.
+func (m *MockChatService) CreateGroupChat(arg0 models.CreateGroupChatDTO) (*models.ReadChatDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroupChat", arg0)
+	ret0, _ := ret[0].(*models.ReadChatDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroupChat indicates an expected call of CreateGroupChat.
+func (mr *MockChatServiceMockRecorder) CreateGroupChat(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupChat", reflect.TypeOf((*MockChatService)(nil).CreateGroupChat), arg0)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockChatService) DeleteMessage(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockChatServiceMockRecorder) DeleteMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockChatService)(nil).DeleteMessage), arg0, arg1, arg2)
+}
+
+// GetChatByID mocks base method.
+func (m *MockChatService) GetChatByID(arg0, arg1 uint64) (*models.ReadChatDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadChatDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatByID indicates an expected call of GetChatByID.
+func (mr *MockChatServiceMockRecorder) GetChatByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatByID", reflect.TypeOf((*MockChatService)(nil).GetChatByID), arg0, arg1)
+}
+
+// GetChatsPage mocks base method.
+func (m *MockChatService) GetChatsPage(arg0 uint64, arg1 *models.Cursor, arg2 uint64) (*models.ChatPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatsPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ChatPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatsPage indicates an expected call of GetChatsPage.
+func (mr *MockChatServiceMockRecorder) GetChatsPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatsPage", reflect.TypeOf((*MockChatService)(nil).GetChatsPage), arg0, arg1, arg2)
+}
+
+// GetMessagesPage mocks base method.
+func (m *MockChatService) GetMessagesPage(arg0, arg1 uint64, arg2 *models.Cursor, arg3 uint64) (*models.MessagePageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessagesPage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.MessagePageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessagesPage indicates an expected call of GetMessagesPage.
+func (mr *MockChatServiceMockRecorder) GetMessagesPage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessagesPage", reflect.TypeOf((*MockChatService)(nil).GetMessagesPage), arg0, arg1, arg2, arg3)
+}
+
+// LeaveChat mocks base method.
+func (m *MockChatService) LeaveChat(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveChat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LeaveChat indicates an expected call of LeaveChat.
+func (mr *MockChatServiceMockRecorder) LeaveChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveChat", reflect.TypeOf((*MockChatService)(nil).LeaveChat), arg0, arg1)
+}
+
+// MarkRead mocks base method.
+func (m *MockChatService) MarkRead(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockChatServiceMockRecorder) MarkRead(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockChatService)(nil).MarkRead), arg0, arg1, arg2)
+}
+
+// MuteChat mocks base method.
+func (m *MockChatService) MuteChat(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteChat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteChat indicates an expected call of MuteChat.
+func (mr *MockChatServiceMockRecorder) MuteChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteChat", reflect.TypeOf((*MockChatService)(nil).MuteChat), arg0, arg1)
+}
+
+// RemoveChatMember mocks base method.
+func (m *MockChatService) RemoveChatMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveChatMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveChatMember indicates an expected call of RemoveChatMember.
+func (mr *MockChatServiceMockRecorder) RemoveChatMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChatMember", reflect.TypeOf((*MockChatService)(nil).RemoveChatMember), arg0, arg1, arg2)
+}
+
+// SendMessage mocks base method.
+func (m *MockChatService) SendMessage(arg0 models.CreateMessageDTO) (*models.ReadMessageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", arg0)
+	ret0, _ := ret[0].(*models.ReadMessageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockChatServiceMockRecorder) SendMessage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockChatService)(nil).SendMessage), arg0)
+}
+
+// UnmuteChat mocks base method.
+func (m *MockChatService) UnmuteChat(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteChat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteChat indicates an expected call of UnmuteChat.
+func (mr *MockChatServiceMockRecorder) UnmuteChat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteChat", reflect.TypeOf((*MockChatService)(nil).UnmuteChat), arg0, arg1)
+}
+
+// UnsendMessage mocks base method.
+func (m *MockChatService) UnsendMessage(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsendMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsendMessage indicates an expected call of UnsendMessage.
+func (mr *MockChatServiceMockRecorder) UnsendMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsendMessage", reflect.TypeOf((*MockChatService)(nil).UnsendMessage), arg0, arg1, arg2)
+}
+
+// UpdateChat mocks base method.
+func (m *MockChatService) UpdateChat(arg0, arg1 uint64, arg2 models.UpdateChatDTO) (*models.ReadChatDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChat", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadChatDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChat indicates an expected call of UpdateChat.
+func (mr *MockChatServiceMockRecorder) UpdateChat(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChat", reflect.TypeOf((*MockChatService)(nil).UpdateChat), arg0, arg1, arg2)
+}
+
+// UpdateChatMember mocks base method.
+func (m *MockChatService) UpdateChatMember(arg0, arg1, arg2 uint64, arg3 models.UpdateChatMemberDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChatMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChatMember indicates an expected call of UpdateChatMember.
+func (mr *MockChatServiceMockRecorder) UpdateChatMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChatMember", reflect.TypeOf((*MockChatService)(nil).UpdateChatMember), arg0, arg1, arg2, arg3)
+}
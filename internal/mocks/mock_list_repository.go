@@ -0,0 +1,180 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/repository (interfaces: ListRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockListRepository is a mock of ListRepository interface.
+type MockListRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockListRepositoryMockRecorder
+}
+
+// MockListRepositoryMockRecorder is the mock recorder for MockListRepository.
+type MockListRepositoryMockRecorder struct {
+	mock *MockListRepository
+}
+
+// NewMockListRepository creates a new mock instance.
+func NewMockListRepository(ctrl *gomock.Controller) *MockListRepository {
+	mock := &MockListRepository{ctrl: ctrl}
+	mock.recorder = &MockListRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListRepository) EXPECT() *MockListRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddListMember mocks base method.
+func (m *MockListRepository) AddListMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddListMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddListMember indicates an expected call of AddListMember.
+func (mr *MockListRepositoryMockRecorder) AddListMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddListMember", reflect.TypeOf((*MockListRepository)(nil).AddListMember), arg0, arg1, arg2)
+}
+
+// CreateList mocks base method.
+func (m *MockListRepository) CreateList(arg0 models.CreateListDTO) (*models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateList", arg0)
+	ret0, _ := ret[0].(*models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateList indicates an expected call of CreateList.
+func (mr *MockListRepositoryMockRecorder) CreateList(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateList", reflect.TypeOf((*MockListRepository)(nil).CreateList), arg0)
+}
+
+// DeleteList mocks base method.
+func (m *MockListRepository) DeleteList(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteList indicates an expected call of DeleteList.
+func (mr *MockListRepositoryMockRecorder) DeleteList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockListRepository)(nil).DeleteList), arg0, arg1)
+}
+
+// GetListByID mocks base method.
+func (m *MockListRepository) GetListByID(arg0, arg1 uint64) (*models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListByID indicates an expected call of GetListByID.
+func (mr *MockListRepositoryMockRecorder) GetListByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByID", reflect.TypeOf((*MockListRepository)(nil).GetListByID), arg0, arg1)
+}
+
+// GetListMembers mocks base method.
+func (m *MockListRepository) GetListMembers(arg0, arg1, arg2, arg3 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListMembers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListMembers indicates an expected call of GetListMembers.
+func (mr *MockListRepositoryMockRecorder) GetListMembers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListMembers", reflect.TypeOf((*MockListRepository)(nil).GetListMembers), arg0, arg1, arg2, arg3)
+}
+
+// GetLists mocks base method.
+func (m *MockListRepository) GetLists(arg0 models.FilterListDTO) ([]models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLists", arg0)
+	ret0, _ := ret[0].([]models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLists indicates an expected call of GetLists.
+func (mr *MockListRepositoryMockRecorder) GetLists(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockListRepository)(nil).GetLists), arg0)
+}
+
+// RemoveListMember mocks base method.
+func (m *MockListRepository) RemoveListMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveListMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveListMember indicates an expected call of RemoveListMember.
+func (mr *MockListRepositoryMockRecorder) RemoveListMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveListMember", reflect.TypeOf((*MockListRepository)(nil).RemoveListMember), arg0, arg1, arg2)
+}
+
+// SubscribeList mocks base method.
+func (m *MockListRepository) SubscribeList(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeList indicates an expected call of SubscribeList.
+func (mr *MockListRepositoryMockRecorder) SubscribeList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeList", reflect.TypeOf((*MockListRepository)(nil).SubscribeList), arg0, arg1)
+}
+
+// UnsubscribeList mocks base method.
+func (m *MockListRepository) UnsubscribeList(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsubscribeList indicates an expected call of UnsubscribeList.
+func (mr *MockListRepositoryMockRecorder) UnsubscribeList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeList", reflect.TypeOf((*MockListRepository)(nil).UnsubscribeList), arg0, arg1)
+}
+
+// UpdateList mocks base method.
+func (m *MockListRepository) UpdateList(arg0, arg1 uint64, arg2 models.UpdateListDTO) (*models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateList", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateList indicates an expected call of UpdateList.
+func (mr *MockListRepositoryMockRecorder) UpdateList(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateList", reflect.TypeOf((*MockListRepository)(nil).UpdateList), arg0, arg1, arg2)
+}
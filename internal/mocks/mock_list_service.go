@@ -0,0 +1,180 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/service (interfaces: ListService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockListService is a mock of ListService interface.
+type MockListService struct {
+	ctrl     *gomock.Controller
+	recorder *MockListServiceMockRecorder
+}
+
+// MockListServiceMockRecorder is the mock recorder for MockListService.
+type MockListServiceMockRecorder struct {
+	mock *MockListService
+}
+
+// NewMockListService creates a new mock instance.
+func NewMockListService(ctrl *gomock.Controller) *MockListService {
+	mock := &MockListService{ctrl: ctrl}
+	mock.recorder = &MockListServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListService) EXPECT() *MockListServiceMockRecorder {
+	return m.recorder
+}
+
+// AddListMember mocks base method.
+func (m *MockListService) AddListMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddListMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddListMember indicates an expected call of AddListMember.
+func (mr *MockListServiceMockRecorder) AddListMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddListMember", reflect.TypeOf((*MockListService)(nil).AddListMember), arg0, arg1, arg2)
+}
+
+// CreateList mocks base method.
+func (m *MockListService) CreateList(arg0 models.CreateListDTO) (*models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateList", arg0)
+	ret0, _ := ret[0].(*models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateList indicates an expected call of CreateList.
+func (mr *MockListServiceMockRecorder) CreateList(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateList", reflect.TypeOf((*MockListService)(nil).CreateList), arg0)
+}
+
+// DeleteList mocks base method.
+func (m *MockListService) DeleteList(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteList indicates an expected call of DeleteList.
+func (mr *MockListServiceMockRecorder) DeleteList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockListService)(nil).DeleteList), arg0, arg1)
+}
+
+// GetListByID mocks base method.
+func (m *MockListService) GetListByID(arg0, arg1 uint64) (*models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListByID indicates an expected call of GetListByID.
+func (mr *MockListServiceMockRecorder) GetListByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByID", reflect.TypeOf((*MockListService)(nil).GetListByID), arg0, arg1)
+}
+
+// GetListMembers mocks base method.
+func (m *MockListService) GetListMembers(arg0, arg1, arg2, arg3 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListMembers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListMembers indicates an expected call of GetListMembers.
+func (mr *MockListServiceMockRecorder) GetListMembers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListMembers", reflect.TypeOf((*MockListService)(nil).GetListMembers), arg0, arg1, arg2, arg3)
+}
+
+// GetLists mocks base method.
+func (m *MockListService) GetLists(arg0 models.FilterListDTO) ([]models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLists", arg0)
+	ret0, _ := ret[0].([]models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLists indicates an expected call of GetLists.
+func (mr *MockListServiceMockRecorder) GetLists(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockListService)(nil).GetLists), arg0)
+}
+
+// RemoveListMember mocks base method.
+func (m *MockListService) RemoveListMember(arg0, arg1, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveListMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveListMember indicates an expected call of RemoveListMember.
+func (mr *MockListServiceMockRecorder) RemoveListMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveListMember", reflect.TypeOf((*MockListService)(nil).RemoveListMember), arg0, arg1, arg2)
+}
+
+// SubscribeList mocks base method.
+func (m *MockListService) SubscribeList(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeList indicates an expected call of SubscribeList.
+func (mr *MockListServiceMockRecorder) SubscribeList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeList", reflect.TypeOf((*MockListService)(nil).SubscribeList), arg0, arg1)
+}
+
+// UnsubscribeList mocks base method.
+func (m *MockListService) UnsubscribeList(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsubscribeList indicates an expected call of UnsubscribeList.
+func (mr *MockListServiceMockRecorder) UnsubscribeList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeList", reflect.TypeOf((*MockListService)(nil).UnsubscribeList), arg0, arg1)
+}
+
+// UpdateList mocks base method.
+func (m *MockListService) UpdateList(arg0, arg1 uint64, arg2 models.UpdateListDTO) (*models.ReadListDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateList", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadListDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateList indicates an expected call of UpdateList.
+func (mr *MockListServiceMockRecorder) UpdateList(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateList", reflect.TypeOf((*MockListService)(nil).UpdateList), arg0, arg1, arg2)
+}
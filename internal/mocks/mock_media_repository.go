@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/repository (interfaces: MediaRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockMediaRepository is a mock of MediaRepository interface.
+type MockMediaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMediaRepositoryMockRecorder
+}
+
+// MockMediaRepositoryMockRecorder is the mock recorder for MockMediaRepository.
+type MockMediaRepositoryMockRecorder struct {
+	mock *MockMediaRepository
+}
+
+// NewMockMediaRepository creates a new mock instance.
+func NewMockMediaRepository(ctrl *gomock.Controller) *MockMediaRepository {
+	mock := &MockMediaRepository{ctrl: ctrl}
+	mock.recorder = &MockMediaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMediaRepository) EXPECT() *MockMediaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateMedia mocks base method.
+func (m *MockMediaRepository) CreateMedia(arg0 models.CreateMediaDTO) (*models.ReadMediaDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMedia", arg0)
+	ret0, _ := ret[0].(*models.ReadMediaDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMedia indicates an expected call of CreateMedia.
+func (mr *MockMediaRepositoryMockRecorder) CreateMedia(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMedia", reflect.TypeOf((*MockMediaRepository)(nil).CreateMedia), arg0)
+}
+
+// GetMediaByID mocks base method.
+func (m *MockMediaRepository) GetMediaByID(arg0, arg1 uint64) (*models.ReadMediaDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadMediaDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaByID indicates an expected call of GetMediaByID.
+func (mr *MockMediaRepositoryMockRecorder) GetMediaByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaByID", reflect.TypeOf((*MockMediaRepository)(nil).GetMediaByID), arg0, arg1)
+}
+
+// UpdateMedia mocks base method.
+func (m *MockMediaRepository) UpdateMedia(arg0, arg1 uint64, arg2 models.UpdateMediaDTO) (*models.ReadMediaDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMedia", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadMediaDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMedia indicates an expected call of UpdateMedia.
+func (mr *MockMediaRepositoryMockRecorder) UpdateMedia(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMedia", reflect.TypeOf((*MockMediaRepository)(nil).UpdateMedia), arg0, arg1, arg2)
+}
+
+// UpdateMediaVariants mocks base method.
+func (m *MockMediaRepository) UpdateMediaVariants(arg0 uint64, arg1 string, arg2 []models.ReadMediaVariantDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMediaVariants", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMediaVariants indicates an expected call of UpdateMediaVariants.
+func (mr *MockMediaRepositoryMockRecorder) UpdateMediaVariants(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMediaVariants", reflect.TypeOf((*MockMediaRepository)(nil).UpdateMediaVariants), arg0, arg1, arg2)
+}
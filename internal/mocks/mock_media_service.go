@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/service (interfaces: MediaService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockMediaService is a mock of MediaService interface.
+type MockMediaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMediaServiceMockRecorder
+}
+
+// MockMediaServiceMockRecorder is the mock recorder for MockMediaService.
+type MockMediaServiceMockRecorder struct {
+	mock *MockMediaService
+}
+
+// NewMockMediaService creates a new mock instance.
+func NewMockMediaService(ctrl *gomock.Controller) *MockMediaService {
+	mock := &MockMediaService{ctrl: ctrl}
+	mock.recorder = &MockMediaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMediaService) EXPECT() *MockMediaServiceMockRecorder {
+	return m.recorder
+}
+
+// GetMediaByID mocks base method.
+func (m *MockMediaService) GetMediaByID(arg0, arg1 uint64) (*models.ReadMediaDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadMediaDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaByID indicates an expected call of GetMediaByID.
+func (mr *MockMediaServiceMockRecorder) GetMediaByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaByID", reflect.TypeOf((*MockMediaService)(nil).GetMediaByID), arg0, arg1)
+}
+
+// OpenMediaFile mocks base method.
+func (m *MockMediaService) OpenMediaFile(arg0 string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenMediaFile", arg0)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenMediaFile indicates an expected call of OpenMediaFile.
+func (mr *MockMediaServiceMockRecorder) OpenMediaFile(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenMediaFile", reflect.TypeOf((*MockMediaService)(nil).OpenMediaFile), arg0)
+}
+
+// UpdateMedia mocks base method.
+func (m *MockMediaService) UpdateMedia(arg0, arg1 uint64, arg2 models.UpdateMediaDTO) (*models.ReadMediaDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMedia", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadMediaDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMedia indicates an expected call of UpdateMedia.
+func (mr *MockMediaServiceMockRecorder) UpdateMedia(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMedia", reflect.TypeOf((*MockMediaService)(nil).UpdateMedia), arg0, arg1, arg2)
+}
+
+// UploadMedia mocks base method.
+func (m *MockMediaService) UploadMedia(arg0 models.UploadMediaDTO) (*models.ReadMediaDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadMedia", arg0)
+	ret0, _ := ret[0].(*models.ReadMediaDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMedia indicates an expected call of UploadMedia.
+func (mr *MockMediaServiceMockRecorder) UploadMedia(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMedia", reflect.TypeOf((*MockMediaService)(nil).UploadMedia), arg0)
+}
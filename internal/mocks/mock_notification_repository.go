@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/repository (interfaces: NotificationRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockNotificationRepository is a mock of NotificationRepository interface.
+type MockNotificationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationRepositoryMockRecorder
+}
+
+// MockNotificationRepositoryMockRecorder is the mock recorder for MockNotificationRepository.
+type MockNotificationRepositoryMockRecorder struct {
+	mock *MockNotificationRepository
+}
+
+// NewMockNotificationRepository creates a new mock instance.
+func NewMockNotificationRepository(ctrl *gomock.Controller) *MockNotificationRepository {
+	mock := &MockNotificationRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationRepository) EXPECT() *MockNotificationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountUnread mocks base method.
+func (m *MockNotificationRepository) CountUnread(arg0 uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationRepositoryMockRecorder) CountUnread(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationRepository)(nil).CountUnread), arg0)
+}
+
+// GetNotifications mocks base method.
+func (m *MockNotificationRepository) GetNotifications(arg0 uint64, arg1 *models.Cursor, arg2 uint64) ([]models.ReadNotificationDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadNotificationDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) GetNotifications(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).GetNotifications), arg0, arg1, arg2)
+}
+
+// GetPreferences mocks base method.
+func (m *MockNotificationRepository) GetPreferences(arg0 uint64) (models.NotificationPreferencesDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", arg0)
+	ret0, _ := ret[0].(models.NotificationPreferencesDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockNotificationRepositoryMockRecorder) GetPreferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockNotificationRepository)(nil).GetPreferences), arg0)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationRepository) MarkAllRead(arg0 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkAllRead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkAllRead), arg0)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationRepository) MarkRead(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkRead), arg0, arg1)
+}
+
+// UpdatePreferences mocks base method.
+func (m *MockNotificationRepository) UpdatePreferences(arg0 uint64, arg1 models.NotificationPreferencesDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockNotificationRepositoryMockRecorder) UpdatePreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockNotificationRepository)(nil).UpdatePreferences), arg0, arg1)
+}
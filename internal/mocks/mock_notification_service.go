@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/service (interfaces: NotificationService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockNotificationService is a mock of NotificationService interface.
+type MockNotificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationServiceMockRecorder
+}
+
+// MockNotificationServiceMockRecorder is the mock recorder for MockNotificationService.
+type MockNotificationServiceMockRecorder struct {
+	mock *MockNotificationService
+}
+
+// NewMockNotificationService creates a new mock instance.
+func NewMockNotificationService(ctrl *gomock.Controller) *MockNotificationService {
+	mock := &MockNotificationService{ctrl: ctrl}
+	mock.recorder = &MockNotificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationService) EXPECT() *MockNotificationServiceMockRecorder {
+	return m.recorder
+}
+
+// CountUnread mocks base method.
+func (m *MockNotificationService) CountUnread(arg0 uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationServiceMockRecorder) CountUnread(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationService)(nil).CountUnread), arg0)
+}
+
+// GetNotificationsPage mocks base method.
+func (m *MockNotificationService) GetNotificationsPage(arg0 uint64, arg1 *models.Cursor, arg2 uint64) (*models.NotificationPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationsPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.NotificationPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationsPage indicates an expected call of GetNotificationsPage.
+func (mr *MockNotificationServiceMockRecorder) GetNotificationsPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationsPage", reflect.TypeOf((*MockNotificationService)(nil).GetNotificationsPage), arg0, arg1, arg2)
+}
+
+// GetPreferences mocks base method.
+func (m *MockNotificationService) GetPreferences(arg0 uint64) (models.NotificationPreferencesDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", arg0)
+	ret0, _ := ret[0].(models.NotificationPreferencesDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockNotificationServiceMockRecorder) GetPreferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockNotificationService)(nil).GetPreferences), arg0)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationService) MarkAllRead(arg0 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationServiceMockRecorder) MarkAllRead(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationService)(nil).MarkAllRead), arg0)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationService) MarkRead(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationServiceMockRecorder) MarkRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationService)(nil).MarkRead), arg0, arg1)
+}
+
+// UpdatePreferences mocks base method.
+func (m *MockNotificationService) UpdatePreferences(arg0 uint64, arg1 models.NotificationPreferencesDTO) (models.NotificationPreferencesDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", arg0, arg1)
+	ret0, _ := ret[0].(models.NotificationPreferencesDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockNotificationServiceMockRecorder) UpdatePreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockNotificationService)(nil).UpdatePreferences), arg0, arg1)
+}
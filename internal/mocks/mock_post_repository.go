@@ -92,6 +92,36 @@ func (mr *MockPostRepositoryMockRecorder) GetAllPosts(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostRepository)(nil).GetAllPosts), arg0)
 }
 
+// GetDrafts mocks base method.
+func (m *MockPostRepository) GetDrafts(arg0, arg1, arg2 uint64) ([]models.ReadPostDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrafts", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadPostDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrafts indicates an expected call of GetDrafts.
+func (mr *MockPostRepositoryMockRecorder) GetDrafts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrafts", reflect.TypeOf((*MockPostRepository)(nil).GetDrafts), arg0, arg1, arg2)
+}
+
+// GetPostByID mocks base method.
+func (m *MockPostRepository) GetPostByID(arg0, arg1 uint64) (*models.ReadPostDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadPostDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostByID indicates an expected call of GetPostByID.
+func (mr *MockPostRepositoryMockRecorder) GetPostByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostByID", reflect.TypeOf((*MockPostRepository)(nil).GetPostByID), arg0, arg1)
+}
+
 // LikePost mocks base method.
 func (m *MockPostRepository) LikePost(arg0, arg1 uint64) error {
 	m.ctrl.T.Helper()
@@ -106,6 +136,91 @@ func (mr *MockPostRepositoryMockRecorder) LikePost(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LikePost", reflect.TypeOf((*MockPostRepository)(nil).LikePost), arg0, arg1)
 }
 
+// MuteConversation mocks base method.
+func (m *MockPostRepository) MuteConversation(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteConversation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteConversation indicates an expected call of MuteConversation.
+func (mr *MockPostRepositoryMockRecorder) MuteConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteConversation", reflect.TypeOf((*MockPostRepository)(nil).MuteConversation), arg0, arg1)
+}
+
+// PinPost mocks base method.
+func (m *MockPostRepository) PinPost(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinPost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinPost indicates an expected call of PinPost.
+func (mr *MockPostRepositoryMockRecorder) PinPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinPost", reflect.TypeOf((*MockPostRepository)(nil).PinPost), arg0, arg1)
+}
+
+// PublishPost mocks base method.
+func (m *MockPostRepository) PublishPost(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPost indicates an expected call of PublishPost.
+func (mr *MockPostRepositoryMockRecorder) PublishPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPost", reflect.TypeOf((*MockPostRepository)(nil).PublishPost), arg0, arg1)
+}
+
+// UnmuteConversation mocks base method.
+func (m *MockPostRepository) UnmuteConversation(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteConversation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteConversation indicates an expected call of UnmuteConversation.
+func (mr *MockPostRepositoryMockRecorder) UnmuteConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteConversation", reflect.TypeOf((*MockPostRepository)(nil).UnmuteConversation), arg0, arg1)
+}
+
+// UnpinPost mocks base method.
+func (m *MockPostRepository) UnpinPost(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpinPost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpinPost indicates an expected call of UnpinPost.
+func (mr *MockPostRepositoryMockRecorder) UnpinPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpinPost", reflect.TypeOf((*MockPostRepository)(nil).UnpinPost), arg0, arg1)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockPostRepository) UpdateDraft(arg0, arg1 uint64, arg2 models.UpdateDraftDTO) (*models.ReadPostDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadPostDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockPostRepositoryMockRecorder) UpdateDraft(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockPostRepository)(nil).UpdateDraft), arg0, arg1, arg2)
+}
+
 // ViewPost mocks base method.
 func (m *MockPostRepository) ViewPost(arg0, arg1 uint64) error {
 	m.ctrl.T.Helper()
@@ -119,3 +234,17 @@ func (mr *MockPostRepositoryMockRecorder) ViewPost(arg0, arg1 interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewPost", reflect.TypeOf((*MockPostRepository)(nil).ViewPost), arg0, arg1)
 }
+
+// VotePoll mocks base method.
+func (m *MockPostRepository) VotePoll(arg0, arg1 uint64, arg2 []uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VotePoll", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VotePoll indicates an expected call of VotePoll.
+func (mr *MockPostRepositoryMockRecorder) VotePoll(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VotePoll", reflect.TypeOf((*MockPostRepository)(nil).VotePoll), arg0, arg1, arg2)
+}
@@ -92,6 +92,51 @@ func (mr *MockPostServiceMockRecorder) GetAllPosts(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostService)(nil).GetAllPosts), arg0)
 }
 
+// GetDrafts mocks base method.
+func (m *MockPostService) GetDrafts(arg0, arg1, arg2 uint64) ([]models.ReadPostDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrafts", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadPostDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrafts indicates an expected call of GetDrafts.
+func (mr *MockPostServiceMockRecorder) GetDrafts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrafts", reflect.TypeOf((*MockPostService)(nil).GetDrafts), arg0, arg1, arg2)
+}
+
+// GetPostByID mocks base method.
+func (m *MockPostService) GetPostByID(arg0, arg1 uint64) (*models.ReadPostDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadPostDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostByID indicates an expected call of GetPostByID.
+func (mr *MockPostServiceMockRecorder) GetPostByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostByID", reflect.TypeOf((*MockPostService)(nil).GetPostByID), arg0, arg1)
+}
+
+// GetPostsPage mocks base method.
+func (m *MockPostService) GetPostsPage(arg0 models.FilterPostDTO) (*models.PostPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostsPage", arg0)
+	ret0, _ := ret[0].(*models.PostPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostsPage indicates an expected call of GetPostsPage.
+func (mr *MockPostServiceMockRecorder) GetPostsPage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsPage", reflect.TypeOf((*MockPostService)(nil).GetPostsPage), arg0)
+}
+
 // LikePost mocks base method.
 func (m *MockPostService) LikePost(arg0, arg1 uint64) error {
 	m.ctrl.T.Helper()
@@ -106,6 +151,91 @@ func (mr *MockPostServiceMockRecorder) LikePost(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LikePost", reflect.TypeOf((*MockPostService)(nil).LikePost), arg0, arg1)
 }
 
+// MuteConversation mocks base method.
+func (m *MockPostService) MuteConversation(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteConversation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteConversation indicates an expected call of MuteConversation.
+func (mr *MockPostServiceMockRecorder) MuteConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteConversation", reflect.TypeOf((*MockPostService)(nil).MuteConversation), arg0, arg1)
+}
+
+// PinPost mocks base method.
+func (m *MockPostService) PinPost(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinPost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinPost indicates an expected call of PinPost.
+func (mr *MockPostServiceMockRecorder) PinPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinPost", reflect.TypeOf((*MockPostService)(nil).PinPost), arg0, arg1)
+}
+
+// PublishPost mocks base method.
+func (m *MockPostService) PublishPost(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPost indicates an expected call of PublishPost.
+func (mr *MockPostServiceMockRecorder) PublishPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPost", reflect.TypeOf((*MockPostService)(nil).PublishPost), arg0, arg1)
+}
+
+// UnmuteConversation mocks base method.
+func (m *MockPostService) UnmuteConversation(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteConversation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteConversation indicates an expected call of UnmuteConversation.
+func (mr *MockPostServiceMockRecorder) UnmuteConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteConversation", reflect.TypeOf((*MockPostService)(nil).UnmuteConversation), arg0, arg1)
+}
+
+// UnpinPost mocks base method.
+func (m *MockPostService) UnpinPost(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpinPost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpinPost indicates an expected call of UnpinPost.
+func (mr *MockPostServiceMockRecorder) UnpinPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpinPost", reflect.TypeOf((*MockPostService)(nil).UnpinPost), arg0, arg1)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockPostService) UpdateDraft(arg0, arg1 uint64, arg2 models.UpdateDraftDTO) (*models.ReadPostDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadPostDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockPostServiceMockRecorder) UpdateDraft(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockPostService)(nil).UpdateDraft), arg0, arg1, arg2)
+}
+
 // ViewPost mocks base method.
 func (m *MockPostService) ViewPost(arg0, arg1 uint64) error {
 	m.ctrl.T.Helper()
@@ -119,3 +249,17 @@ func (mr *MockPostServiceMockRecorder) ViewPost(arg0, arg1 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewPost", reflect.TypeOf((*MockPostService)(nil).ViewPost), arg0, arg1)
 }
+
+// VotePoll mocks base method.
+func (m *MockPostService) VotePoll(arg0, arg1 uint64, arg2 models.VotePollDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VotePoll", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VotePoll indicates an expected call of VotePoll.
+func (mr *MockPostServiceMockRecorder) VotePoll(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VotePoll", reflect.TypeOf((*MockPostService)(nil).VotePoll), arg0, arg1, arg2)
+}
@@ -34,6 +34,49 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApproveFollowRequest mocks base method.
+func (m *MockUserRepository) ApproveFollowRequest(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveFollowRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveFollowRequest indicates an expected call of ApproveFollowRequest.
+func (mr *MockUserRepositoryMockRecorder) ApproveFollowRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveFollowRequest", reflect.TypeOf((*MockUserRepository)(nil).ApproveFollowRequest), arg0, arg1)
+}
+
+// BlockUser mocks base method.
+func (m *MockUserRepository) BlockUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUser indicates an expected call of BlockUser.
+func (mr *MockUserRepositoryMockRecorder) BlockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUser", reflect.TypeOf((*MockUserRepository)(nil).BlockUser), arg0, arg1)
+}
+
+// CreateMutedWord mocks base method.
+func (m *MockUserRepository) CreateMutedWord(arg0 uint64, arg1 models.CreateMutedWordDTO) (*models.ReadMutedWordDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMutedWord", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadMutedWordDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMutedWord indicates an expected call of CreateMutedWord.
+func (mr *MockUserRepositoryMockRecorder) CreateMutedWord(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMutedWord", reflect.TypeOf((*MockUserRepository)(nil).CreateMutedWord), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepository) CreateUser(arg0 models.CreateUserDTO) (*models.ReadAuthUserDataDTO, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +92,20 @@ func (mr *MockUserRepositoryMockRecorder) CreateUser(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), arg0)
 }
 
+// DeleteMutedWord mocks base method.
+func (m *MockUserRepository) DeleteMutedWord(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMutedWord", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMutedWord indicates an expected call of DeleteMutedWord.
+func (mr *MockUserRepositoryMockRecorder) DeleteMutedWord(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMutedWord", reflect.TypeOf((*MockUserRepository)(nil).DeleteMutedWord), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserRepository) DeleteUser(arg0 uint64) error {
 	m.ctrl.T.Helper()
@@ -63,34 +120,154 @@ func (mr *MockUserRepositoryMockRecorder) DeleteUser(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), arg0)
 }
 
+// FollowUser mocks base method.
+func (m *MockUserRepository) FollowUser(arg0, arg1 uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowUser", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowUser indicates an expected call of FollowUser.
+func (mr *MockUserRepositoryMockRecorder) FollowUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowUser", reflect.TypeOf((*MockUserRepository)(nil).FollowUser), arg0, arg1)
+}
+
 // GetAllUsers mocks base method.
-func (m *MockUserRepository) GetAllUsers(arg0, arg1 uint64) ([]models.ReadUserDTO, error) {
+func (m *MockUserRepository) GetAllUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUsers", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetAllUsers", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.ReadUserDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllUsers indicates an expected call of GetAllUsers.
-func (mr *MockUserRepositoryMockRecorder) GetAllUsers(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) GetAllUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUserRepository)(nil).GetAllUsers), arg0, arg1, arg2)
+}
+
+// GetBlockedUsers mocks base method.
+func (m *MockUserRepository) GetBlockedUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedUsers indicates an expected call of GetBlockedUsers.
+func (mr *MockUserRepositoryMockRecorder) GetBlockedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedUsers", reflect.TypeOf((*MockUserRepository)(nil).GetBlockedUsers), arg0, arg1, arg2)
+}
+
+// GetFollowRequests mocks base method.
+func (m *MockUserRepository) GetFollowRequests(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowRequests", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowRequests indicates an expected call of GetFollowRequests.
+func (mr *MockUserRepositoryMockRecorder) GetFollowRequests(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowRequests", reflect.TypeOf((*MockUserRepository)(nil).GetFollowRequests), arg0, arg1, arg2)
+}
+
+// GetFollows mocks base method.
+func (m *MockUserRepository) GetFollows(arg0 models.FilterFollowDTO) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollows", arg0)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollows indicates an expected call of GetFollows.
+func (mr *MockUserRepositoryMockRecorder) GetFollows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollows", reflect.TypeOf((*MockUserRepository)(nil).GetFollows), arg0)
+}
+
+// GetMutedUsers mocks base method.
+func (m *MockUserRepository) GetMutedUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutedUsers indicates an expected call of GetMutedUsers.
+func (mr *MockUserRepositoryMockRecorder) GetMutedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutedUsers", reflect.TypeOf((*MockUserRepository)(nil).GetMutedUsers), arg0, arg1, arg2)
+}
+
+// GetMutedWords mocks base method.
+func (m *MockUserRepository) GetMutedWords(arg0 uint64) ([]models.ReadMutedWordDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutedWords", arg0)
+	ret0, _ := ret[0].([]models.ReadMutedWordDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutedWords indicates an expected call of GetMutedWords.
+func (mr *MockUserRepositoryMockRecorder) GetMutedWords(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutedWords", reflect.TypeOf((*MockUserRepository)(nil).GetMutedWords), arg0)
+}
+
+// GetProfileByUserName mocks base method.
+func (m *MockUserRepository) GetProfileByUserName(arg0 string, arg1 uint64) (*models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfileByUserName", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfileByUserName indicates an expected call of GetProfileByUserName.
+func (mr *MockUserRepositoryMockRecorder) GetProfileByUserName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfileByUserName", reflect.TypeOf((*MockUserRepository)(nil).GetProfileByUserName), arg0, arg1)
+}
+
+// GetRecommendedUsers mocks base method.
+func (m *MockUserRepository) GetRecommendedUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecommendedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecommendedUsers indicates an expected call of GetRecommendedUsers.
+func (mr *MockUserRepositoryMockRecorder) GetRecommendedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUserRepository)(nil).GetAllUsers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecommendedUsers", reflect.TypeOf((*MockUserRepository)(nil).GetRecommendedUsers), arg0, arg1, arg2)
 }
 
 // GetUserByID mocks base method.
-func (m *MockUserRepository) GetUserByID(arg0 uint64) (*models.ReadUserDTO, error) {
+func (m *MockUserRepository) GetUserByID(arg0, arg1 uint64) (*models.ReadUserDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", arg0)
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
 	ret0, _ := ret[0].(*models.ReadUserDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockUserRepositoryMockRecorder) GetUserByID(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), arg0, arg1)
 }
 
 // GetUserByUserName mocks base method.
@@ -108,6 +285,106 @@ func (mr *MockUserRepositoryMockRecorder) GetUserByUserName(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUserName", reflect.TypeOf((*MockUserRepository)(nil).GetUserByUserName), arg0)
 }
 
+// GetUsersPage mocks base method.
+func (m *MockUserRepository) GetUsersPage(arg0 uint64, arg1 *models.Cursor, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersPage indicates an expected call of GetUsersPage.
+func (mr *MockUserRepositoryMockRecorder) GetUsersPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersPage", reflect.TypeOf((*MockUserRepository)(nil).GetUsersPage), arg0, arg1, arg2)
+}
+
+// MuteUser mocks base method.
+func (m *MockUserRepository) MuteUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteUser indicates an expected call of MuteUser.
+func (mr *MockUserRepositoryMockRecorder) MuteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteUser", reflect.TypeOf((*MockUserRepository)(nil).MuteUser), arg0, arg1)
+}
+
+// RejectFollowRequest mocks base method.
+func (m *MockUserRepository) RejectFollowRequest(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectFollowRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectFollowRequest indicates an expected call of RejectFollowRequest.
+func (mr *MockUserRepositoryMockRecorder) RejectFollowRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectFollowRequest", reflect.TypeOf((*MockUserRepository)(nil).RejectFollowRequest), arg0, arg1)
+}
+
+// SearchUsers mocks base method.
+func (m *MockUserRepository) SearchUsers(arg0 uint64, arg1 string, arg2, arg3 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserRepositoryMockRecorder) SearchUsers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserRepository)(nil).SearchUsers), arg0, arg1, arg2, arg3)
+}
+
+// UnblockUser mocks base method.
+func (m *MockUserRepository) UnblockUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnblockUser indicates an expected call of UnblockUser.
+func (mr *MockUserRepositoryMockRecorder) UnblockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockUser", reflect.TypeOf((*MockUserRepository)(nil).UnblockUser), arg0, arg1)
+}
+
+// UnfollowUser mocks base method.
+func (m *MockUserRepository) UnfollowUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfollowUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnfollowUser indicates an expected call of UnfollowUser.
+func (mr *MockUserRepositoryMockRecorder) UnfollowUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfollowUser", reflect.TypeOf((*MockUserRepository)(nil).UnfollowUser), arg0, arg1)
+}
+
+// UnmuteUser mocks base method.
+func (m *MockUserRepository) UnmuteUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteUser indicates an expected call of UnmuteUser.
+func (mr *MockUserRepositoryMockRecorder) UnmuteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteUser", reflect.TypeOf((*MockUserRepository)(nil).UnmuteUser), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserRepository) UpdateUser(arg0 uint64, arg1 models.UpdateUserDTO) (*models.ReadUserDTO, error) {
 	m.ctrl.T.Helper()
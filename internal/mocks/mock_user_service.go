@@ -34,6 +34,63 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ApproveFollowRequest mocks base method.
+func (m *MockUserService) ApproveFollowRequest(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveFollowRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveFollowRequest indicates an expected call of ApproveFollowRequest.
+func (mr *MockUserServiceMockRecorder) ApproveFollowRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveFollowRequest", reflect.TypeOf((*MockUserService)(nil).ApproveFollowRequest), arg0, arg1)
+}
+
+// BlockUser mocks base method.
+func (m *MockUserService) BlockUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUser indicates an expected call of BlockUser.
+func (mr *MockUserServiceMockRecorder) BlockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUser", reflect.TypeOf((*MockUserService)(nil).BlockUser), arg0, arg1)
+}
+
+// CreateMutedWord mocks base method.
+func (m *MockUserService) CreateMutedWord(arg0 uint64, arg1 models.CreateMutedWordDTO) (*models.ReadMutedWordDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMutedWord", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadMutedWordDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMutedWord indicates an expected call of CreateMutedWord.
+func (mr *MockUserServiceMockRecorder) CreateMutedWord(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMutedWord", reflect.TypeOf((*MockUserService)(nil).CreateMutedWord), arg0, arg1)
+}
+
+// DeleteMutedWord mocks base method.
+func (m *MockUserService) DeleteMutedWord(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMutedWord", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMutedWord indicates an expected call of DeleteMutedWord.
+func (mr *MockUserServiceMockRecorder) DeleteMutedWord(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMutedWord", reflect.TypeOf((*MockUserService)(nil).DeleteMutedWord), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserService) DeleteUser(arg0 uint64) error {
 	m.ctrl.T.Helper()
@@ -48,34 +105,269 @@ func (mr *MockUserServiceMockRecorder) DeleteUser(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserService)(nil).DeleteUser), arg0)
 }
 
+// FollowUser mocks base method.
+func (m *MockUserService) FollowUser(arg0, arg1 uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowUser", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowUser indicates an expected call of FollowUser.
+func (mr *MockUserServiceMockRecorder) FollowUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowUser", reflect.TypeOf((*MockUserService)(nil).FollowUser), arg0, arg1)
+}
+
 // GetAllUsers mocks base method.
-func (m *MockUserService) GetAllUsers(arg0, arg1 uint64) ([]models.ReadUserDTO, error) {
+func (m *MockUserService) GetAllUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUsers", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetAllUsers", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.ReadUserDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllUsers indicates an expected call of GetAllUsers.
-func (mr *MockUserServiceMockRecorder) GetAllUsers(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetAllUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUserService)(nil).GetAllUsers), arg0, arg1, arg2)
+}
+
+// GetBlockedUsers mocks base method.
+func (m *MockUserService) GetBlockedUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedUsers indicates an expected call of GetBlockedUsers.
+func (mr *MockUserServiceMockRecorder) GetBlockedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedUsers", reflect.TypeOf((*MockUserService)(nil).GetBlockedUsers), arg0, arg1, arg2)
+}
+
+// GetFollowRequests mocks base method.
+func (m *MockUserService) GetFollowRequests(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowRequests", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowRequests indicates an expected call of GetFollowRequests.
+func (mr *MockUserServiceMockRecorder) GetFollowRequests(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowRequests", reflect.TypeOf((*MockUserService)(nil).GetFollowRequests), arg0, arg1, arg2)
+}
+
+// GetFollows mocks base method.
+func (m *MockUserService) GetFollows(arg0 models.FilterFollowDTO) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollows", arg0)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollows indicates an expected call of GetFollows.
+func (mr *MockUserServiceMockRecorder) GetFollows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollows", reflect.TypeOf((*MockUserService)(nil).GetFollows), arg0)
+}
+
+// GetFollowsPage mocks base method.
+func (m *MockUserService) GetFollowsPage(arg0 models.FilterFollowDTO) (*models.UserPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowsPage", arg0)
+	ret0, _ := ret[0].(*models.UserPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowsPage indicates an expected call of GetFollowsPage.
+func (mr *MockUserServiceMockRecorder) GetFollowsPage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowsPage", reflect.TypeOf((*MockUserService)(nil).GetFollowsPage), arg0)
+}
+
+// GetMutedUsers mocks base method.
+func (m *MockUserService) GetMutedUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutedUsers indicates an expected call of GetMutedUsers.
+func (mr *MockUserServiceMockRecorder) GetMutedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUserService)(nil).GetAllUsers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutedUsers", reflect.TypeOf((*MockUserService)(nil).GetMutedUsers), arg0, arg1, arg2)
+}
+
+// GetMutedWords mocks base method.
+func (m *MockUserService) GetMutedWords(arg0 uint64) ([]models.ReadMutedWordDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutedWords", arg0)
+	ret0, _ := ret[0].([]models.ReadMutedWordDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutedWords indicates an expected call of GetMutedWords.
+func (mr *MockUserServiceMockRecorder) GetMutedWords(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutedWords", reflect.TypeOf((*MockUserService)(nil).GetMutedWords), arg0)
+}
+
+// GetRecommendedUsers mocks base method.
+func (m *MockUserService) GetRecommendedUsers(arg0, arg1, arg2 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecommendedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecommendedUsers indicates an expected call of GetRecommendedUsers.
+func (mr *MockUserServiceMockRecorder) GetRecommendedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecommendedUsers", reflect.TypeOf((*MockUserService)(nil).GetRecommendedUsers), arg0, arg1, arg2)
 }
 
 // GetUserByID mocks base method.
-func (m *MockUserService) GetUserByID(arg0 uint64) (*models.ReadUserDTO, error) {
+func (m *MockUserService) GetUserByID(arg0, arg1 uint64) (*models.ReadUserDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", arg0)
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
 	ret0, _ := ret[0].(*models.ReadUserDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockUserServiceMockRecorder) GetUserByID(arg0 interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserService)(nil).GetUserByID), arg0, arg1)
+}
+
+// GetUserByUserName mocks base method.
+func (m *MockUserService) GetUserByUserName(arg0 string, arg1 uint64) (*models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUserName", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUserName indicates an expected call of GetUserByUserName.
+func (mr *MockUserServiceMockRecorder) GetUserByUserName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUserName", reflect.TypeOf((*MockUserService)(nil).GetUserByUserName), arg0, arg1)
+}
+
+// GetUsersPage mocks base method.
+func (m *MockUserService) GetUsersPage(arg0 uint64, arg1 *models.Cursor, arg2 uint64) (*models.UserPageDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.UserPageDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersPage indicates an expected call of GetUsersPage.
+func (mr *MockUserServiceMockRecorder) GetUsersPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersPage", reflect.TypeOf((*MockUserService)(nil).GetUsersPage), arg0, arg1, arg2)
+}
+
+// MuteUser mocks base method.
+func (m *MockUserService) MuteUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteUser indicates an expected call of MuteUser.
+func (mr *MockUserServiceMockRecorder) MuteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteUser", reflect.TypeOf((*MockUserService)(nil).MuteUser), arg0, arg1)
+}
+
+// RejectFollowRequest mocks base method.
+func (m *MockUserService) RejectFollowRequest(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectFollowRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectFollowRequest indicates an expected call of RejectFollowRequest.
+func (mr *MockUserServiceMockRecorder) RejectFollowRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectFollowRequest", reflect.TypeOf((*MockUserService)(nil).RejectFollowRequest), arg0, arg1)
+}
+
+// SearchUsers mocks base method.
+func (m *MockUserService) SearchUsers(arg0 uint64, arg1 string, arg2, arg3 uint64) ([]models.ReadUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadUserDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserServiceMockRecorder) SearchUsers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserService)(nil).SearchUsers), arg0, arg1, arg2, arg3)
+}
+
+// UnblockUser mocks base method.
+func (m *MockUserService) UnblockUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnblockUser indicates an expected call of UnblockUser.
+func (mr *MockUserServiceMockRecorder) UnblockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockUser", reflect.TypeOf((*MockUserService)(nil).UnblockUser), arg0, arg1)
+}
+
+// UnfollowUser mocks base method.
+func (m *MockUserService) UnfollowUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfollowUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnfollowUser indicates an expected call of UnfollowUser.
+func (mr *MockUserServiceMockRecorder) UnfollowUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfollowUser", reflect.TypeOf((*MockUserService)(nil).UnfollowUser), arg0, arg1)
+}
+
+// UnmuteUser mocks base method.
+func (m *MockUserService) UnmuteUser(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteUser indicates an expected call of UnmuteUser.
+func (mr *MockUserServiceMockRecorder) UnmuteUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserService)(nil).GetUserByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteUser", reflect.TypeOf((*MockUserService)(nil).UnmuteUser), arg0, arg1)
 }
 
 // UpdateUser mocks base method.
@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/repository (interfaces: WebhookRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDeliveries mocks base method.
+func (m *MockWebhookRepository) ClaimDeliveries(arg0 int, arg1 time.Time) ([]models.PendingWebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]models.PendingWebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDeliveries indicates an expected call of ClaimDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDeliveries), arg0, arg1)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookRepository) CreateWebhook(arg0 models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) CreateWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).CreateWebhook), arg0)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookRepository) DeleteWebhook(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookRepository) GetDeliveries(arg0, arg1, arg2, arg3 uint64) ([]models.ReadWebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadWebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetDeliveries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetDeliveries), arg0, arg1, arg2, arg3)
+}
+
+// GetWebhookByID mocks base method.
+func (m *MockWebhookRepository) GetWebhookByID(arg0, arg1 uint64) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookByID indicates an expected call of GetWebhookByID.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookByID", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookByID), arg0, arg1)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookRepository) GetWebhooks(arg0 uint64) ([]models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0)
+	ret0, _ := ret[0].([]models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhooks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhooks), arg0)
+}
+
+// RecordAttempt mocks base method.
+func (m *MockWebhookRepository) RecordAttempt(arg0 models.WebhookAttemptDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt.
+func (mr *MockWebhookRepositoryMockRecorder) RecordAttempt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*MockWebhookRepository)(nil).RecordAttempt), arg0)
+}
+
+// Redeliver mocks base method.
+func (m *MockWebhookRepository) Redeliver(arg0, arg1, arg2 uint64) (*models.ReadWebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadWebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockWebhookRepositoryMockRecorder) Redeliver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhookRepository)(nil).Redeliver), arg0, arg1, arg2)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockWebhookRepository) UpdateWebhook(arg0, arg1 uint64, arg2 models.UpdateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateWebhook), arg0, arg1, arg2)
+}
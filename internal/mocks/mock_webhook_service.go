@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/shekshuev/gophertalk-backend/internal/service (interfaces: WebhookService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookService) CreateWebhook(arg0 models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookServiceMockRecorder) CreateWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookService)(nil).CreateWebhook), arg0)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookService) DeleteWebhook(arg0, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookServiceMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookService)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookService) GetDeliveries(arg0, arg1, arg2, arg3 uint64) ([]models.ReadWebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ReadWebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookServiceMockRecorder) GetDeliveries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookService)(nil).GetDeliveries), arg0, arg1, arg2, arg3)
+}
+
+// GetWebhookByID mocks base method.
+func (m *MockWebhookService) GetWebhookByID(arg0, arg1 uint64) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookByID indicates an expected call of GetWebhookByID.
+func (mr *MockWebhookServiceMockRecorder) GetWebhookByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookByID", reflect.TypeOf((*MockWebhookService)(nil).GetWebhookByID), arg0, arg1)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookService) GetWebhooks(arg0 uint64) ([]models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0)
+	ret0, _ := ret[0].([]models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookServiceMockRecorder) GetWebhooks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookService)(nil).GetWebhooks), arg0)
+}
+
+// Redeliver mocks base method.
+func (m *MockWebhookService) Redeliver(arg0, arg1, arg2 uint64) (*models.ReadWebhookDeliveryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadWebhookDeliveryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockWebhookServiceMockRecorder) Redeliver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhookService)(nil).Redeliver), arg0, arg1, arg2)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockWebhookService) UpdateWebhook(arg0, arg1 uint64, arg2 models.UpdateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReadWebhookDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockWebhookServiceMockRecorder) UpdateWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockWebhookService)(nil).UpdateWebhook), arg0, arg1, arg2)
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+const (
+	ChatRoleAdmin  = "admin"
+	ChatRoleMember = "member"
+)
+
+// Messages other than text are system messages, written by the server when
+// a group changes. They name the member who made the change as the sender
+// and the member it was about as the subject.
+const (
+	MessageTypeText          = "text"
+	MessageTypeGroupCreated  = "group_created"
+	MessageTypeGroupRenamed  = "group_renamed"
+	MessageTypeMemberAdded   = "member_added"
+	MessageTypeMemberRemoved = "member_removed"
+	MessageTypeMemberLeft    = "member_left"
+)
+
+type CreateChatDTO struct {
+	UserID uint64 `json:"user_id" validate:"required,gt=0"`
+}
+
+// CreateGroupChatDTO starts a group with the creator as its admin and the
+// users in UserIDs as members.
+type CreateGroupChatDTO struct {
+	CreatorID uint64
+	Name      string   `json:"name" validate:"required,max=100"`
+	UserIDs   []uint64 `json:"user_ids" validate:"required,min=1,unique,dive,gt=0"`
+}
+
+type UpdateChatDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddChatMemberDTO struct {
+	UserID uint64 `json:"user_id" validate:"required,gt=0"`
+}
+
+type UpdateChatMemberDTO struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+// ReadChatDTO is a chat as seen by one of its members. UnreadCount counts
+// the messages of the others after the member's read position; MutedAt is set
+// when the member muted the chat.
+type ReadChatDTO struct {
+	ID            uint64              `json:"id"`
+	IsGroup       bool                `json:"is_group"`
+	Name          string              `json:"name,omitempty"`
+	Members       []ReadChatMemberDTO `json:"members"`
+	LastMessage   *ReadMessageDTO     `json:"last_message,omitempty"`
+	UnreadCount   uint64              `json:"unread_count"`
+	MutedAt       *time.Time          `json:"muted_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	LastMessageAt time.Time           `json:"last_message_at"`
+}
+
+// ReadChatMemberDTO carries the read receipt of a member: the last message
+// they have read and when.
+type ReadChatMemberDTO struct {
+	User              ReadUserDTO `json:"user"`
+	Role              string      `json:"role"`
+	LastReadMessageID uint64      `json:"last_read_message_id"`
+	ReadAt            *time.Time  `json:"read_at,omitempty"`
+}
+
+type ChatPageDTO struct {
+	Items      []ReadChatDTO `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	PrevCursor string        `json:"prev_cursor,omitempty"`
+}
+
+type CreateMessageDTO struct {
+	ChatID   uint64
+	SenderID uint64
+	Text     string `json:"text" validate:"required,max=1000"`
+}
+
+// ReadMessageDTO is a message in a chat. An unsent message keeps its place
+// with UnsentAt set and its text removed. IsRead tells whether every other
+// member has read it. The text of a system message is the group name when it
+// was created or renamed and empty otherwise.
+type ReadMessageDTO struct {
+	ID        uint64     `json:"id"`
+	ChatID    uint64     `json:"chat_id"`
+	SenderID  uint64     `json:"sender_id"`
+	Type      string     `json:"type"`
+	SubjectID *uint64    `json:"subject_id,omitempty"`
+	Text      string     `json:"text"`
+	IsRead    bool       `json:"is_read"`
+	CreatedAt time.Time  `json:"created_at"`
+	UnsentAt  *time.Time `json:"unsent_at,omitempty"`
+}
+
+type MessagePageDTO struct {
+	Items      []ReadMessageDTO `json:"items"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	PrevCursor string           `json:"prev_cursor,omitempty"`
+}
+
+// MarkChatReadDTO marks the messages up to MessageID read, or the whole chat when
+// it is not set.
+type MarkChatReadDTO struct {
+	MessageID uint64 `json:"message_id,omitempty"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type CreateListDTO struct {
+	UserID      uint64
+	Name        string `json:"name" validate:"required,min=1,max=50"`
+	Description string `json:"description,omitempty" validate:"max=160"`
+	Private     bool   `json:"private,omitempty"`
+}
+
+type UpdateListDTO struct {
+	Name        string  `json:"name,omitempty" validate:"omitempty,min=1,max=50"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=160"`
+	Private     *bool   `json:"private,omitempty"`
+}
+
+type ReadListDTO struct {
+	ID               uint64    `json:"id"`
+	UserID           uint64    `json:"user_id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Private          bool      `json:"private"`
+	MembersCount     uint      `json:"members_count"`
+	SubscribersCount uint      `json:"subscribers_count"`
+	IsSubscribed     bool      `json:"is_subscribed"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// FilterListDTO selects the lists owned by OwnerID, the lists SubscriberID
+// subscribed to, or the lists MemberID was added to. Lists the viewer may not
+// see are always left out.
+type FilterListDTO struct {
+	ViewerID     uint64
+	OwnerID      uint64
+	SubscriberID uint64
+	MemberID     uint64
+	Limit        uint64
+	Offset       uint64
+}
@@ -0,0 +1,41 @@
+package models
+
+type UploadMediaDTO struct {
+	UserID  uint64
+	Data    []byte
+	AltText string `validate:"max=1000"`
+}
+
+type CreateMediaDTO struct {
+	UserID     uint64
+	StorageKey string
+	URL        string
+	MimeType   string
+	SizeBytes  int64
+	Width      int
+	Height     int
+	AltText    string
+}
+
+type UpdateMediaDTO struct {
+	AltText string `json:"alt_text" validate:"max=1000"`
+}
+
+type ReadMediaVariantDTO struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type ReadMediaDTO struct {
+	ID       uint64                `json:"id"`
+	URL      string                `json:"url"`
+	MimeType string                `json:"mime_type"`
+	Width    int                   `json:"width"`
+	Height   int                   `json:"height"`
+	AltText  string                `json:"alt_text"`
+	Blurhash string                `json:"blurhash,omitempty"`
+	Variants []ReadMediaVariantDTO `json:"variants,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+const (
+	NotificationTypeLike          = "like"
+	NotificationTypeReply         = "reply"
+	NotificationTypeMention       = "mention"
+	NotificationTypeFollow        = "follow"
+	NotificationTypeFollowRequest = "follow_request"
+)
+
+// NotificationTypes lists every notification type a user can turn off.
+var NotificationTypes = []string{
+	NotificationTypeLike,
+	NotificationTypeReply,
+	NotificationTypeMention,
+	NotificationTypeFollow,
+	NotificationTypeFollowRequest,
+}
+
+// ReadNotificationDTO groups the events of one type about one post, or about
+// the user when PostID is nil, that arrived while the notification was
+// unread. Actors holds the latest few of ActorsCount users behind them.
+type ReadNotificationDTO struct {
+	ID          uint64        `json:"id"`
+	Type        string        `json:"type"`
+	PostID      *uint64       `json:"post_id,omitempty"`
+	Actors      []ReadUserDTO `json:"actors"`
+	ActorsCount uint          `json:"actors_count"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	ReadAt      *time.Time    `json:"read_at,omitempty"`
+}
+
+type NotificationPageDTO struct {
+	Items      []ReadNotificationDTO `json:"items"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+	PrevCursor string                `json:"prev_cursor,omitempty"`
+}
+
+type UnreadCountDTO struct {
+	Count uint64 `json:"count"`
+}
+
+// NotificationPreferencesDTO tells for every notification type whether the
+// user wants to receive it.
+type NotificationPreferencesDTO map[string]bool
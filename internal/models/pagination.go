@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Cursor is the keyset position a page starts after. Backward pages walk
+// towards newer items, used for prev links.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint64    `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+type PostPageDTO struct {
+	Items      []ReadPostDTO `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	PrevCursor string        `json:"prev_cursor,omitempty"`
+}
+
+type UserPageDTO struct {
+	Items      []ReadUserDTO `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	PrevCursor string        `json:"prev_cursor,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type CreatePollDTO struct {
+	Options  []string  `json:"options" validate:"required,min=2,max=4,unique,dive,required,max=100"`
+	Multiple bool      `json:"multiple"`
+	ClosesAt time.Time `json:"closes_at" validate:"required"`
+}
+
+type VotePollDTO struct {
+	OptionIDs []uint64 `json:"option_ids" validate:"required,min=1,max=4,unique,dive,gt=0"`
+}
+
+type ReadPollOptionDTO struct {
+	ID         uint64 `json:"id"`
+	Text       string `json:"text"`
+	VotesCount *uint  `json:"votes_count,omitempty"`
+}
+
+type ReadPollDTO struct {
+	ID          uint64              `json:"id"`
+	Multiple    bool                `json:"multiple"`
+	ClosesAt    time.Time           `json:"closes_at"`
+	Closed      bool                `json:"closed"`
+	VotersCount *uint               `json:"voters_count,omitempty"`
+	Options     []ReadPollOptionDTO `json:"options"`
+	OwnVotes    []uint64            `json:"own_votes,omitempty"`
+}
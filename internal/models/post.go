@@ -1,11 +1,70 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/search"
+)
+
+const (
+	PostStateDraft     = "draft"
+	PostStateScheduled = "scheduled"
+	PostStatePublished = "published"
+)
+
+const (
+	PostVisibilityPublic    = "public"
+	PostVisibilityFollowers = "followers"
+	PostVisibilityMentioned = "mentioned"
+)
+
+const (
+	PostSortRelevance = "relevance"
+	PostSortRecent    = "recent"
+)
+
+const (
+	ReplyPolicyEveryone  = "everyone"
+	ReplyPolicyFollowing = "following"
+	ReplyPolicyMentioned = "mentioned"
+)
 
 type CreatePostDTO struct {
-	Text      string  `json:"text" validate:"required,min=0,max=280"`
-	ReplyToID *uint64 `json:"reply_to_id,omitempty" validate:"omitempty,gt=0"`
-	UserID    uint64
+	Text        string  `json:"text" validate:"required,min=0,max=280"`
+	ReplyToID   *uint64 `json:"reply_to_id,omitempty" validate:"omitempty,gt=0"`
+	UserID      uint64
+	MediaIDs    []uint64           `json:"media_ids,omitempty" validate:"omitempty,max=4,unique,dive,gt=0"`
+	Mentions    []CreateMentionDTO `json:"-"`
+	Poll        *CreatePollDTO     `json:"poll,omitempty"`
+	Draft       bool               `json:"draft,omitempty"`
+	PublishAt   *time.Time         `json:"publish_at,omitempty"`
+	State       string             `json:"-"`
+	Visibility  string             `json:"visibility,omitempty" validate:"omitempty,oneof=public followers mentioned"`
+	ReplyPolicy string             `json:"reply_policy,omitempty" validate:"omitempty,oneof=everyone following mentioned"`
+	Language    string             `json:"language,omitempty" validate:"omitempty,oneof=en ru de fr es it pt nl"`
+}
+
+type UpdateDraftDTO struct {
+	Text      string             `json:"text" validate:"required,min=0,max=280"`
+	PublishAt *time.Time         `json:"publish_at,omitempty"`
+	Mentions  []CreateMentionDTO `json:"-"`
+	State     string             `json:"-"`
+}
+
+type CreateMentionDTO struct {
+	UserName string
+	Offset   int
+	Length   int
+}
+
+const EntityTypeMention = "mention"
+
+type ReadPostEntityDTO struct {
+	Type     string `json:"type"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	UserID   uint64 `json:"user_id,omitempty"`
+	UserName string `json:"user_name,omitempty"`
 }
 
 type ReadPostUserDTO struct {
@@ -17,23 +76,41 @@ type ReadPostUserDTO struct {
 }
 
 type ReadPostDTO struct {
-	ID           uint64           `json:"id"`
-	Text         string           `json:"text"`
-	ReplyToID    *uint64          `json:"reply_to_id,omitempty"`
-	User         *ReadPostUserDTO `json:"user,omitempty"`
-	CreatedAt    time.Time        `json:"created_at"`
-	LikesCount   uint             `json:"likes_count"`
-	ViewsCount   uint             `json:"views_count"`
-	RepliesCount uint             `json:"replies_count"`
-	UserLiked    bool             `json:"user_liked"`
-	UserViewed   bool             `json:"user_viewed"`
+	ID           uint64              `json:"id"`
+	Text         string              `json:"text"`
+	ReplyToID    *uint64             `json:"reply_to_id,omitempty"`
+	User         *ReadPostUserDTO    `json:"user,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	LikesCount   uint                `json:"likes_count"`
+	ViewsCount   uint                `json:"views_count"`
+	RepliesCount uint                `json:"replies_count"`
+	UserLiked    bool                `json:"user_liked"`
+	UserViewed   bool                `json:"user_viewed"`
+	Entities     []ReadPostEntityDTO `json:"entities,omitempty"`
+	Media        []ReadMediaDTO      `json:"media,omitempty"`
+	Poll         *ReadPollDTO        `json:"poll,omitempty"`
+	Pinned       bool                `json:"pinned,omitempty"`
+	State        string              `json:"state,omitempty"`
+	PublishAt    *time.Time          `json:"publish_at,omitempty"`
+	Visibility   string              `json:"visibility,omitempty"`
+	ReplyPolicy  string              `json:"reply_policy,omitempty"`
+	CanReply     bool                `json:"can_reply"`
+	Highlight    string              `json:"highlight,omitempty"`
 }
 
 type FilterPostDTO struct {
-	Search    string `json:"search,omitempty"`
-	OwnerID   uint64 `json:"owner_id,omitempty"`
-	UserID    uint64
-	ReplyToID uint64 `json:"reply_to_id,omitempty"`
-	Limit     uint64 `json:"limit" validate:"required,min=0,max=100"`
-	Offset    uint64 `json:"offset" validate:"required,gte=0"`
+	PostID      uint64        `json:"-"`
+	Cursor      *Cursor       `json:"-"`
+	Paged       bool          `json:"-"`
+	Search      string        `json:"search,omitempty"`
+	Query       *search.Query `json:"-"`
+	Sort        string        `json:"sort,omitempty"`
+	OwnerID     uint64        `json:"owner_id,omitempty"`
+	UserID      uint64
+	ReplyToID   uint64 `json:"reply_to_id,omitempty"`
+	MentionedID uint64 `json:"mentioned_id,omitempty"`
+	Home        bool   `json:"-"`
+	ListID      uint64 `json:"-"`
+	Limit       uint64 `json:"limit" validate:"required,min=0,max=100"`
+	Offset      uint64 `json:"offset" validate:"required,gte=0"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import "encoding/json"
+
+const (
+	SocketCommandSubscribe   = "subscribe"
+	SocketCommandUnsubscribe = "unsubscribe"
+	SocketCommandTyping      = "typing"
+	SocketCommandAuth        = "auth"
+	SocketCommandPing        = "ping"
+)
+
+const (
+	SocketMessageAck   = "ack"
+	SocketMessageError = "error"
+	SocketMessagePong  = "pong"
+	SocketMessagePing  = "ping"
+)
+
+// SocketCommandDTO is a message sent by the client over the WebSocket. Ref is
+// echoed in the reply so the client can match them.
+type SocketCommandDTO struct {
+	Ref     string   `json:"ref,omitempty"`
+	Type    string   `json:"type"`
+	PostIDs []uint64 `json:"post_ids,omitempty"`
+	UserIDs []uint64 `json:"user_ids,omitempty"`
+	ChatID  uint64   `json:"chat_id,omitempty"`
+	Token   string   `json:"token,omitempty"`
+}
+
+// SocketMessageDTO is a message sent by the server over the WebSocket: a
+// reply to a command or an event from a subscribed topic.
+type SocketMessageDTO struct {
+	ID    uint64          `json:"id,omitempty"`
+	Ref   string          `json:"ref,omitempty"`
+	Type  string          `json:"type"`
+	Topic string          `json:"topic,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// StreamPostDTO announces a post that was added to the home timeline.
+type StreamPostDTO struct {
+	PostID uint64 `json:"post_id"`
+	UserID uint64 `json:"user_id"`
+}
+
+// StreamNotificationDTO announces a notification that was created or gained
+// an actor.
+type StreamNotificationDTO struct {
+	ID   uint64 `json:"id"`
+	Type string `json:"type"`
+}
+
+// StreamCountersDTO carries the current counters of a post.
+type StreamCountersDTO struct {
+	PostID       uint64 `json:"post_id"`
+	LikesCount   uint   `json:"likes_count"`
+	ViewsCount   uint   `json:"views_count"`
+	RepliesCount uint   `json:"replies_count"`
+}
+
+// StreamTypingDTO tells the members of a chat who is typing in it.
+type StreamTypingDTO struct {
+	ChatID uint64 `json:"chat_id"`
+	UserID uint64 `json:"user_id"`
+}
+
+// StreamMessageDTO announces a new message to the members of its chat. Muted
+// tells the recipient that they muted the chat, so it should not alert.
+type StreamMessageDTO struct {
+	ReadMessageDTO
+	Muted bool `json:"muted"`
+}
+
+// StreamChatReadDTO announces that a member read the chat up to a message.
+type StreamChatReadDTO struct {
+	ChatID            uint64 `json:"chat_id"`
+	UserID            uint64 `json:"user_id"`
+	LastReadMessageID uint64 `json:"last_read_message_id"`
+}
+
+// StreamMessageUnsentDTO announces that the sender took a message back.
+type StreamMessageUnsentDTO struct {
+	ChatID    uint64 `json:"chat_id"`
+	MessageID uint64 `json:"message_id"`
+}
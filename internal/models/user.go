@@ -15,13 +15,40 @@ type CreateUserDTO struct {
 }
 
 type ReadUserDTO struct {
-	ID        uint64    `json:"id"`
-	UserName  string    `json:"user_name"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Status    uint8     `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint64        `json:"id"`
+	UserName  string        `json:"user_name"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	Status    uint8         `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Avatar    *ReadMediaDTO `json:"avatar,omitempty"`
+
+	FollowersCount uint       `json:"followers_count"`
+	FollowingCount uint       `json:"following_count"`
+	IsFollowedByMe bool       `json:"is_followed_by_me"`
+	FollowedAt     *time.Time `json:"followed_at,omitempty"`
+	BlockedAt      *time.Time `json:"blocked_at,omitempty"`
+	MutedAt        *time.Time `json:"muted_at,omitempty"`
+
+	Protected         bool       `json:"protected"`
+	IsFollowRequested bool       `json:"is_follow_requested"`
+	RequestedAt       *time.Time `json:"requested_at,omitempty"`
+
+	MessagesFromFollowersOnly bool `json:"messages_from_followers_only"`
+
+	RecommendationReason string `json:"recommendation_reason,omitempty"`
+}
+
+// FilterFollowDTO selects one side of a user's follow graph: the users who
+// follow UserID, or with Following set the users UserID follows.
+type FilterFollowDTO struct {
+	UserID    uint64
+	ViewerID  uint64
+	Following bool
+	Cursor    *Cursor
+	Limit     uint64
+	Offset    uint64
 }
 
 type ReadAuthUserDataDTO struct {
@@ -38,4 +65,22 @@ type UpdateUserDTO struct {
 	PasswordHash    string
 	FirstName       string `json:"first_name" validate:"omitempty,min=1,max=30,alphaunicode"`
 	LastName        string `json:"last_name" validate:"omitempty,min=1,max=30,alphaunicode"`
+	AvatarMediaID   uint64 `json:"avatar_media_id,omitempty" validate:"omitempty,gt=0"`
+	Protected       *bool  `json:"protected,omitempty"`
+
+	MessagesFromFollowersOnly *bool `json:"messages_from_followers_only,omitempty"`
+}
+
+// CreateMutedWordDTO mutes a keyword or, with a leading #, a hashtag until
+// ExpiresAt, or for good when it is not set.
+type CreateMutedWordDTO struct {
+	Word      string     `json:"word" validate:"required,max=100"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ReadMutedWordDTO struct {
+	ID        uint64     `json:"id"`
+	Word      string     `json:"word"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
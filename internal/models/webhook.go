@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookEventPostCreated    = "post.created"
+	WebhookEventPostDeleted    = "post.deleted"
+	WebhookEventUserRegistered = "user.registered"
+	WebhookEventLikeCreated    = "like.created"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventPostCreated,
+	WebhookEventPostDeleted,
+	WebhookEventUserRegistered,
+	WebhookEventLikeCreated,
+}
+
+// CreateWebhookDTO registers an endpoint, which only admins may do. A webhook
+// receives the events about its owner's content; with AllUsers it receives
+// them for everyone.
+type CreateWebhookDTO struct {
+	UserID   uint64
+	URL      string   `json:"url" validate:"required,max=2048,http_url"`
+	Events   []string `json:"events" validate:"required,min=1,unique,dive,required"`
+	AllUsers bool     `json:"all_users,omitempty"`
+	Secret   string   `json:"-"`
+}
+
+// UpdateWebhookDTO changes an endpoint. Activating a webhook that was disabled
+// after repeated failures resets its failure count.
+type UpdateWebhookDTO struct {
+	URL    string   `json:"url,omitempty" validate:"omitempty,max=2048,http_url"`
+	Events []string `json:"events,omitempty" validate:"omitempty,min=1,unique,dive,required"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// ReadWebhookDTO carries the signing secret only in the response to the
+// request that created the webhook.
+type ReadWebhookDTO struct {
+	ID         uint64     `json:"id"`
+	URL        string     `json:"url"`
+	Events     []string   `json:"events"`
+	AllUsers   bool       `json:"all_users"`
+	Active     bool       `json:"active"`
+	Failures   uint       `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	Secret     string     `json:"secret,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type ReadWebhookDeliveryDTO struct {
+	ID             uint64          `json:"id"`
+	WebhookID      uint64          `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       uint            `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	Error          *string         `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// PendingWebhookDeliveryDTO is a delivery claimed for an attempt, with what
+// is needed to send it.
+type PendingWebhookDeliveryDTO struct {
+	ID        uint64
+	WebhookID uint64
+	URL       string
+	Secret    string
+	Event     string
+	Payload   json.RawMessage
+	Attempts  uint
+	CreatedAt time.Time
+}
+
+// WebhookAttemptDTO records the outcome of one attempt. A failed attempt
+// without NextAttemptAt fails the delivery for good.
+type WebhookAttemptDTO struct {
+	DeliveryID     uint64
+	Succeeded      bool
+	ResponseStatus *int
+	Error          string
+	NextAttemptAt  *time.Time
+}
+
+// WebhookPayloadDTO is the body posted to the endpoint.
+type WebhookPayloadDTO struct {
+	ID        uint64          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+type ChatRepositoryImpl struct {
+	db  *sql.DB
+	cfg *config.Config
+	hub *events.Hub
+}
+
+func NewChatRepositoryImpl(cfg *config.Config, hub *events.Hub) *ChatRepositoryImpl {
+	db, err := sql.Open("pgx", cfg.DatabaseDSN)
+	if err != nil {
+		log.Fatal("Error connecting to database", err)
+		return nil
+	}
+	repository := &ChatRepositoryImpl{cfg: cfg, db: db, hub: hub}
+	return repository
+}
+
+// messagesRestricted returns a condition that holds when the recipient only
+// accepts messages from the accounts they follow and does not follow the
+// sender.
+func messagesRestricted(recipient, sender string) string {
+	return fmt.Sprintf(`exists (select 1 from users mr where mr.id = %[1]s and mr.messages_from_followers_only
+		and not exists (select 1 from follows mf where mf.follower_id = %[1]s and mf.following_id = %[2]s))`, recipient, sender)
+}
+
+// messageHidden returns a condition that holds when the user deleted the
+// message for themselves.
+func messageHidden(message, user string) string {
+	return fmt.Sprintf(`exists (select 1 from message_deletions md where md.message_id = %s and md.user_id = %s)`, message, user)
+}
+
+// messageColumns selects the message aliased as m.
+const messageColumns = `m.id, m.chat_id, m.sender_id, m.type, m.subject_id, m.text, not exists (
+		select 1 from chat_members mo where mo.chat_id = m.chat_id and mo.user_id <> m.sender_id
+		and mo.last_read_message_id < m.id
+	) as is_read, m.created_at, m.unsent_at`
+
+// unreadBy returns a condition that holds for the messages aliased as m the
+// member aliased as cm has not read. System messages are never unread.
+func unreadBy(member string) string {
+	return `m.chat_id = ` + member + `.chat_id and m.id > ` + member + `.last_read_message_id
+		and m.sender_id <> ` + member + `.user_id and m.type = 'text' and m.unsent_at is null
+		and not ` + messageHidden("m.id", member+".user_id")
+}
+
+// chatColumns selects the chat aliased as c as seen by the member aliased as
+// cm, followed by the last message the member can see.
+var chatColumns = `c.id, c.is_group, coalesce(c.name, ''), c.created_at, c.last_message_at, cm.muted_at, (
+		select count(*) from messages m where ` + unreadBy("cm") + `
+	) as unread_count, lm.id, lm.chat_id, lm.sender_id, lm.type, lm.subject_id, lm.text, lm.is_read,
+	lm.created_at, lm.unsent_at`
+
+// lastMessageJoin joins the last message of the chat aliased as c the member
+// aliased as cm can see, as lm.
+var lastMessageJoin = `left join lateral (
+		select ` + messageColumns + ` from messages m
+		where m.chat_id = c.id and not ` + messageHidden("m.id", "cm.user_id") + `
+		order by m.created_at desc, m.id desc limit 1
+	) lm on true`
+
+func scanMessage(row rowScanner) (*models.ReadMessageDTO, error) {
+	var message models.ReadMessageDTO
+	err := row.Scan(
+		&message.ID, &message.ChatID, &message.SenderID, &message.Type, &message.SubjectID, &message.Text, &message.IsRead,
+		&message.CreatedAt, &message.UnsentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func scanChat(row rowScanner) (*models.ReadChatDTO, error) {
+	chat := models.ReadChatDTO{Members: []models.ReadChatMemberDTO{}}
+	var lastID, lastChatID, lastSenderID, lastSubjectID *uint64
+	var lastType, lastText *string
+	var lastIsRead *bool
+	var lastCreatedAt, lastUnsentAt *time.Time
+	err := row.Scan(
+		&chat.ID, &chat.IsGroup, &chat.Name, &chat.CreatedAt, &chat.LastMessageAt, &chat.MutedAt, &chat.UnreadCount,
+		&lastID, &lastChatID, &lastSenderID, &lastType, &lastSubjectID, &lastText, &lastIsRead,
+		&lastCreatedAt, &lastUnsentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastID != nil {
+		chat.LastMessage = &models.ReadMessageDTO{
+			ID:        *lastID,
+			ChatID:    *lastChatID,
+			SenderID:  *lastSenderID,
+			Type:      *lastType,
+			SubjectID: lastSubjectID,
+			Text:      *lastText,
+			IsRead:    *lastIsRead,
+			CreatedAt: *lastCreatedAt,
+			UnsentAt:  lastUnsentAt,
+		}
+	}
+	return &chat, nil
+}
+
+// CreateDirectChat returns the chat between the two users, creating it
+// unless one of them blocked the other or the recipient does not accept
+// messages from the user. It tells whether the chat was created.
+func (r *ChatRepositoryImpl) CreateDirectChat(userID, recipientID uint64) (uint64, bool, error) {
+	query := `
+		with target as (
+			select u.id, ` + blockedBetween("$1", "u.id") + ` as blocked,
+				` + messagesRestricted("u.id", "$1") + ` as restricted
+			from users u where u.id = $2 and u.deleted_at is null
+		), created as (
+			insert into chats (direct_low_id, direct_high_id)
+			select least($1, id), greatest($1, id) from target where not blocked and not restricted
+			on conflict (direct_low_id, direct_high_id) do nothing
+			returning id
+		), joined as (
+			insert into chat_members (chat_id, user_id)
+			select c.id, t.user_id from created c, (values ($1::bigint), ($2::bigint)) as t (user_id)
+		)
+		select exists (select 1 from target), coalesce((select blocked from target), false),
+			coalesce((select restricted from target), false), coalesce((select id from created), (
+				select id from chats where direct_low_id = least($1::bigint, $2::bigint)
+				and direct_high_id = greatest($1::bigint, $2::bigint)
+			), 0), exists (select 1 from created);
+	`
+	var found, blocked, restricted, created bool
+	var id uint64
+	if err := r.db.QueryRow(query, userID, recipientID).Scan(&found, &blocked, &restricted, &id, &created); err != nil {
+		return 0, false, err
+	}
+	if !found {
+		return 0, false, ErrNotFound
+	}
+	if blocked {
+		return 0, false, ErrBlocked
+	}
+	if restricted {
+		return 0, false, ErrMessagesRestricted
+	}
+	return id, created, nil
+}
+
+// GetChats lists the chats of the user, the most recently active first.
+func (r *ChatRepositoryImpl) GetChats(userID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadChatDTO, error) {
+	query := "select " + chatColumns + `
+		from chats c join chat_members cm on cm.chat_id = c.id and cm.user_id = $1
+		` + lastMessageJoin + `
+		where true`
+	params := []interface{}{userID}
+	direction := "desc"
+	if cursor != nil {
+		operator := "<"
+		if cursor.Backward {
+			direction, operator = "asc", ">"
+		}
+		if cursor.ID > 0 {
+			query += fmt.Sprintf(" and (c.last_message_at, c.id) %s ($2, $3)", operator)
+			params = append(params, cursor.CreatedAt, cursor.ID)
+		}
+	}
+	query += fmt.Sprintf(" order by c.last_message_at %s, c.id %s limit $%d;", direction, direction, len(params)+1)
+	params = append(params, limit)
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadChatDTO = make([]models.ReadChatDTO, 0)
+	for rows.Next() {
+		chat, err := scanChat(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(readDTO)-1; i < j; i, j = i+1, j-1 {
+			readDTO[i], readDTO[j] = readDTO[j], readDTO[i]
+		}
+	}
+	if err := r.loadMembers(userID, readDTO); err != nil {
+		return nil, err
+	}
+	return readDTO, nil
+}
+
+// GetChatByID returns the chat when the user is one of its members.
+func (r *ChatRepositoryImpl) GetChatByID(id, userID uint64) (*models.ReadChatDTO, error) {
+	query := "select " + chatColumns + `
+		from chats c join chat_members cm on cm.chat_id = c.id and cm.user_id = $2
+		` + lastMessageJoin + `
+		where c.id = $1;`
+	chat, err := scanChat(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	chats := []models.ReadChatDTO{*chat}
+	if err := r.loadMembers(userID, chats); err != nil {
+		return nil, err
+	}
+	return &chats[0], nil
+}
+
+// loadMembers fills in the members of the chats with their read receipts.
+func (r *ChatRepositoryImpl) loadMembers(viewerID uint64, chats []models.ReadChatDTO) error {
+	if len(chats) == 0 {
+		return nil
+	}
+	params := []interface{}{viewerID}
+	placeholders := []string{}
+	index := make(map[uint64]int)
+	for i, chat := range chats {
+		params = append(params, chat.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+		index[chat.ID] = i
+	}
+	query := fmt.Sprintf(`
+		select %s, cm.chat_id, cm.role, cm.last_read_message_id, cm.read_at
+		from chat_members cm join users on users.id = cm.user_id
+		where cm.chat_id in (%s)
+		order by cm.chat_id, cm.created_at, cm.user_id;
+	`, userColumns("$1"), strings.Join(placeholders, ", "))
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var chatID uint64
+		var member models.ReadChatMemberDTO
+		user, err := scanUser(rows, &chatID, &member.Role, &member.LastReadMessageID, &member.ReadAt)
+		if err != nil {
+			return err
+		}
+		member.User = *user
+		i := index[chatID]
+		chats[i].Members = append(chats[i].Members, member)
+	}
+	return rows.Err()
+}
+
+// CountUnread counts the unread messages in the chats the user did not mute.
+func (r *ChatRepositoryImpl) CountUnread(userID uint64) (uint64, error) {
+	query := `
+		select count(*) from chat_members cm join messages m on ` + unreadBy("cm") + `
+		where cm.user_id = $1 and cm.muted_at is null;
+	`
+	var count uint64
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead moves the user's read position to the last message up to
+// messageID, or to the last message of the chat when messageID is 0. The
+// position never moves back. The other members learn about it when it moved.
+func (r *ChatRepositoryImpl) MarkRead(id, userID, messageID uint64) error {
+	query := `
+		with member as (
+			select chat_id, user_id from chat_members where chat_id = $1 and user_id = $2
+		), target as (
+			select coalesce(max(m.id), 0) as message_id from messages m
+			where m.chat_id = $1 and ($3 = 0 or m.id <= $3)
+		), updated as (
+			update chat_members cm set last_read_message_id = t.message_id, read_at = now()
+			from member, target t
+			where cm.chat_id = member.chat_id and cm.user_id = member.user_id and cm.last_read_message_id < t.message_id
+			returning cm.last_read_message_id
+		)
+		select exists (select 1 from member), coalesce((select last_read_message_id from updated), 0);
+	`
+	var found bool
+	var lastReadID uint64
+	if err := r.db.QueryRow(query, id, userID, messageID).Scan(&found, &lastReadID); err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if lastReadID > 0 {
+		r.publishToMembers(id, events.TypeChatRead, func(uint64, bool) interface{} {
+			return models.StreamChatReadDTO{ChatID: id, UserID: userID, LastReadMessageID: lastReadID}
+		})
+	}
+	return nil
+}
+
+func (r *ChatRepositoryImpl) MuteChat(id, userID uint64) error {
+	query := `
+		update chat_members set muted_at = coalesce(muted_at, now())
+		where chat_id = $1 and user_id = $2
+		returning chat_id;
+	`
+	err := r.db.QueryRow(query, id, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *ChatRepositoryImpl) UnmuteChat(id, userID uint64) error {
+	query := `
+		update chat_members set muted_at = null
+		where chat_id = $1 and user_id = $2
+		returning chat_id;
+	`
+	err := r.db.QueryRow(query, id, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// publishMessage announces a new message to the members of its chat and to
+// the users in alsoTo, who just left it.
+func (r *ChatRepositoryImpl) publishMessage(message *models.ReadMessageDTO, alsoTo ...uint64) {
+	r.publishToMembers(message.ChatID, events.TypeMessage, func(_ uint64, muted bool) interface{} {
+		return models.StreamMessageDTO{ReadMessageDTO: *message, Muted: muted}
+	})
+	for _, userID := range alsoTo {
+		r.hub.Publish(events.UserTopic(userID), events.TypeMessage, models.StreamMessageDTO{ReadMessageDTO: *message})
+	}
+}
+
+// publishToMembers hands an event to the streams of every member of the chat,
+// with the data built for each member and whether they muted the chat.
+func (r *ChatRepositoryImpl) publishToMembers(chatID uint64, eventType string, data func(userID uint64, muted bool) interface{}) {
+	if r.hub == nil {
+		return
+	}
+	rows, err := r.db.Query(`select user_id, muted_at is not null from chat_members where chat_id = $1;`, chatID)
+	if err != nil {
+		log.Printf("Failed to read chat members: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID uint64
+		var muted bool
+		if err := rows.Scan(&userID, &muted); err != nil {
+			log.Printf("Failed to read chat members: %v", err)
+			return
+		}
+		r.hub.Publish(events.UserTopic(userID), eventType, data(userID, muted))
+	}
+}
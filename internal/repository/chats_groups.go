@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// chatAdmin selects the group bound to the chat placeholder when the user
+// bound to the user placeholder is a member, along with whether they are an
+// admin and how many members the group has.
+func chatAdmin(chat, user string) string {
+	return fmt.Sprintf(`select c.id, cm.role = 'admin' as admin, (
+			select count(*) from chat_members ca where ca.chat_id = c.id
+		) as members
+		from chats c join chat_members cm on cm.chat_id = c.id and cm.user_id = %s
+		where c.id = %s and c.is_group`, user, chat)
+}
+
+// announce returns common table expressions, to follow the others of a
+// statement, that write the system messages selected by source, which yields
+// the chat, sender, type, subject and text of each. The last expression,
+// announced, returns the messages.
+func announce(source string) string {
+	return `announced as (
+			insert into messages (chat_id, sender_id, type, subject_id, text) ` + source + `
+			returning id, chat_id, sender_id, type, subject_id, text, created_at
+		), touched as (
+			update chats set last_message_at = a.created_at from announced a where chats.id = a.chat_id
+		)`
+}
+
+// scanAnnounced reads the message returned by announced, if any.
+func scanAnnounced(id, chatID, senderID *uint64, messageType, text *string, subjectID *uint64, createdAt *time.Time) *models.ReadMessageDTO {
+	if id == nil {
+		return nil
+	}
+	return &models.ReadMessageDTO{
+		ID:        *id,
+		ChatID:    *chatID,
+		SenderID:  *senderID,
+		Type:      *messageType,
+		SubjectID: subjectID,
+		Text:      *text,
+		CreatedAt: *createdAt,
+	}
+}
+
+// announcedColumns selects the message returned by announced for
+// scanAnnounced.
+const announcedColumns = `(select id from announced), (select chat_id from announced), (select sender_id from announced),
+	(select type from announced), (select text from announced), (select subject_id from announced),
+	(select created_at from announced)`
+
+// CreateGroupChat starts a group with the creator as its admin. It is
+// refused when any of the users is not found, blocked the creator or was
+// blocked by them, or does not accept messages from the creator.
+func (r *ChatRepositoryImpl) CreateGroupChat(dto models.CreateGroupChatDTO) (uint64, error) {
+	params := []interface{}{dto.CreatorID, dto.Name, len(dto.UserIDs)}
+	placeholders := []string{}
+	for _, userID := range dto.UserIDs {
+		params = append(params, userID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+	}
+	query := fmt.Sprintf(`
+		with targets as (
+			select u.id, `+blockedBetween("$1", "u.id")+` as blocked, `+messagesRestricted("u.id", "$1")+` as restricted
+			from users u where u.id in (%s) and u.id <> $1 and u.deleted_at is null
+		), chat as (
+			insert into chats (is_group, name)
+			select true, $2 where (select count(*) from targets) = $3
+			and not exists (select 1 from targets where blocked or restricted)
+			returning id
+		), joined as (
+			insert into chat_members (chat_id, user_id, role)
+			select chat.id, $1, 'admin' from chat
+			union all
+			select chat.id, t.id, 'member' from chat, targets t
+		), `+announce(`select id, $1, 'group_created', null::bigint, $2 from chat`)+`
+		select (select count(*) from targets), exists (select 1 from targets where blocked),
+			exists (select 1 from targets where restricted), coalesce((select id from chat), 0);
+	`, strings.Join(placeholders, ", "))
+	var found int
+	var blocked, restricted bool
+	var id uint64
+	if err := r.db.QueryRow(query, params...).Scan(&found, &blocked, &restricted, &id); err != nil {
+		return 0, err
+	}
+	if blocked {
+		return 0, ErrBlocked
+	}
+	if restricted {
+		return 0, ErrMessagesRestricted
+	}
+	if found < len(dto.UserIDs) {
+		return 0, ErrNotFound
+	}
+	return id, nil
+}
+
+// UpdateChat renames the group. Only admins may rename it.
+func (r *ChatRepositoryImpl) UpdateChat(id, userID uint64, dto models.UpdateChatDTO) error {
+	query := `
+		with chat as (` + chatAdmin("$1", "$2") + `
+		), renamed as (
+			update chats set name = $3 from chat where chats.id = chat.id and chat.admin
+			returning chats.id
+		), ` + announce(`select id, $2, 'group_renamed', null::bigint, $3 from renamed`) + `
+		select exists (select 1 from chat), coalesce((select admin from chat), false), ` + announcedColumns + `;
+	`
+	var found, admin bool
+	var messageID, chatID, senderID, subjectID *uint64
+	var messageType, text *string
+	var createdAt *time.Time
+	err := r.db.QueryRow(query, id, userID, dto.Name).Scan(
+		&found, &admin, &messageID, &chatID, &senderID, &messageType, &text, &subjectID, &createdAt,
+	)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if !admin {
+		return ErrChatAdminOnly
+	}
+	if message := scanAnnounced(messageID, chatID, senderID, messageType, text, subjectID, createdAt); message != nil {
+		r.publishMessage(message)
+	}
+	return nil
+}
+
+// AddChatMember lets an admin add a user to the group, up to
+// ChatMaxMembers. Like creating a group, it is refused when the user and the
+// admin blocked each other or the user does not accept messages from the
+// admin. The new member starts with the history read.
+func (r *ChatRepositoryImpl) AddChatMember(id, userID, memberID uint64) error {
+	query := `
+		with chat as (` + chatAdmin("$1", "$2") + `
+		), target as (
+			select u.id, ` + blockedBetween("$2", "u.id") + ` as blocked, ` + messagesRestricted("u.id", "$2") + ` as restricted,
+				exists (select 1 from chat_members tm where tm.chat_id = $1 and tm.user_id = u.id) as joined
+			from users u where u.id = $3 and u.deleted_at is null
+		), added as (
+			insert into chat_members (chat_id, user_id, last_read_message_id)
+			select chat.id, target.id, coalesce((select max(m.id) from messages m where m.chat_id = chat.id), 0)
+			from chat, target
+			where chat.admin and chat.members < $4 and not target.blocked and not target.restricted
+			on conflict (chat_id, user_id) do nothing
+			returning chat_id, user_id
+		), ` + announce(`select chat_id, $2, 'member_added', user_id, '' from added`) + `
+		select exists (select 1 from chat), coalesce((select admin from chat), false),
+			coalesce((select members from chat), 0), exists (select 1 from target),
+			coalesce((select blocked from target), false), coalesce((select restricted from target), false),
+			coalesce((select joined from target), false), ` + announcedColumns + `;
+	`
+	var found, admin, targetFound, blocked, restricted, joined bool
+	var members int
+	var messageID, chatID, senderID, subjectID *uint64
+	var messageType, text *string
+	var createdAt *time.Time
+	err := r.db.QueryRow(query, id, userID, memberID, r.cfg.ChatMaxMembers).Scan(
+		&found, &admin, &members, &targetFound, &blocked, &restricted, &joined,
+		&messageID, &chatID, &senderID, &messageType, &text, &subjectID, &createdAt,
+	)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !found || !targetFound:
+		return ErrNotFound
+	case !admin:
+		return ErrChatAdminOnly
+	case joined:
+		return nil
+	case blocked:
+		return ErrBlocked
+	case restricted:
+		return ErrMessagesRestricted
+	case members >= r.cfg.ChatMaxMembers:
+		return ErrChatFull
+	}
+	if message := scanAnnounced(messageID, chatID, senderID, messageType, text, subjectID, createdAt); message != nil {
+		r.publishMessage(message)
+	}
+	return nil
+}
+
+// UpdateChatMember lets an admin change the role of a member. The last admin
+// cannot step down.
+func (r *ChatRepositoryImpl) UpdateChatMember(id, userID, memberID uint64, role string) error {
+	query := `
+		with chat as (` + chatAdmin("$1", "$2") + `
+		), target as (
+			select tm.user_id, tm.role = 'admin' and not exists (
+				select 1 from chat_members oa where oa.chat_id = $1 and oa.user_id <> tm.user_id and oa.role = 'admin'
+			) as last_admin
+			from chat_members tm where tm.chat_id = $1 and tm.user_id = $3
+		), updated as (
+			update chat_members cm set role = $4 from chat, target
+			where cm.chat_id = chat.id and cm.user_id = target.user_id and chat.admin
+			and not ($4 <> 'admin' and target.last_admin)
+			returning cm.user_id
+		)
+		select exists (select 1 from chat), coalesce((select admin from chat), false), exists (select 1 from target),
+			coalesce((select last_admin from target), false);
+	`
+	var found, admin, targetFound, lastAdmin bool
+	if err := r.db.QueryRow(query, id, userID, memberID, role).Scan(&found, &admin, &targetFound, &lastAdmin); err != nil {
+		return err
+	}
+	switch {
+	case !found || !targetFound:
+		return ErrNotFound
+	case !admin:
+		return ErrChatAdminOnly
+	case lastAdmin && role != models.ChatRoleAdmin:
+		return ErrLastChatAdmin
+	}
+	return nil
+}
+
+// RemoveChatMember takes a member out of the group: an admin may remove
+// anyone and every member may leave. When the last admin leaves, the member
+// who joined first becomes admin, and when the last member leaves, the group
+// is deleted along with its messages. The removed member keeps no access to
+// the group.
+func (r *ChatRepositoryImpl) RemoveChatMember(id, userID, memberID uint64) error {
+	query := `
+		with chat as (` + chatAdmin("$1", "$2") + `
+		), removed as (
+			delete from chat_members cm using chat
+			where cm.chat_id = chat.id and cm.user_id = $3 and ($2 = $3 or chat.admin)
+			returning cm.chat_id, cm.user_id
+		), promoted as (
+			update chat_members cm set role = 'admin' from removed
+			where cm.chat_id = removed.chat_id and cm.user_id = (
+				select nm.user_id from chat_members nm where nm.chat_id = removed.chat_id and nm.user_id <> $3
+				order by nm.created_at, nm.user_id limit 1
+			) and not exists (
+				select 1 from chat_members oa where oa.chat_id = removed.chat_id and oa.user_id <> $3 and oa.role = 'admin'
+			)
+		), emptied as (
+			select removed.chat_id from removed
+			where not exists (select 1 from chat_members om where om.chat_id = removed.chat_id and om.user_id <> $3)
+		), dropped as (
+			delete from chats using emptied where chats.id = emptied.chat_id
+			returning chats.id
+		), ` + announce(`
+			select chat_id, $2, case when $2 = $3 then 'member_left' else 'member_removed' end, user_id, ''
+			from removed where not exists (select 1 from emptied)`) + `
+		select exists (select 1 from chat), coalesce((select admin from chat), false), exists (select 1 from dropped),
+			` + announcedColumns + `;
+	`
+	var found, admin, dropped bool
+	var messageID, chatID, senderID, subjectID *uint64
+	var messageType, text *string
+	var createdAt *time.Time
+	err := r.db.QueryRow(query, id, userID, memberID).Scan(
+		&found, &admin, &dropped, &messageID, &chatID, &senderID, &messageType, &text, &subjectID, &createdAt,
+	)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if dropped {
+		return nil
+	}
+	message := scanAnnounced(messageID, chatID, senderID, messageType, text, subjectID, createdAt)
+	if message == nil {
+		if userID != memberID && !admin {
+			return ErrChatAdminOnly
+		}
+		return ErrNotFound
+	}
+	r.publishMessage(message, memberID)
+	return nil
+}
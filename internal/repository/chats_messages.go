@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// GetMessages lists the messages of the chat the user did not delete for
+// themselves, newest first. It does not check that the user is a member.
+func (r *ChatRepositoryImpl) GetMessages(chatID, userID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadMessageDTO, error) {
+	query := "select " + messageColumns + `
+		from messages m
+		where m.chat_id = $1 and not ` + messageHidden("m.id", "$2")
+	params := []interface{}{chatID, userID}
+	direction := "desc"
+	if cursor != nil {
+		operator := "<"
+		if cursor.Backward {
+			direction, operator = "asc", ">"
+		}
+		if cursor.ID > 0 {
+			query += fmt.Sprintf(" and (m.created_at, m.id) %s ($3, $4)", operator)
+			params = append(params, cursor.CreatedAt, cursor.ID)
+		}
+	}
+	query += fmt.Sprintf(" order by m.created_at %s, m.id %s limit $%d;", direction, direction, len(params)+1)
+	params = append(params, limit)
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadMessageDTO = make([]models.ReadMessageDTO, 0)
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(readDTO)-1; i < j; i, j = i+1, j-1 {
+			readDTO[i], readDTO[j] = readDTO[j], readDTO[i]
+		}
+	}
+	return readDTO, nil
+}
+
+// CreateMessage adds a message from a member to the chat, which counts as
+// read by its sender. In a direct chat it is refused when the members blocked
+// each other or the recipient does not accept messages from the sender;
+// groups check that when members are added.
+func (r *ChatRepositoryImpl) CreateMessage(dto models.CreateMessageDTO) (*models.ReadMessageDTO, error) {
+	query := `
+		with chat as (
+			select cm.chat_id from chat_members cm where cm.chat_id = $1 and cm.user_id = $2
+		), denied as (
+			select coalesce(bool_or(` + blockedBetween("$2", "o.user_id") + `), false) as blocked,
+				coalesce(bool_or(` + messagesRestricted("o.user_id", "$2") + `), false) as restricted
+			from chat_members o join chats dc on dc.id = o.chat_id and not dc.is_group
+			where o.chat_id in (select chat_id from chat) and o.user_id <> $2
+		), inserted as (
+			insert into messages (chat_id, sender_id, text)
+			select chat_id, $2, $3 from chat, denied where not denied.blocked and not denied.restricted
+			returning id, created_at
+		), touched as (
+			update chats set last_message_at = i.created_at from inserted i where chats.id = $1
+		), sent as (
+			update chat_members set last_read_message_id = i.id, read_at = i.created_at
+			from inserted i where chat_members.chat_id = $1 and chat_members.user_id = $2
+		)
+		select exists (select 1 from chat), (select blocked from denied), (select restricted from denied),
+			(select id from inserted), (select created_at from inserted);
+	`
+	var found bool
+	var blocked, restricted *bool
+	var id *uint64
+	var createdAt *time.Time
+	err := r.db.QueryRow(query, dto.ChatID, dto.SenderID, dto.Text).Scan(&found, &blocked, &restricted, &id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	if blocked != nil && *blocked {
+		return nil, ErrBlocked
+	}
+	if restricted != nil && *restricted {
+		return nil, ErrMessagesRestricted
+	}
+	if id == nil {
+		return nil, ErrNotFound
+	}
+	message := &models.ReadMessageDTO{
+		ID:        *id,
+		ChatID:    dto.ChatID,
+		SenderID:  dto.SenderID,
+		Type:      models.MessageTypeText,
+		Text:      dto.Text,
+		CreatedAt: *createdAt,
+	}
+	r.publishMessage(message)
+	return message, nil
+}
+
+// UnsendMessage takes the sender's message back for every member. Its text
+// is removed and it stays in place marked as unsent. System messages cannot
+// be unsent.
+func (r *ChatRepositoryImpl) UnsendMessage(id, chatID, senderID uint64) error {
+	query := `
+		update messages set text = '', unsent_at = now()
+		where id = $1 and chat_id = $2 and sender_id = $3 and type = 'text' and unsent_at is null
+		returning id;
+	`
+	err := r.db.QueryRow(query, id, chatID, senderID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	r.publishToMembers(chatID, events.TypeMessageUnsent, func(uint64, bool) interface{} {
+		return models.StreamMessageUnsentDTO{ChatID: chatID, MessageID: id}
+	})
+	return nil
+}
+
+// DeleteMessage hides the message from the member only.
+func (r *ChatRepositoryImpl) DeleteMessage(id, chatID, userID uint64) error {
+	query := `
+		insert into message_deletions (message_id, user_id)
+		select m.id, cm.user_id from messages m
+		join chat_members cm on cm.chat_id = m.chat_id and cm.user_id = $3
+		where m.id = $1 and m.chat_id = $2
+		on conflict (message_id, user_id) do nothing;
+	`
+	result, err := r.db.Exec(query, id, chatID, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var chatRowColumns = []string{
+	"id", "is_group", "name", "created_at", "last_message_at", "muted_at", "unread_count",
+	"lm_id", "lm_chat_id", "lm_sender_id", "lm_type", "lm_subject_id", "lm_text", "lm_is_read", "lm_created_at", "lm_unsent_at",
+}
+
+var messageRowColumns = []string{
+	"id", "chat_id", "sender_id", "type", "subject_id", "text", "is_read", "created_at", "unsent_at",
+}
+
+func TestChatRepositoryImpl_CreateDirectChat(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`insert into chats (direct_low_id, direct_high_id)`) + ".*" +
+		regexp.QuoteMeta(`on conflict (direct_low_id, direct_high_id) do nothing`)
+	columns := []string{"found", "blocked", "restricted", "id", "created"}
+	testCases := []struct {
+		name    string
+		row     []driver.Value
+		err     error
+		id      uint64
+		created bool
+	}{
+		{name: "Created", row: []driver.Value{true, false, false, 5, true}, id: 5, created: true},
+		{name: "Existing", row: []driver.Value{true, false, false, 5, false}, id: 5},
+		{name: "Blocked", row: []driver.Value{true, true, false, 0, false}, err: ErrBlocked},
+		{name: "Followers only", row: []driver.Value{true, false, true, 0, false}, err: ErrMessagesRestricted},
+		{name: "Unknown user", row: []driver.Value{false, false, false, 0, false}, err: ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery(query).WithArgs(uint64(1), uint64(2)).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(tc.row...))
+			id, created, err := r.CreateDirectChat(1, 2)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			assert.Equal(t, tc.id, id, "ID mismatch")
+			assert.Equal(t, tc.created, created, "Created mismatch")
+		})
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_GetChatByID(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	query := regexp.QuoteMeta(`from chats c join chat_members cm on cm.chat_id = c.id and cm.user_id = $2`) + ".*" +
+		regexp.QuoteMeta(`where c.id = $1;`)
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1)).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow(5, false, "", now, now, nil, 2, 9, 5, 2, models.MessageTypeText, nil, "Hi", false, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`cm.chat_id, cm.role, cm.last_read_message_id, cm.read_at`)+".*"+
+		regexp.QuoteMeta(`where cm.chat_id in ($2)`)).
+		WithArgs(uint64(1), uint64(5)).
+		WillReturnRows(sqlmock.NewRows(append(userRowColumns, "chat_id", "role", "last_read_message_id", "read_at")).
+			AddRow(1, "john", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, 5, "member", 7, now).
+			AddRow(2, "jane", "Jane", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, 5, "member", 9, now))
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(3)).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns))
+
+	chat, err := r.GetChatByID(5, 1)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(2), chat.UnreadCount, "Unread count mismatch")
+	assert.Equal(t, "Hi", chat.LastMessage.Text, "Last message mismatch")
+	assert.Len(t, chat.Members, 2, "Members count mismatch")
+	assert.Equal(t, uint64(9), chat.Members[1].LastReadMessageID, "Read receipt mismatch")
+
+	_, err = r.GetChatByID(5, 3)
+	assert.Equal(t, ErrNotFound, err, "Non-members should not see the chat")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_GetMessages(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`where m.chat_id = $1 and not exists (select 1 from message_deletions md where md.message_id = m.id and md.user_id = $2)`)+
+		".*"+regexp.QuoteMeta(`and (m.created_at, m.id) > ($3, $4) order by m.created_at asc, m.id asc limit $5;`)).
+		WithArgs(uint64(5), uint64(1), now, uint64(3), uint64(11)).
+		WillReturnRows(sqlmock.NewRows(messageRowColumns).
+			AddRow(4, 5, 2, models.MessageTypeText, nil, "", true, now, now).
+			AddRow(5, 5, 1, models.MessageTypeText, nil, "Hi", false, now, nil))
+
+	messages, err := r.GetMessages(5, 1, &models.Cursor{CreatedAt: now, ID: 3, Backward: true}, 11)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(5), messages[0].ID, "Backward page should be returned newest first")
+	assert.NotNil(t, messages[1].UnsentAt, "Unsent message should be marked")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_CreateMessage(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	subscription := hub.Subscribe(events.UserTopic(2))
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db, hub: hub}
+	now := time.Now()
+	query := regexp.QuoteMeta(`insert into messages (chat_id, sender_id, text)`)
+	columns := []string{"found", "blocked", "restricted", "id", "created_at"}
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), "Hi").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, false, false, 9, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`select user_id, muted_at is not null from chat_members where chat_id = $1;`)).
+		WithArgs(uint64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "muted"}).AddRow(1, false).AddRow(2, true))
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), "Hi").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, true, false, nil, nil))
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), "Hi").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, false, true, nil, nil))
+	mock.ExpectQuery(query).WithArgs(uint64(6), uint64(1), "Hi").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(false, nil, nil, nil, nil))
+
+	message, err := r.CreateMessage(models.CreateMessageDTO{ChatID: 5, SenderID: 1, Text: "Hi"})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(9), message.ID, "ID mismatch")
+	event := <-subscription.Events()
+	assert.Equal(t, events.TypeMessage, event.Type, "Event type mismatch")
+	assert.Contains(t, string(event.Data), `"muted":true`, "Muted chat should be marked")
+
+	_, err = r.CreateMessage(models.CreateMessageDTO{ChatID: 5, SenderID: 1, Text: "Hi"})
+	assert.Equal(t, ErrBlocked, err, "Blocked users should not be messaged")
+	_, err = r.CreateMessage(models.CreateMessageDTO{ChatID: 5, SenderID: 1, Text: "Hi"})
+	assert.Equal(t, ErrMessagesRestricted, err, "Followers only setting should be respected")
+	_, err = r.CreateMessage(models.CreateMessageDTO{ChatID: 6, SenderID: 1, Text: "Hi"})
+	assert.Equal(t, ErrNotFound, err, "Non-members should not send messages")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_MarkRead(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	subscription := hub.Subscribe(events.UserTopic(2))
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db, hub: hub}
+	query := regexp.QuoteMeta(`update chat_members cm set last_read_message_id = t.message_id, read_at = now()`)
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "last_read_message_id"}).AddRow(true, 9))
+	mock.ExpectQuery(regexp.QuoteMeta(`select user_id, muted_at is not null from chat_members where chat_id = $1;`)).
+		WithArgs(uint64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "muted"}).AddRow(1, false).AddRow(2, false))
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "last_read_message_id"}).AddRow(true, 0))
+	mock.ExpectQuery(query).WithArgs(uint64(6), uint64(1), uint64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "last_read_message_id"}).AddRow(false, 0))
+
+	assert.Nil(t, r.MarkRead(5, 1, 0), "Error is not nil")
+	event := <-subscription.Events()
+	assert.Equal(t, events.TypeChatRead, event.Type, "Event type mismatch")
+	assert.JSONEq(t, `{"chat_id":5,"user_id":1,"last_read_message_id":9}`, string(event.Data), "Event data mismatch")
+
+	assert.Nil(t, r.MarkRead(5, 1, 0), "Reading again should succeed")
+	assert.Empty(t, subscription.Events(), "Unchanged read position should not be announced")
+	assert.Equal(t, ErrNotFound, r.MarkRead(6, 1, 0), "Non-members should not read the chat")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_UnsendMessage(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`update messages set text = '', unsent_at = now()
+		where id = $1 and chat_id = $2 and sender_id = $3 and type = 'text' and unsent_at is null`)
+	mock.ExpectQuery(query).WithArgs(uint64(9), uint64(5), uint64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectQuery(query).WithArgs(uint64(9), uint64(5), uint64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	assert.Nil(t, r.UnsendMessage(9, 5, 1), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.UnsendMessage(9, 5, 2), "Only the sender can unsend a message")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_CreateGroupChat(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`from users u where u.id in ($4, $5) and u.id <> $1`) + ".*" +
+		regexp.QuoteMeta(`insert into chats (is_group, name)`)
+	columns := []string{"found", "blocked", "restricted", "id"}
+	testCases := []struct {
+		name string
+		row  []driver.Value
+		err  error
+		id   uint64
+	}{
+		{name: "Created", row: []driver.Value{2, false, false, 5}, id: 5},
+		{name: "Blocked", row: []driver.Value{2, true, false, 0}, err: ErrBlocked},
+		{name: "Followers only", row: []driver.Value{2, false, true, 0}, err: ErrMessagesRestricted},
+		{name: "Unknown user", row: []driver.Value{1, false, false, 0}, err: ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery(query).WithArgs(uint64(1), "Gophers", 2, uint64(2), uint64(3)).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(tc.row...))
+			id, err := r.CreateGroupChat(models.CreateGroupChatDTO{CreatorID: 1, Name: "Gophers", UserIDs: []uint64{2, 3}})
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			assert.Equal(t, tc.id, id, "ID mismatch")
+		})
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_AddChatMember(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.ChatMaxMembers = 3
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	subscription := hub.Subscribe(events.UserTopic(3))
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db, hub: hub}
+	now := time.Now()
+	query := regexp.QuoteMeta(`insert into chat_members (chat_id, user_id, last_read_message_id)`)
+	columns := []string{
+		"found", "admin", "members", "target_found", "blocked", "restricted", "joined",
+		"id", "chat_id", "sender_id", "type", "text", "subject_id", "created_at",
+	}
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(3), 3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(true, true, 2, true, false, false, false, 9, 5, 1, models.MessageTypeMemberAdded, "", 3, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`select user_id, muted_at is not null from chat_members where chat_id = $1;`)).
+		WithArgs(uint64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "muted"}).AddRow(1, false).AddRow(2, false).AddRow(3, false))
+	testCases := []struct {
+		name string
+		row  []driver.Value
+		err  error
+	}{
+		{name: "Already a member", row: []driver.Value{true, true, 3, true, false, false, true}},
+		{name: "Not an admin", row: []driver.Value{true, false, 2, true, false, false, false}, err: ErrChatAdminOnly},
+		{name: "Blocked", row: []driver.Value{true, true, 2, true, true, false, false}, err: ErrBlocked},
+		{name: "Followers only", row: []driver.Value{true, true, 2, true, false, true, false}, err: ErrMessagesRestricted},
+		{name: "Full", row: []driver.Value{true, true, 3, true, false, false, false}, err: ErrChatFull},
+		{name: "Unknown user", row: []driver.Value{true, true, 2, false, false, false, false}, err: ErrNotFound},
+		{name: "Not a group", row: []driver.Value{false, false, 0, true, false, false, false}, err: ErrNotFound},
+	}
+	for _, tc := range testCases {
+		mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(3), 3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(append(tc.row, nil, nil, nil, nil, nil, nil, nil)...))
+	}
+
+	assert.Nil(t, r.AddChatMember(5, 1, 3), "Error is not nil")
+	event := <-subscription.Events()
+	assert.Equal(t, events.TypeMessage, event.Type, "Event type mismatch")
+	assert.Contains(t, string(event.Data), `"type":"member_added"`, "System message should be announced")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.err, r.AddChatMember(5, 1, 3), "Error mismatch")
+		})
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_UpdateChatMember(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`update chat_members cm set role = $4`)
+	columns := []string{"found", "admin", "target_found", "last_admin"}
+	testCases := []struct {
+		name string
+		role string
+		row  []driver.Value
+		err  error
+	}{
+		{name: "Promoted", role: models.ChatRoleAdmin, row: []driver.Value{true, true, true, false}},
+		{name: "Last admin", role: models.ChatRoleMember, row: []driver.Value{true, true, true, true}, err: ErrLastChatAdmin},
+		{name: "Not an admin", role: models.ChatRoleAdmin, row: []driver.Value{true, false, true, false}, err: ErrChatAdminOnly},
+		{name: "Not a member", role: models.ChatRoleAdmin, row: []driver.Value{true, true, false, false}, err: ErrNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(2), tc.role).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(tc.row...))
+			assert.Equal(t, tc.err, r.UpdateChatMember(5, 1, 2, tc.role), "Error mismatch")
+		})
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestChatRepositoryImpl_RemoveChatMember(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	subscription := hub.Subscribe(events.UserTopic(2))
+	r := &ChatRepositoryImpl{cfg: &cfg, db: db, hub: hub}
+	now := time.Now()
+	query := regexp.QuoteMeta(`delete from chat_members cm using chat`)
+	columns := []string{"found", "admin", "dropped", "id", "chat_id", "sender_id", "type", "text", "subject_id", "created_at"}
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(2)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, true, false, 9, 5, 1, models.MessageTypeMemberRemoved, "", 2, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`select user_id, muted_at is not null from chat_members where chat_id = $1;`)).
+		WithArgs(uint64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "muted"}).AddRow(1, false))
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(3), uint64(2)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, false, false, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1), uint64(4)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, true, false, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(query).WithArgs(uint64(6), uint64(1), uint64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(false, false, false, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(`delete from chat_members cm using chat .* emptied as \( .* delete from chats using emptied `+
+		`.* from removed where not exists \(select 1 from emptied\)`).WithArgs(uint64(7), uint64(1), uint64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, true, true, nil, nil, nil, nil, nil, nil, nil))
+
+	assert.Nil(t, r.RemoveChatMember(5, 1, 2), "Error is not nil")
+	event := <-subscription.Events()
+	assert.Contains(t, string(event.Data), `"type":"member_removed"`, "Removed member should be told")
+	assert.Equal(t, ErrChatAdminOnly, r.RemoveChatMember(5, 3, 2), "Only admins can remove others")
+	assert.Equal(t, ErrNotFound, r.RemoveChatMember(5, 1, 4), "Non-members cannot be removed")
+	assert.Equal(t, ErrNotFound, r.RemoveChatMember(6, 1, 1), "Only groups can be left")
+	assert.Nil(t, r.RemoveChatMember(7, 1, 1), "Last member should be able to leave")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+type ListRepositoryImpl struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+func NewListRepositoryImpl(cfg *config.Config) *ListRepositoryImpl {
+	db, err := sql.Open("pgx", cfg.DatabaseDSN)
+	if err != nil {
+		log.Fatal("Error connecting to database", err)
+		return nil
+	}
+	repository := &ListRepositoryImpl{cfg: cfg, db: db}
+	return repository
+}
+
+// listColumns selects a list aliased as l as seen by the user bound to the
+// viewer placeholder.
+func listColumns(viewer string) string {
+	return `l.id, l.user_id, l.name, l.description, l.private,
+		(select count(*) from list_members lm where lm.list_id = l.id) as members_count,
+		(select count(*) from list_subscriptions ls where ls.list_id = l.id) as subscribers_count,
+		exists (select 1 from list_subscriptions vs where vs.list_id = l.id and vs.user_id = ` + viewer + `) as is_subscribed,
+		l.created_at, l.updated_at`
+}
+
+// listVisibleTo returns a condition on the list aliased as l that holds when
+// the user bound to the viewer placeholder may see it: private lists are only
+// visible to their owner, public ones to everyone the owner has no block with.
+func listVisibleTo(viewer string) string {
+	return fmt.Sprintf("(l.user_id = %[1]s or (not l.private and not %[2]s))", viewer, blockedBetween(viewer, "l.user_id"))
+}
+
+// listTimeline returns a condition on the post aliased as p that keeps the
+// posts of the members of the list bound to the list placeholder, provided
+// the viewer may see the list.
+func listTimeline(viewer, list string) string {
+	return fmt.Sprintf(`p.user_id in (
+		select lm.user_id from list_members lm join lists l on l.id = lm.list_id
+		where lm.list_id = %s and %s
+	)`, list, listVisibleTo(viewer))
+}
+
+func scanList(row rowScanner) (*models.ReadListDTO, error) {
+	var list models.ReadListDTO
+	err := row.Scan(
+		&list.ID, &list.UserID, &list.Name, &list.Description, &list.Private,
+		&list.MembersCount, &list.SubscribersCount, &list.IsSubscribed, &list.CreatedAt, &list.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *ListRepositoryImpl) CreateList(dto models.CreateListDTO) (*models.ReadListDTO, error) {
+	query := `
+		with l as (
+			insert into lists (user_id, name, description, private) values ($1, $2, $3, $4)
+			returning id, user_id, name, description, private, created_at, updated_at
+		)
+		select l.id, l.user_id, l.name, l.description, l.private, 0, 0, false, l.created_at, l.updated_at from l;
+	`
+	return scanList(r.db.QueryRow(query, dto.UserID, dto.Name, dto.Description, dto.Private))
+}
+
+func (r *ListRepositoryImpl) GetListByID(id, viewerID uint64) (*models.ReadListDTO, error) {
+	query := "select " + listColumns("$1") + " from lists l where l.id = $2 and " + listVisibleTo("$1") + ";"
+	return scanList(r.db.QueryRow(query, viewerID, id))
+}
+
+func (r *ListRepositoryImpl) GetLists(dto models.FilterListDTO) ([]models.ReadListDTO, error) {
+	query := "select " + listColumns("$1") + " from lists l where " + listVisibleTo("$1")
+	params := []interface{}{dto.ViewerID}
+	if dto.OwnerID > 0 {
+		query += fmt.Sprintf(" and l.user_id = $%d", len(params)+1)
+		params = append(params, dto.OwnerID)
+	}
+	if dto.SubscriberID > 0 {
+		query += fmt.Sprintf(" and exists (select 1 from list_subscriptions fs where fs.list_id = l.id and fs.user_id = $%d)", len(params)+1)
+		params = append(params, dto.SubscriberID)
+	}
+	if dto.MemberID > 0 {
+		// members never learn about private lists, not even through their owner
+		query += fmt.Sprintf(" and not l.private and exists (select 1 from list_members fm where fm.list_id = l.id and fm.user_id = $%d)", len(params)+1)
+		params = append(params, dto.MemberID)
+	}
+	query += fmt.Sprintf(" order by l.created_at desc, l.id desc offset $%d limit $%d;", len(params)+1, len(params)+2)
+	params = append(params, dto.Offset, dto.Limit)
+
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadListDTO = make([]models.ReadListDTO, 0)
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *list)
+	}
+	return readDTO, rows.Err()
+}
+
+func (r *ListRepositoryImpl) UpdateList(id, ownerID uint64, dto models.UpdateListDTO) (*models.ReadListDTO, error) {
+	fields := make([]string, 0)
+	args := make([]interface{}, 0)
+	if dto.Name != "" {
+		fields = append(fields, fmt.Sprintf("name = $%d", len(args)+1))
+		args = append(args, dto.Name)
+	}
+	if dto.Description != nil {
+		fields = append(fields, fmt.Sprintf("description = $%d", len(args)+1))
+		args = append(args, *dto.Description)
+	}
+	if dto.Private != nil {
+		fields = append(fields, fmt.Sprintf("private = $%d", len(args)+1))
+		args = append(args, *dto.Private)
+	}
+	if len(fields) == 0 {
+		return nil, ErrNoFieldsToUpdate
+	}
+	fields = append(fields, "updated_at = now()")
+
+	ownerParam := fmt.Sprintf("$%d", len(args)+1)
+	query := fmt.Sprintf("update lists l set %s where l.id = $%d and l.user_id = %s returning %s;",
+		strings.Join(fields, ", "), len(args)+2, ownerParam, listColumns(ownerParam))
+	args = append(args, ownerID, id)
+	return scanList(r.db.QueryRow(query, args...))
+}
+
+func (r *ListRepositoryImpl) DeleteList(id, ownerID uint64) error {
+	query := `delete from lists where id = $1 and user_id = $2;`
+	result, err := r.db.Exec(query, id, ownerID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AddListMember adds the user to a list of the owner. Users with a block
+// between them and the owner cannot be added.
+func (r *ListRepositoryImpl) AddListMember(id, ownerID, userID uint64) error {
+	query := `
+		with target as (
+			select l.id as list_id, u.id as user_id, ` + blockedBetween("l.user_id", "u.id") + ` as blocked
+			from lists l, users u
+			where l.id = $1 and l.user_id = $2 and u.id = $3 and u.deleted_at is null
+		), inserted as (
+			insert into list_members (list_id, user_id)
+			select list_id, user_id from target where not blocked
+			on conflict (list_id, user_id) do nothing
+			returning user_id
+		)
+		select exists (select 1 from target), exists (select 1 from target where blocked);
+	`
+	var found, blocked bool
+	if err := r.db.QueryRow(query, id, ownerID, userID).Scan(&found, &blocked); err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
+
+func (r *ListRepositoryImpl) RemoveListMember(id, ownerID, userID uint64) error {
+	query := `
+		delete from list_members lm using lists l
+		where lm.list_id = l.id and l.id = $1 and l.user_id = $2 and lm.user_id = $3;
+	`
+	_, err := r.db.Exec(query, id, ownerID, userID)
+	return err
+}
+
+func (r *ListRepositoryImpl) GetListMembers(id, viewerID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + `
+		from list_members lm
+		join lists l on l.id = lm.list_id
+		join users on users.id = lm.user_id
+		where lm.list_id = $2 and users.deleted_at is null and ` + listVisibleTo("$1") + `
+		order by lm.created_at desc, users.id desc
+		offset $3 limit $4;`
+	rows, err := r.db.Query(query, viewerID, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *user)
+	}
+	return readDTO, rows.Err()
+}
+
+func (r *ListRepositoryImpl) SubscribeList(id, userID uint64) error {
+	query := `
+		with target as (
+			select l.id from lists l where l.id = $1 and ` + listVisibleTo("$2") + `
+		), inserted as (
+			insert into list_subscriptions (list_id, user_id)
+			select id, $2 from target
+			on conflict (list_id, user_id) do nothing
+			returning list_id
+		)
+		select exists (select 1 from target);
+	`
+	var found bool
+	if err := r.db.QueryRow(query, id, userID).Scan(&found); err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *ListRepositoryImpl) UnsubscribeList(id, userID uint64) error {
+	query := `delete from list_subscriptions where list_id = $1 and user_id = $2;`
+	_, err := r.db.Exec(query, id, userID)
+	return err
+}
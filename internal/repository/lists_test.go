@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var listRowColumns = []string{
+	"id", "user_id", "name", "description", "private",
+	"members_count", "subscribers_count", "is_subscribed", "created_at", "updated_at",
+}
+
+const listVisibleSQL = `(l.user_id = $1 or (not l.private and not exists (select 1 from blocks bk where (bk.blocker_id = $1 and bk.blocked_id = l.user_id)
+	or (bk.blocker_id = l.user_id and bk.blocked_id = $1))))`
+
+func TestListRepositoryImpl_CreateList(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into lists (user_id, name, description, private) values ($1, $2, $3, $4)`)).
+		WithArgs(uint64(1), "Gophers", "People who write Go", true).
+		WillReturnRows(sqlmock.NewRows(listRowColumns).AddRow(1, 1, "Gophers", "People who write Go", true, 0, 0, false, now, now))
+
+	list, err := r.CreateList(models.CreateListDTO{UserID: 1, Name: "Gophers", Description: "People who write Go", Private: true})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(1), list.ID, "ID mismatch")
+	assert.True(t, list.Private, "Private mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestListRepositoryImpl_GetListByID(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	query := regexp.QuoteMeta(`from lists l where l.id = $2 and ` + listVisibleSQL + `;`)
+	mock.ExpectQuery(query).WithArgs(uint64(2), uint64(1)).
+		WillReturnRows(sqlmock.NewRows(listRowColumns).AddRow(1, 1, "Gophers", "", false, 3, 1, true, now, now))
+	mock.ExpectQuery(query).WithArgs(uint64(2), uint64(5)).
+		WillReturnRows(sqlmock.NewRows(listRowColumns))
+
+	list, err := r.GetListByID(1, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint(3), list.MembersCount, "Members count mismatch")
+	assert.True(t, list.IsSubscribed, "Subscribed flag mismatch")
+	_, err = r.GetListByID(5, 2)
+	assert.Equal(t, ErrNotFound, err, "Error mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestListRepositoryImpl_GetLists(t *testing.T) {
+	testCases := []struct {
+		name  string
+		dto   models.FilterListDTO
+		query string
+	}{
+		{
+			name:  "Owned lists",
+			dto:   models.FilterListDTO{ViewerID: 1, OwnerID: 2, Limit: 10},
+			query: `and l.user_id = $2 order by l.created_at desc, l.id desc offset $3 limit $4;`,
+		},
+		{
+			name:  "Subscribed lists",
+			dto:   models.FilterListDTO{ViewerID: 1, SubscriberID: 2, Limit: 10},
+			query: `and exists (select 1 from list_subscriptions fs where fs.list_id = l.id and fs.user_id = $2) order by`,
+		},
+		{
+			name:  "Memberships leave out private lists",
+			dto:   models.FilterListDTO{ViewerID: 1, MemberID: 2, Limit: 10},
+			query: `and not l.private and exists (select 1 from list_members fm where fm.list_id = l.id and fm.user_id = $2) order by`,
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`from lists l where `+listVisibleSQL+` `+tc.query)).
+				WithArgs(uint64(1), uint64(2), uint64(0), uint64(10)).
+				WillReturnRows(sqlmock.NewRows(listRowColumns).AddRow(3, 2, "Gophers", "", false, 0, 0, false, now, now))
+
+			lists, err := r.GetLists(tc.dto)
+			assert.Nil(t, err, "Error is not nil")
+			assert.Len(t, lists, 1, "Lists count mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestListRepositoryImpl_UpdateList(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	private := true
+	mock.ExpectQuery(regexp.QuoteMeta(`update lists l set name = $1, private = $2, updated_at = now() where l.id = $4 and l.user_id = $3 returning`)).
+		WithArgs("Rustaceans", true, uint64(1), uint64(5)).
+		WillReturnRows(sqlmock.NewRows(listRowColumns).AddRow(5, 1, "Rustaceans", "", true, 0, 0, false, now, now))
+
+	list, err := r.UpdateList(5, 1, models.UpdateListDTO{Name: "Rustaceans", Private: &private})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, "Rustaceans", list.Name, "Name mismatch")
+	_, err = r.UpdateList(5, 1, models.UpdateListDTO{})
+	assert.Equal(t, ErrNoFieldsToUpdate, err, "Error mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestListRepositoryImpl_DeleteList(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`delete from lists where id = $1 and user_id = $2;`)
+	mock.ExpectExec(query).WithArgs(uint64(5), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(uint64(5), uint64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Nil(t, r.DeleteList(5, 1), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.DeleteList(5, 2), "Error mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestListRepositoryImpl_AddListMember(t *testing.T) {
+	testCases := []struct {
+		name    string
+		found   bool
+		blocked bool
+		err     error
+	}{
+		{name: "Success add", found: true},
+		{name: "Foreign list or missing user", err: ErrNotFound},
+		{name: "Blocked user", found: true, blocked: true, err: ErrBlocked},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`select exists (select 1 from target), exists (select 1 from target where blocked);`)).
+				WithArgs(uint64(5), uint64(1), uint64(2)).
+				WillReturnRows(sqlmock.NewRows([]string{"found", "blocked"}).AddRow(tc.found, tc.blocked))
+
+			assert.Equal(t, tc.err, r.AddListMember(5, 1, 2), "Error mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestListRepositoryImpl_GetListMembers(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	query := `select ` + avatarColumns("$1") + ` from list_members lm join lists l on l.id = lm.list_id join users on users.id = lm.user_id where lm.list_id = $2 and users.deleted_at is null and ` + listVisibleSQL + ` order by lm.created_at desc, users.id desc offset $3 limit $4;`
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(uint64(1), uint64(5), uint64(0), uint64(10)).
+		WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow(2, "jane", "Jane", "Doe", 1, now, now, nil, 0, 0, false, false, false, false))
+
+	users, err := r.GetListMembers(5, 1, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, users, 1, "Users count mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestListRepositoryImpl_SubscribeList(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &ListRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`
+		insert into list_subscriptions (list_id, user_id)
+		select id, $2 from target
+		on conflict (list_id, user_id) do nothing
+		returning list_id
+		)
+		select exists (select 1 from target);
+	`)
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(true))
+	mock.ExpectQuery(query).WithArgs(uint64(6), uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from list_subscriptions where list_id = $1 and user_id = $2;`)).
+		WithArgs(uint64(5), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Nil(t, r.SubscribeList(5, 1), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.SubscribeList(6, 1), "Error mismatch")
+	assert.Nil(t, r.UnsubscribeList(5, 1), "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+type MediaRepositoryImpl struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+func NewMediaRepositoryImpl(cfg *config.Config) *MediaRepositoryImpl {
+	db, err := sql.Open("pgx", cfg.DatabaseDSN)
+	if err != nil {
+		log.Fatal("Error connecting to database", err)
+		return nil
+	}
+	repository := &MediaRepositoryImpl{cfg: cfg, db: db}
+	return repository
+}
+
+func (r *MediaRepositoryImpl) CreateMedia(dto models.CreateMediaDTO) (*models.ReadMediaDTO, error) {
+	query := `
+		insert into media (user_id, storage_key, url, mime_type, size_bytes, width, height, alt_text)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning id, url, mime_type, width, height, alt_text;
+	`
+	var media models.ReadMediaDTO
+	err := r.db.QueryRow(
+		query, dto.UserID, dto.StorageKey, dto.URL, dto.MimeType, dto.SizeBytes, dto.Width, dto.Height, dto.AltText,
+	).Scan(&media.ID, &media.URL, &media.MimeType, &media.Width, &media.Height, &media.AltText)
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// GetMediaByID returns media the user uploaded, media not attached to any
+// post, and media of published posts the user is allowed to see.
+func (r *MediaRepositoryImpl) GetMediaByID(id, userID uint64) (*models.ReadMediaDTO, error) {
+	query := `
+		select m.id, m.url, m.mime_type, m.width, m.height, m.alt_text, m.blurhash, m.variants
+		from media m
+		left join posts p on p.id = m.post_id
+		where m.id = $1 and (m.user_id = $2 or m.post_id is null
+			or (p.deleted_at is null and p.state = 'published' and ` + visibleTo("$2") + `));
+	`
+	return scanMedia(r.db.QueryRow(query, id, userID))
+}
+
+func (r *MediaRepositoryImpl) UpdateMedia(id, ownerID uint64, dto models.UpdateMediaDTO) (*models.ReadMediaDTO, error) {
+	query := `
+		update media set alt_text = $1 where id = $2 and user_id = $3
+		returning id, url, mime_type, width, height, alt_text, blurhash, variants;
+	`
+	return scanMedia(r.db.QueryRow(query, dto.AltText, id, ownerID))
+}
+
+func (r *MediaRepositoryImpl) UpdateMediaVariants(id uint64, blurhash string, variants []models.ReadMediaVariantDTO) error {
+	query := `
+		update media set blurhash = $1, variants = $2 where id = $3;
+	`
+	data, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.Exec(query, blurhash, data, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanMedia(row rowScanner) (*models.ReadMediaDTO, error) {
+	var media models.ReadMediaDTO
+	var variants []byte
+	err := row.Scan(
+		&media.ID, &media.URL, &media.MimeType, &media.Width, &media.Height, &media.AltText, &media.Blurhash, &variants)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if variants != nil {
+		if err := json.Unmarshal(variants, &media.Variants); err != nil {
+			return nil, err
+		}
+	}
+	if len(media.Variants) == 0 {
+		media.Variants = nil
+	}
+	return &media, nil
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaRepositoryImpl_CreateMedia(t *testing.T) {
+	testCases := []struct {
+		name      string
+		createDTO models.CreateMediaDTO
+		readDTO   *models.ReadMediaDTO
+		hasError  bool
+	}{
+		{
+			name: "Success create",
+			createDTO: models.CreateMediaDTO{
+				UserID:     1,
+				StorageKey: "media/1.png",
+				URL:        "/v1.0/media/files/media/1.png",
+				MimeType:   "image/png",
+				SizeBytes:  1024,
+				Width:      640,
+				Height:     480,
+				AltText:    "A gopher",
+			},
+			readDTO: &models.ReadMediaDTO{
+				ID:       1,
+				URL:      "/v1.0/media/files/media/1.png",
+				MimeType: "image/png",
+				Width:    640,
+				Height:   480,
+				AltText:  "A gopher",
+			},
+			hasError: false,
+		},
+		{
+			name: "Error on insert SQL",
+			createDTO: models.CreateMediaDTO{
+				UserID:     1,
+				StorageKey: "media/1.png",
+			},
+			hasError: true,
+		},
+	}
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &MediaRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`
+		insert into media (user_id, storage_key, url, mime_type, size_bytes, width, height, alt_text)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning id, url, mime_type, width, height, alt_text;
+	`)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expect := mock.ExpectQuery(query).WithArgs(
+				tc.createDTO.UserID,
+				tc.createDTO.StorageKey,
+				tc.createDTO.URL,
+				tc.createDTO.MimeType,
+				tc.createDTO.SizeBytes,
+				tc.createDTO.Width,
+				tc.createDTO.Height,
+				tc.createDTO.AltText,
+			)
+			if !tc.hasError {
+				expect.WillReturnRows(sqlmock.NewRows(
+					[]string{"id", "url", "mime_type", "width", "height", "alt_text"},
+				).AddRow(
+					tc.readDTO.ID, tc.readDTO.URL, tc.readDTO.MimeType, tc.readDTO.Width, tc.readDTO.Height, tc.readDTO.AltText,
+				))
+			} else {
+				expect.WillReturnError(sql.ErrConnDone)
+			}
+			media, err := r.CreateMedia(tc.createDTO)
+			if tc.hasError {
+				assert.NotNil(t, err, "Error is nil")
+				assert.Nil(t, media, "Media should be nil")
+			} else {
+				assert.Nil(t, err, "Error is not nil")
+				assert.Equal(t, tc.readDTO, media, "Media mismatch")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestMediaRepositoryImpl_GetMediaByID(t *testing.T) {
+	testCases := []struct {
+		name    string
+		id      uint64
+		readDTO *models.ReadMediaDTO
+		err     error
+	}{
+		{
+			name: "Success get media by ID",
+			id:   1,
+			readDTO: &models.ReadMediaDTO{
+				ID:       1,
+				URL:      "/v1.0/media/files/media/1.png",
+				MimeType: "image/png",
+				Width:    640,
+				Height:   480,
+				Blurhash: "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+				Variants: []models.ReadMediaVariantDTO{
+					{Name: "thumb", URL: "/v1.0/media/files/media/1_thumb.png", MimeType: "image/png", Width: 150, Height: 150},
+				},
+			},
+			err: nil,
+		},
+		{
+			name:    "Media not found or hidden",
+			id:      2,
+			readDTO: nil,
+			err:     ErrNotFound,
+		},
+	}
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &MediaRepositoryImpl{cfg: &cfg, db: db}
+	query := `select m\.id, m\.url, m\.mime_type, m\.width, m\.height, m\.alt_text, m\.blurhash, m\.variants from media m ` +
+		`left join posts p on p\.id = m\.post_id where m\.id = \$1 and \(m\.user_id = \$2 or m\.post_id is null ` +
+		`or \(p\.deleted_at is null and p\.state = 'published' and \(\(p\.visibility = 'public' or p\.user_id = \$2 .*\)\);`
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == nil {
+				variants, _ := json.Marshal(tc.readDTO.Variants)
+				mock.ExpectQuery(query).WithArgs(tc.id, uint64(3)).WillReturnRows(sqlmock.NewRows(
+					[]string{"id", "url", "mime_type", "width", "height", "alt_text", "blurhash", "variants"},
+				).AddRow(
+					tc.readDTO.ID, tc.readDTO.URL, tc.readDTO.MimeType, tc.readDTO.Width, tc.readDTO.Height, tc.readDTO.AltText,
+					tc.readDTO.Blurhash, variants,
+				))
+			} else {
+				mock.ExpectQuery(query).WithArgs(tc.id, uint64(3)).WillReturnError(sql.ErrNoRows)
+			}
+			media, err := r.GetMediaByID(tc.id, 3)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			assert.Equal(t, tc.readDTO, media, "Media mismatch")
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestMediaRepositoryImpl_UpdateMedia(t *testing.T) {
+	testCases := []struct {
+		name      string
+		id        uint64
+		ownerID   uint64
+		updateDTO models.UpdateMediaDTO
+		readDTO   *models.ReadMediaDTO
+		err       error
+	}{
+		{
+			name:      "Success update alt text",
+			id:        1,
+			ownerID:   1,
+			updateDTO: models.UpdateMediaDTO{AltText: "A gopher"},
+			readDTO: &models.ReadMediaDTO{
+				ID:       1,
+				URL:      "/v1.0/media/files/media/1.png",
+				MimeType: "image/png",
+				Width:    640,
+				Height:   480,
+				AltText:  "A gopher",
+			},
+			err: nil,
+		},
+		{
+			name:      "Media belongs to other user",
+			id:        1,
+			ownerID:   2,
+			updateDTO: models.UpdateMediaDTO{AltText: "A gopher"},
+			readDTO:   nil,
+			err:       ErrNotFound,
+		},
+	}
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &MediaRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`
+		update media set alt_text = $1 where id = $2 and user_id = $3
+		returning id, url, mime_type, width, height, alt_text, blurhash, variants;
+	`)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expect := mock.ExpectQuery(query).WithArgs(tc.updateDTO.AltText, tc.id, tc.ownerID)
+			if tc.err == nil {
+				expect.WillReturnRows(sqlmock.NewRows(
+					[]string{"id", "url", "mime_type", "width", "height", "alt_text", "blurhash", "variants"},
+				).AddRow(
+					tc.readDTO.ID, tc.readDTO.URL, tc.readDTO.MimeType, tc.readDTO.Width, tc.readDTO.Height, tc.readDTO.AltText,
+					tc.readDTO.Blurhash, []byte("[]"),
+				))
+			} else {
+				expect.WillReturnError(sql.ErrNoRows)
+			}
+			media, err := r.UpdateMedia(tc.id, tc.ownerID, tc.updateDTO)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			assert.Equal(t, tc.readDTO, media, "Media mismatch")
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestMediaRepositoryImpl_UpdateMediaVariants(t *testing.T) {
+	variants := []models.ReadMediaVariantDTO{
+		{Name: "thumb", URL: "/v1.0/media/files/media/1_thumb.png", MimeType: "image/png", Width: 150, Height: 150},
+	}
+	data, _ := json.Marshal(variants)
+	testCases := []struct {
+		name     string
+		id       uint64
+		affected int64
+		err      error
+	}{
+		{
+			name:     "Success update variants",
+			id:       1,
+			affected: 1,
+			err:      nil,
+		},
+		{
+			name:     "Media not found",
+			id:       2,
+			affected: 0,
+			err:      ErrNotFound,
+		},
+	}
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &MediaRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`update media set blurhash = $1, variants = $2 where id = $3;`)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs("LEHV6nWB2yk8pyo0adR*.7kCMdnj", data, tc.id).
+				WillReturnResult(sqlmock.NewResult(0, tc.affected))
+			err := r.UpdateMediaVariants(tc.id, "LEHV6nWB2yk8pyo0adR*.7kCMdnj", variants)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// notificationActorsShown is how many of the latest actors are returned with
+// every notification.
+const notificationActorsShown = 3
+
+type NotificationRepositoryImpl struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+func NewNotificationRepositoryImpl(cfg *config.Config) *NotificationRepositoryImpl {
+	db, err := sql.Open("pgx", cfg.DatabaseDSN)
+	if err != nil {
+		log.Fatal("Error connecting to database", err)
+		return nil
+	}
+	repository := &NotificationRepositoryImpl{cfg: cfg, db: db}
+	return repository
+}
+
+// notificationsFrom returns common table expressions, to follow a with
+// keyword, that record the events selected by source as notifications. The
+// source yields the recipient, actor, type, post and time of every event.
+// Events are dropped when the actor is the recipient, either of them blocked
+// the other, the recipient muted the actor or the thread, turned the type off
+// or cannot see the post. An event joins the recipient's unread notification
+// of the same type and post, which makes "X and 12 others liked your post" a
+// single row. The last expression, notified, returns the notifications that
+// gained an actor.
+func notificationsFrom(source string) string {
+	return `events (user_id, actor_id, type, post_id, created_at) as (` + source + `
+		), accepted as (
+			select e.user_id, e.actor_id, e.type, e.post_id, e.created_at from events e
+			where e.user_id <> e.actor_id and not ` + blockedBetween("e.user_id", "e.actor_id") + `
+			and not exists (select 1 from muted_users nm where nm.user_id = e.user_id and nm.muted_id = e.actor_id)
+			and not exists (
+				select 1 from notification_preferences np where np.user_id = e.user_id and np.type = e.type and not np.enabled
+			)
+			and (e.post_id is null or exists (
+				select 1 from posts p where p.id = e.post_id and p.deleted_at is null and ` + visibleTo("e.user_id") + `
+				and not ` + threadMuted("e.user_id", "p.id") + `
+			))
+		), grouped as (
+			insert into notifications (user_id, type, post_id, created_at, updated_at)
+			select user_id, type, post_id, min(created_at), max(created_at) from accepted
+			group by user_id, type, post_id
+			on conflict (user_id, type, (coalesce(post_id, 0))) where read_at is null
+			do update set updated_at = greatest(notifications.updated_at, excluded.updated_at)
+			returning id, user_id, type, post_id
+		), notified as (
+			insert into notification_actors (notification_id, actor_id, created_at)
+			select g.id, a.actor_id, max(a.created_at) from grouped g
+			join accepted a on a.user_id = g.user_id and a.type = g.type and a.post_id is not distinct from g.post_id
+			group by g.id, a.actor_id
+			on conflict (notification_id, actor_id) do nothing
+			returning notification_id
+		)`
+}
+
+// notifiedSelect ends a statement built with notificationsFrom. It returns
+// the notifications that gained an actor along with their recipients.
+const notifiedSelect = `select g.id, g.user_id, g.type from grouped g where g.id in (select notification_id from notified);`
+
+type notified struct {
+	id               uint64
+	userID           uint64
+	notificationType string
+}
+
+func scanNotified(rows *sql.Rows) ([]notified, error) {
+	defer rows.Close()
+	found := []notified{}
+	for rows.Next() {
+		var n notified
+		if err := rows.Scan(&n.id, &n.userID, &n.notificationType); err != nil {
+			return nil, err
+		}
+		found = append(found, n)
+	}
+	return found, rows.Err()
+}
+
+// publishNotified tells the recipients' streams about their notifications.
+func publishNotified(hub *events.Hub, found []notified) {
+	for _, n := range found {
+		hub.Publish(events.UserTopic(n.userID), events.TypeNotification, models.StreamNotificationDTO{
+			ID:   n.id,
+			Type: n.notificationType,
+		})
+	}
+}
+
+// notifyPublished tells the parent's author about a reply and the mentioned
+// users about a mention, and queues the post.created webhooks. It runs once a
+// post is published, so drafts and scheduled posts notify nobody until then.
+func (r *PostRepositoryImpl) notifyPublished(postID uint64) {
+	query := `with ` + notificationsFrom(`
+			select parent.user_id, p.user_id, 'reply', p.id, p.created_at
+			from posts p join posts parent on parent.id = p.reply_to_id
+			where p.id = $1
+			union all
+			select pm.user_id, p.user_id, 'mention', p.id, p.created_at
+			from post_mentions pm join posts p on p.id = pm.post_id
+			where pm.post_id = $1 and not exists (
+				select 1 from posts rp where rp.id = p.reply_to_id and rp.user_id = pm.user_id
+			)`) + `, ` + webhooksFrom(`
+			select 'post.created', p.user_id, json_build_object(
+				'id', p.id, 'user_id', p.user_id, 'reply_to_id', p.reply_to_id, 'text', p.text, 'created_at', p.created_at
+			)::jsonb
+			from posts p where p.id = $1`) + notifiedSelect
+	rows, err := r.db.Query(query, postID)
+	if err == nil {
+		var found []notified
+		if found, err = scanNotified(rows); err == nil {
+			publishNotified(r.hub, found)
+			return
+		}
+	}
+	log.Printf("Failed to notify about post %d: %v", postID, err)
+}
+
+// GetNotifications lists the user's notifications, most recently updated
+// first, with the latest actors of each.
+func (r *NotificationRepositoryImpl) GetNotifications(
+	userID uint64,
+	cursor *models.Cursor,
+	limit uint64,
+) ([]models.ReadNotificationDTO, error) {
+	query := `
+		select n.id, n.type, n.post_id, n.created_at, n.updated_at, n.read_at,
+			(select count(*) from notification_actors na where na.notification_id = n.id) as actors_count
+		from notifications n
+		where n.user_id = $1
+		and (n.post_id is null or exists (select 1 from posts p where p.id = n.post_id and p.deleted_at is null))`
+	params := []interface{}{userID}
+	direction := "desc"
+	if cursor != nil {
+		operator := "<"
+		if cursor.Backward {
+			direction, operator = "asc", ">"
+		}
+		if cursor.ID > 0 {
+			query += fmt.Sprintf(" and (n.updated_at, n.id) %s ($2, $3)", operator)
+			params = append(params, cursor.CreatedAt, cursor.ID)
+		}
+	}
+	query += fmt.Sprintf(" order by n.updated_at %s, n.id %s limit $%d;", direction, direction, len(params)+1)
+	params = append(params, limit)
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadNotificationDTO = make([]models.ReadNotificationDTO, 0)
+	for rows.Next() {
+		notification := models.ReadNotificationDTO{Actors: []models.ReadUserDTO{}}
+		err := rows.Scan(
+			&notification.ID, &notification.Type, &notification.PostID, &notification.CreatedAt,
+			&notification.UpdatedAt, &notification.ReadAt, &notification.ActorsCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(readDTO)-1; i < j; i, j = i+1, j-1 {
+			readDTO[i], readDTO[j] = readDTO[j], readDTO[i]
+		}
+	}
+	if err := r.loadActors(userID, readDTO); err != nil {
+		return nil, err
+	}
+	return readDTO, nil
+}
+
+// loadActors fills in the latest actors of the notifications.
+func (r *NotificationRepositoryImpl) loadActors(viewerID uint64, notifications []models.ReadNotificationDTO) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	params := []interface{}{viewerID, notificationActorsShown}
+	placeholders := []string{}
+	index := make(map[uint64]int)
+	for i, notification := range notifications {
+		params = append(params, notification.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+		index[notification.ID] = i
+	}
+	query := fmt.Sprintf(`
+		select %s, na.notification_id from (
+			select notification_id, actor_id, created_at,
+				row_number() over (partition by notification_id order by created_at desc, actor_id desc) as position
+			from notification_actors where notification_id in (%s)
+		) na
+		join users on users.id = na.actor_id
+		where na.position <= $2 and users.deleted_at is null
+		order by na.notification_id, na.position;
+	`, userColumns("$1"), strings.Join(placeholders, ", "))
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var notificationID uint64
+		user, err := scanUser(rows, &notificationID)
+		if err != nil {
+			return err
+		}
+		i := index[notificationID]
+		notifications[i].Actors = append(notifications[i].Actors, *user)
+	}
+	return rows.Err()
+}
+
+func (r *NotificationRepositoryImpl) CountUnread(userID uint64) (uint64, error) {
+	query := `
+		select count(*) from notifications n
+		where n.user_id = $1 and n.read_at is null
+		and (n.post_id is null or exists (select 1 from posts p where p.id = n.post_id and p.deleted_at is null));
+	`
+	var count uint64
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead marks one notification as read. Events that arrive afterwards
+// start a new notification instead of joining it.
+func (r *NotificationRepositoryImpl) MarkRead(id, userID uint64) error {
+	query := `
+		update notifications set read_at = coalesce(read_at, now())
+		where id = $1 and user_id = $2
+		returning id;
+	`
+	err := r.db.QueryRow(query, id, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *NotificationRepositoryImpl) MarkAllRead(userID uint64) error {
+	query := `update notifications set read_at = now() where user_id = $1 and read_at is null;`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+// GetPreferences reports for every notification type whether the user
+// receives it. Types the user never changed are on.
+func (r *NotificationRepositoryImpl) GetPreferences(userID uint64) (models.NotificationPreferencesDTO, error) {
+	preferences := make(models.NotificationPreferencesDTO)
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = true
+	}
+	query := `select type, enabled from notification_preferences where user_id = $1;`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var notificationType string
+		var enabled bool
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, err
+		}
+		if _, ok := preferences[notificationType]; ok {
+			preferences[notificationType] = enabled
+		}
+	}
+	return preferences, rows.Err()
+}
+
+// UpdatePreferences stores the given types and leaves the others as they were.
+func (r *NotificationRepositoryImpl) UpdatePreferences(userID uint64, dto models.NotificationPreferencesDTO) error {
+	params := []interface{}{userID}
+	values := []string{}
+	for _, notificationType := range models.NotificationTypes {
+		enabled, ok := dto[notificationType]
+		if !ok {
+			continue
+		}
+		params = append(params, notificationType, enabled)
+		values = append(values, fmt.Sprintf("($1, $%d, $%d)", len(params)-1, len(params)))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`
+		insert into notification_preferences (user_id, type, enabled) values %s
+		on conflict (user_id, type) do update set enabled = excluded.enabled;
+	`, strings.Join(values, ", "))
+	_, err := r.db.Exec(query, params...)
+	return err
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var notificationRowColumns = []string{"id", "type", "post_id", "created_at", "updated_at", "read_at", "actors_count"}
+
+func TestNotificationRepositoryImpl_GetNotifications(t *testing.T) {
+	now := time.Now()
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &NotificationRepositoryImpl{cfg: &cfg, db: db}
+	query := `
+		select n.id, n.type, n.post_id, n.created_at, n.updated_at, n.read_at,
+			(select count(*) from notification_actors na where na.notification_id = n.id) as actors_count
+		from notifications n
+		where n.user_id = $1
+		and (n.post_id is null or exists (select 1 from posts p where p.id = n.post_id and p.deleted_at is null))
+		and (n.updated_at, n.id) < ($2, $3) order by n.updated_at desc, n.id desc limit $4;`
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(uint64(1), now, uint64(20), uint64(11)).
+		WillReturnRows(sqlmock.NewRows(notificationRowColumns).
+			AddRow(12, models.NotificationTypeLike, 5, now, now, nil, 13).
+			AddRow(11, models.NotificationTypeFollow, nil, now, now, now, 1))
+	actorsQuery := `select ` + avatarColumns("$1") + `, na.notification_id from (
+			select notification_id, actor_id, created_at,
+				row_number() over (partition by notification_id order by created_at desc, actor_id desc) as position
+			from notification_actors where notification_id in ($3, $4)
+		) na
+		join users on users.id = na.actor_id
+		where na.position <= $2 and users.deleted_at is null
+		order by na.notification_id, na.position;`
+	mock.ExpectQuery(regexp.QuoteMeta(actorsQuery)).
+		WithArgs(uint64(1), notificationActorsShown, uint64(12), uint64(11)).
+		WillReturnRows(sqlmock.NewRows(append(userRowColumns, "notification_id")).
+			AddRow(4, "bob", "Bob", "Smith", 1, now, now, nil, 0, 0, false, false, false, false, 11).
+			AddRow(3, "john", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, 12).
+			AddRow(2, "jane", "Jane", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, 12))
+
+	notifications, err := r.GetNotifications(1, &models.Cursor{CreatedAt: now, ID: 20}, 11)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, notifications, 2, "Notifications count mismatch")
+	assert.Equal(t, uint(13), notifications[0].ActorsCount, "Actors count mismatch")
+	assert.Len(t, notifications[0].Actors, 2, "Latest actors mismatch")
+	assert.Equal(t, "john", notifications[0].Actors[0].UserName, "Latest actor should come first")
+	assert.Nil(t, notifications[1].PostID, "Follow notifications have no post")
+	assert.NotNil(t, notifications[1].ReadAt, "Read time is missing")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestNotificationRepositoryImpl_MarkRead(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &NotificationRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`
+		update notifications set read_at = coalesce(read_at, now())
+		where id = $1 and user_id = $2
+		returning id;
+	`)
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	assert.Nil(t, r.MarkRead(5, 1), "Error is not nil")
+	mock.ExpectQuery(query).WithArgs(uint64(6), uint64(1)).WillReturnError(sql.ErrNoRows)
+	assert.Equal(t, ErrNotFound, r.MarkRead(6, 1), "Foreign notifications should not be found")
+
+	mock.ExpectExec(regexp.QuoteMeta(`update notifications set read_at = now() where user_id = $1 and read_at is null;`)).
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	assert.Nil(t, r.MarkAllRead(1), "Error is not nil")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*) from notifications n where n.user_id = $1 and n.read_at is null`)).
+		WithArgs(uint64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	count, err := r.CountUnread(1)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(0), count, "Unread count mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestNotificationRepositoryImpl_Preferences(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &NotificationRepositoryImpl{cfg: &cfg, db: db}
+	mock.ExpectExec(regexp.QuoteMeta(`
+		insert into notification_preferences (user_id, type, enabled) values ($1, $2, $3), ($1, $4, $5)
+		on conflict (user_id, type) do update set enabled = excluded.enabled;
+	`)).
+		WithArgs(uint64(1), models.NotificationTypeLike, false, models.NotificationTypeFollow, true).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	err = r.UpdatePreferences(1, models.NotificationPreferencesDTO{
+		models.NotificationTypeFollow: true,
+		models.NotificationTypeLike:   false,
+	})
+	assert.Nil(t, err, "Error is not nil")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select type, enabled from notification_preferences where user_id = $1;`)).
+		WithArgs(uint64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "enabled"}).
+			AddRow(models.NotificationTypeLike, false).
+			AddRow(models.NotificationTypeFollow, true))
+	preferences, err := r.GetPreferences(1)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, preferences, len(models.NotificationTypes), "Every type should be reported")
+	assert.False(t, preferences[models.NotificationTypeLike], "Likes should be off")
+	assert.True(t, preferences[models.NotificationTypeMention], "Untouched types should be on")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
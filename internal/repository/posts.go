@@ -2,13 +2,16 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
 )
 
 type ReplyBuffer struct {
@@ -24,9 +27,12 @@ type PostRepositoryImpl struct {
 	vb  *ViewBuffer
 	lb  *LikeBuffer
 	rb  *ReplyBuffer
+	pb  *VoteBuffer
+	tb  *FanoutBuffer
+	hub *events.Hub
 }
 
-func NewPostRepositoryImpl(cfg *config.Config) *PostRepositoryImpl {
+func NewPostRepositoryImpl(cfg *config.Config, hub *events.Hub) *PostRepositoryImpl {
 	db, err := sql.Open("pgx", cfg.DatabaseDSN)
 	if err != nil {
 		log.Fatal("Error connecting to database", err)
@@ -38,6 +44,10 @@ func NewPostRepositoryImpl(cfg *config.Config) *PostRepositoryImpl {
 	likesBufferTimer := 5 * time.Second
 	replyBufferTimer := 10 * time.Second
 	replyBufferSize := 10
+	votesBufferTimer := 5 * time.Second
+	votesBufferSize := 100
+	fanoutBufferTimer := time.Second
+	fanoutBufferSize := 100
 	vb := &ViewBuffer{
 		buffer:     make([]View, 0, viewsBufferSize),
 		maxRecords: viewsBufferSize,
@@ -54,37 +64,117 @@ func NewPostRepositoryImpl(cfg *config.Config) *PostRepositoryImpl {
 		maxRecords: replyBufferSize,
 		timer:      replyBufferTimer,
 	}
-	repository := &PostRepositoryImpl{cfg: cfg, db: db, vb: vb, lb: lb, rb: rb}
+	pb := &VoteBuffer{
+		options:    make(map[uint64]int),
+		voters:     make(map[uint64]int),
+		maxRecords: votesBufferSize,
+		timer:      votesBufferTimer,
+	}
+	tb := &FanoutBuffer{
+		buffer:     make([]uint64, 0, fanoutBufferSize),
+		maxRecords: fanoutBufferSize,
+		timer:      fanoutBufferTimer,
+	}
+	repository := &PostRepositoryImpl{cfg: cfg, db: db, vb: vb, lb: lb, rb: rb, pb: pb, tb: tb, hub: hub}
 	go repository.startViewsTimer()
 	go repository.startLikesTimer()
 	go repository.startDislikesTimer()
 	go repository.startRepliesTimer()
+	go repository.startVotesTimer()
+	go repository.startFanoutTimer()
+	go repository.startPublisher(cfg.PublisherInterval)
+	go repository.startTimelineJobs()
 	return repository
 }
 
 func (r *PostRepositoryImpl) CreatePost(dto models.CreatePostDTO) (*models.ReadPostDTO, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
 	query := `
-		insert into posts (text, user_id, reply_to_id) values ($1, $2, $3)
-		returning id, text, created_at, reply_to_id;
+		insert into posts (text, user_id, reply_to_id, state, publish_at, visibility, reply_policy, search_config)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning id, text, created_at, reply_to_id, state, publish_at, visibility, reply_policy;
 	`
+	state := dto.State
+	if state == "" {
+		state = models.PostStatePublished
+	}
+	visibility := dto.Visibility
+	if visibility == "" {
+		visibility = models.PostVisibilityPublic
+	}
+	replyPolicy := dto.ReplyPolicy
+	if replyPolicy == "" {
+		replyPolicy = models.ReplyPolicyEveryone
+	}
+	if dto.ReplyToID != nil {
+		visibility, err = replyVisibility(tx, *dto.ReplyToID, dto.UserID, visibility)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 	var post models.ReadPostDTO
-	err := r.db.QueryRow(
-		query, dto.Text, dto.UserID, dto.ReplyToID).Scan(&post.ID, &post.Text, &post.CreatedAt, &post.ReplyToID)
+	err = tx.QueryRow(
+		query, dto.Text, dto.UserID, dto.ReplyToID, state, dto.PublishAt, visibility, replyPolicy, searchConfig(dto.Language),
+	).Scan(&post.ID, &post.Text, &post.CreatedAt, &post.ReplyToID, &post.State, &post.PublishAt, &post.Visibility, &post.ReplyPolicy)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
-	if dto.ReplyToID != nil && *dto.ReplyToID > 0 {
-		r.rb.lock.Lock()
-		defer r.rb.lock.Unlock()
-		r.rb.buffer[*dto.ReplyToID]++
-		if r.rb.buffer[*dto.ReplyToID] > r.rb.maxRecords {
-			r.flushReplies()
+	// authors can always reply to their own posts
+	post.CanReply = true
+	if len(dto.Mentions) > 0 {
+		entities, err := r.createMentions(tx, post.ID, dto.UserID, dto.Mentions)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		post.Entities = entities
+	}
+	if len(dto.MediaIDs) > 0 {
+		media, err := r.attachMedia(tx, post.ID, dto.UserID, dto.MediaIDs)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		post.Media = media
+	}
+	if dto.Poll != nil {
+		poll, err := r.createPoll(tx, post.ID, dto.Poll)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
 		}
+		post.Poll = poll
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	if state == models.PostStatePublished {
+		r.addReply(dto.ReplyToID)
+		r.fanOut(post.ID, dto.ReplyToID)
+		r.notifyPublished(post.ID)
 	}
 	return &post, nil
 }
 
+func (r *PostRepositoryImpl) addReply(replyToID *uint64) {
+	if replyToID == nil || *replyToID == 0 {
+		return
+	}
+	r.rb.lock.Lock()
+	defer r.rb.lock.Unlock()
+	r.rb.buffer[*replyToID]++
+	if r.rb.buffer[*replyToID] > r.rb.maxRecords {
+		r.flushReplies()
+	}
+}
+
 func (r *PostRepositoryImpl) flushReplies() {
+	postIDs := make([]uint64, 0, len(r.rb.buffer))
 	for postID, count := range r.rb.buffer {
 		_, err := r.db.Exec(`
 			update posts
@@ -93,9 +183,12 @@ func (r *PostRepositoryImpl) flushReplies() {
 		`, count, postID)
 		if err != nil {
 			log.Printf("Failed to update replies_count for post %d: %v", postID, err)
+			continue
 		}
+		postIDs = append(postIDs, postID)
 	}
 	r.rb.buffer = make(map[uint64]int)
+	r.publishCounters(postIDs)
 }
 
 func (r *PostRepositoryImpl) startRepliesTimer() {
@@ -175,14 +268,46 @@ func (r *PostRepositoryImpl) GetAllPosts(dto models.FilterPostDTO) ([]models.Rea
 				posts[i].UserViewed = true
 			}
 		}
+		if err := r.fillPollVotes(posts, dto.UserID); err != nil {
+			return nil, err
+		}
 		return posts, nil
 	case err := <-errChan:
 		return nil, err
 	}
 }
 
+func (r *PostRepositoryImpl) GetPostByID(id, userID uint64) (*models.ReadPostDTO, error) {
+	posts, err := r.GetAllPosts(models.FilterPostDTO{PostID: id, UserID: userID, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, ErrNotFound
+	}
+	return &posts[0], nil
+}
+
 func (r *PostRepositoryImpl) fetchPosts(dto models.FilterPostDTO) ([]models.ReadPostDTO, error) {
-	query := `
+	// a profile listing starts with the owner's pinned posts
+	withPinned := dto.OwnerID > 0 && dto.ReplyToID == 0 && dto.MentionedID == 0
+	pinnedColumn, pinnedJoin := "false", ""
+	if withPinned {
+		pinnedColumn = "pp.pinned_at is not null"
+		pinnedJoin = "left join pinned_posts pp on pp.post_id = p.id"
+	}
+	textQuery := ""
+	if dto.Query != nil {
+		textQuery = searchText(dto.Query)
+	}
+	highlightColumn := "null"
+	byRelevance := false
+	if textQuery != "" {
+		highlightColumn = searchHeadline("$2")
+		// keyset pages can only follow recency
+		byRelevance = dto.Sort != models.PostSortRecent && !dto.Paged
+	}
+	query := fmt.Sprintf(`
 		select 
 			p.id as post_id,
 			p.text,
@@ -195,15 +320,78 @@ func (r *PostRepositoryImpl) fetchPosts(dto models.FilterPostDTO) ([]models.Read
 			u.deleted_at,
 			p.likes_count,
 			p.views_count,
-			p.replies_count
+			p.replies_count,
+			(
+				select json_agg(json_build_object(
+					'type', 'mention',
+					'offset', pm.start_offset,
+					'length', pm.length,
+					'user_id', mu.id,
+					'user_name', mu.user_name
+				) order by pm.start_offset)
+				from post_mentions pm
+				join users mu on pm.user_id = mu.id and mu.deleted_at is null
+				where pm.post_id = p.id
+			) as entities,
+			(
+				select json_agg(json_build_object(
+					'id', m.id,
+					'url', m.url,
+					'mime_type', m.mime_type,
+					'width', m.width,
+					'height', m.height,
+					'alt_text', m.alt_text,
+					'blurhash', m.blurhash,
+					'variants', m.variants
+				) order by m.position)
+				from media m
+				where m.post_id = p.id
+			) as media,
+			(
+				select json_build_object(
+					'id', pl.id,
+					'multiple', pl.multiple,
+					'closes_at', pl.closes_at,
+					'closed', pl.closes_at <= now(),
+					'voters_count', pl.voters_count,
+					'options', (
+						select json_agg(json_build_object(
+							'id', po.id,
+							'text', po.text,
+							'votes_count', po.votes_count
+						) order by po.position)
+						from poll_options po
+						where po.poll_id = pl.id
+					)
+				)
+				from polls pl
+				where pl.post_id = p.id
+			) as poll,
+			%s as pinned,
+			p.visibility,
+			p.reply_policy,
+			%s as can_reply,
+			%s as highlight
 		from posts p
 		join users u on p.user_id = u.id
-		where p.deleted_at is null
-	`
-	params := []interface{}{}
-	if dto.Search != "" {
-		query += " and p.text ilike $1"
-		params = append(params, "%"+dto.Search+"%")
+		%s
+		where p.deleted_at is null and p.state = 'published'
+	`, pinnedColumn, canReply("$1"), highlightColumn, pinnedJoin)
+	// the viewer is always bound to $1 and the search text to $2
+	query += " and " + visibleTo("$1")
+	// mutes thin out listings but never hide a profile, a thread or a post
+	if dto.OwnerID == 0 && dto.ReplyToID == 0 && dto.PostID == 0 {
+		query += " and " + notMuted("$1")
+	}
+	params := []interface{}{dto.UserID}
+	if textQuery != "" {
+		query += " and p.search_vector @@ " + searchQuery("$2")
+		params = append(params, textQuery)
+	}
+	if dto.Query != nil {
+		var conditions string
+		conditions, params = compileSearch(dto.Query, params)
+		query += conditions
 	}
 
 	if dto.OwnerID > 0 {
@@ -211,11 +399,71 @@ func (r *PostRepositoryImpl) fetchPosts(dto models.FilterPostDTO) ([]models.Read
 		params = append(params, dto.OwnerID)
 	}
 
-	if dto.ReplyToID > 0 {
-		query += fmt.Sprintf(" and p.reply_to_id = $%d order by p.created_at asc", len(params)+1)
-		params = append(params, dto.ReplyToID)
+	if dto.MentionedID > 0 {
+		query += fmt.Sprintf(" and exists (select 1 from post_mentions pm where pm.post_id = p.id and pm.user_id = $%d)", len(params)+1)
+		params = append(params, dto.MentionedID)
+	}
+
+	if dto.Home {
+		query += " and " + homeTimeline("$1", fmt.Sprintf("$%d", len(params)+1))
+		params = append(params, r.cfg.TimelineFanoutLimit)
+	}
+
+	if dto.ListID > 0 {
+		query += " and " + listTimeline("$1", fmt.Sprintf("$%d", len(params)+1))
+		params = append(params, dto.ListID)
+	}
+
+	if dto.PostID > 0 {
+		query += fmt.Sprintf(" and p.id = $%d", len(params)+1)
+		params = append(params, dto.PostID)
 	} else {
-		query += " and p.reply_to_id is null order by p.created_at desc"
+		// threads read oldest first, every other listing newest first
+		ascending := dto.ReplyToID > 0
+		if dto.ReplyToID > 0 {
+			query += fmt.Sprintf(" and p.reply_to_id = $%d", len(params)+1)
+			params = append(params, dto.ReplyToID)
+		} else if dto.MentionedID == 0 && (dto.Query == nil || !dto.Query.HasKind(search.KindReply)) {
+			query += " and p.reply_to_id is null"
+		}
+		if dto.Cursor != nil {
+			// pinned posts only lead the first page
+			if withPinned {
+				query += " and pp.post_id is null"
+			}
+			// a backward page is read in reverse and flipped after scanning
+			if dto.Cursor.Backward {
+				ascending = !ascending
+			}
+			direction, operator := "desc", "<"
+			if ascending {
+				direction, operator = "asc", ">"
+			}
+			if dto.Cursor.ID > 0 {
+				query += fmt.Sprintf(" and (p.created_at, p.id) %s ($%d, $%d)", operator, len(params)+1, len(params)+2)
+				params = append(params, dto.Cursor.CreatedAt, dto.Cursor.ID)
+			}
+			query += fmt.Sprintf(" order by p.created_at %s, p.id %s", direction, direction)
+		} else if dto.Paged {
+			// the first keyset page must follow the order the cursors resume
+			direction := "desc"
+			if ascending {
+				direction = "asc"
+			}
+			query += " order by "
+			if withPinned {
+				query += "pp.pinned_at desc nulls last, "
+			}
+			query += fmt.Sprintf("p.created_at %s, p.id %s", direction, direction)
+		} else if byRelevance {
+			query += " order by ts_rank(p.search_vector, " + searchQuery("$2") + ") desc, p.created_at desc"
+		} else if withPinned {
+			query += " order by pp.pinned_at desc nulls last, p.created_at desc, p.id desc"
+		} else if ascending {
+			query += " order by p.created_at asc"
+		} else {
+			query += " order by p.created_at desc"
+		}
 	}
 
 	query += fmt.Sprintf(" offset $%d limit $%d", len(params)+1, len(params)+2)
@@ -231,6 +479,8 @@ func (r *PostRepositoryImpl) fetchPosts(dto models.FilterPostDTO) ([]models.Read
 	for rows.Next() {
 		var post models.ReadPostDTO
 		var user models.ReadPostUserDTO
+		var entities, media, poll []byte
+		var highlight sql.NullString
 		err := rows.Scan(
 			&post.ID,
 			&post.Text,
@@ -244,10 +494,34 @@ func (r *PostRepositoryImpl) fetchPosts(dto models.FilterPostDTO) ([]models.Read
 			&post.LikesCount,
 			&post.ViewsCount,
 			&post.RepliesCount,
+			&entities,
+			&media,
+			&poll,
+			&post.Pinned,
+			&post.Visibility,
+			&post.ReplyPolicy,
+			&post.CanReply,
+			&highlight,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if entities != nil {
+			if err := json.Unmarshal(entities, &post.Entities); err != nil {
+				return nil, err
+			}
+		}
+		if media != nil {
+			if err := json.Unmarshal(media, &post.Media); err != nil {
+				return nil, err
+			}
+		}
+		if poll != nil {
+			if err := json.Unmarshal(poll, &post.Poll); err != nil {
+				return nil, err
+			}
+		}
+		post.Highlight = highlight.String
 		post.User = &user
 		if post.User.DeletedAt != nil {
 			post.User.UserName = "deleted"
@@ -256,9 +530,42 @@ func (r *PostRepositoryImpl) fetchPosts(dto models.FilterPostDTO) ([]models.Read
 		}
 		posts = append(posts, post)
 	}
+	if dto.Cursor != nil && dto.Cursor.Backward {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
+	// an empty profile may be a protected one the viewer cannot see into
+	if len(posts) == 0 && dto.OwnerID > 0 && dto.OwnerID != dto.UserID {
+		if err := r.checkProtected(dto.OwnerID, dto.UserID); err != nil {
+			return nil, err
+		}
+	}
 	return posts, nil
 }
 
+func (r *PostRepositoryImpl) fillPollVotes(posts []models.ReadPostDTO, userID uint64) error {
+	pollIDs := []uint64{}
+	for _, post := range posts {
+		if post.Poll != nil {
+			pollIDs = append(pollIDs, post.Poll.ID)
+		}
+	}
+	if len(pollIDs) == 0 {
+		return nil
+	}
+	votes, err := r.fetchPollVotes(userID, pollIDs)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		if posts[i].Poll != nil {
+			posts[i].Poll.OwnVotes = votes[posts[i].Poll.ID]
+		}
+	}
+	return nil
+}
+
 func (r *PostRepositoryImpl) fetchLikesMap(userID uint64) (map[uint64]bool, error) {
 	query := `
 		select post_id
@@ -306,19 +613,30 @@ func (r *PostRepositoryImpl) fetchViewsMap(userID uint64) (map[uint64]bool, erro
 }
 
 func (r *PostRepositoryImpl) DeletePost(id, ownerID uint64) error {
-	query := `
-        update posts set deleted_at = now() where id = $1 and user_id = $2 and deleted_at is null;
-    `
-	result, err := r.db.Exec(query, id, ownerID)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	query := `
+		with deleted as (
+			update posts set deleted_at = now() where id = $1 and user_id = $2 and deleted_at is null
+			returning id, user_id
+		), ` + webhooksFrom(`
+			select 'post.deleted', user_id, json_build_object('id', id, 'user_id', user_id)::jsonb from deleted`) + `
+		select count(*) from deleted;
+	`
+	var deleted int
+	if err := tx.QueryRow(query, id, ownerID).Scan(&deleted); err != nil {
+		tx.Rollback()
 		return err
 	}
-	if rowsAffected == 0 {
+	if deleted == 0 {
+		tx.Rollback()
 		return ErrNotFound
 	}
-	return nil
+	if _, err = tx.Exec(`delete from pinned_posts where post_id = $1;`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
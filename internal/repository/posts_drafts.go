@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+const publishBatchSize = 100
+
+func (r *PostRepositoryImpl) GetDrafts(userID, limit, offset uint64) ([]models.ReadPostDTO, error) {
+	query := `
+		select id, text, reply_to_id, created_at, state, publish_at
+		from posts
+		where user_id = $1 and state <> 'published' and deleted_at is null
+		order by publish_at asc nulls last, created_at desc
+		offset $2 limit $3;
+	`
+	rows, err := r.db.Query(query, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.ReadPostDTO = make([]models.ReadPostDTO, 0)
+	for rows.Next() {
+		var post models.ReadPostDTO
+		err := rows.Scan(&post.ID, &post.Text, &post.ReplyToID, &post.CreatedAt, &post.State, &post.PublishAt)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
+
+func (r *PostRepositoryImpl) UpdateDraft(id, ownerID uint64, dto models.UpdateDraftDTO) (*models.ReadPostDTO, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		update posts set text = $1, state = $2, publish_at = $3
+		where id = $4 and user_id = $5 and state <> 'published' and deleted_at is null
+		returning id, text, reply_to_id, created_at, state, publish_at;
+	`
+	var post models.ReadPostDTO
+	err = tx.QueryRow(query, dto.Text, dto.State, dto.PublishAt, id, ownerID).Scan(
+		&post.ID, &post.Text, &post.ReplyToID, &post.CreatedAt, &post.State, &post.PublishAt)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	// mention offsets refer to the old text, so they are stored again
+	if _, err = tx.Exec(`delete from post_mentions where post_id = $1;`, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(dto.Mentions) > 0 {
+		entities, err := r.createMentions(tx, id, ownerID, dto.Mentions)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		post.Entities = entities
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// publishable repeats, in the publishing transaction, the checks CreatePost
+// made when the post was written, as the parent's author may have blocked the
+// user or changed who can reply since, and a poll may have closed meanwhile.
+// It returns the visibility the post is published with.
+func publishable(tx *sql.Tx, id, userID uint64, replyToID *uint64, visibility string) (string, error) {
+	if replyToID != nil {
+		var err error
+		visibility, err = replyVisibility(tx, *replyToID, userID, visibility)
+		if err != nil {
+			return "", err
+		}
+	}
+	var closed bool
+	err := tx.QueryRow(`select exists (select 1 from polls where post_id = $1 and closes_at <= now());`, id).Scan(&closed)
+	if err != nil {
+		return "", err
+	}
+	if closed {
+		return "", ErrPollClosed
+	}
+	return visibility, nil
+}
+
+func (r *PostRepositoryImpl) PublishPost(id, ownerID uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `
+		select reply_to_id, visibility from posts
+		where id = $1 and user_id = $2 and state <> 'published' and deleted_at is null
+		for update;
+	`
+	var replyToID *uint64
+	var visibility string
+	err = tx.QueryRow(query, id, ownerID).Scan(&replyToID, &visibility)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if visibility, err = publishable(tx, id, ownerID, replyToID, visibility); err != nil {
+		tx.Rollback()
+		return err
+	}
+	query = `
+		update posts set state = 'published', publish_at = null, created_at = now(), visibility = $2
+		where id = $1;
+	`
+	if _, err = tx.Exec(query, id, visibility); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	r.addReply(replyToID)
+	r.fanOut(id, replyToID)
+	r.notifyPublished(id)
+	return nil
+}
+
+// PublishDuePosts publishes scheduled posts whose publish_at has passed.
+// Rows are locked with skip locked, so concurrent publishers running in
+// several instances never pick up the same post. Posts that can no longer
+// be published, such as replies the user may no longer send or posts whose
+// poll has closed, are turned back into drafts for their authors to fix.
+func (r *PostRepositoryImpl) PublishDuePosts(limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	query := `
+		select id, user_id, reply_to_id, visibility from posts
+		where state = 'scheduled' and publish_at <= now() and deleted_at is null
+		order by publish_at
+		limit $1
+		for update skip locked;
+	`
+	rows, err := tx.Query(query, limit)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	type duePost struct {
+		id, userID uint64
+		replyToID  *uint64
+		visibility string
+	}
+	due := []duePost{}
+	for rows.Next() {
+		var post duePost
+		if err := rows.Scan(&post.id, &post.userID, &post.replyToID, &post.visibility); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, err
+		}
+		due = append(due, post)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	published := []duePost{}
+	for _, post := range due {
+		visibility, err := publishable(tx, post.id, post.userID, post.replyToID, post.visibility)
+		switch err {
+		case nil:
+			query = `
+				update posts set state = 'published', publish_at = null, created_at = now(), visibility = $2
+				where id = $1;
+			`
+			_, err = tx.Exec(query, post.id, visibility)
+			published = append(published, post)
+		case ErrNotFound, ErrReplyNotAllowed, ErrPollClosed:
+			log.Printf("Scheduled post %d turned back into a draft: %v", post.id, err)
+			_, err = tx.Exec(`update posts set state = 'draft', publish_at = null where id = $1;`, post.id)
+		}
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	for _, post := range published {
+		r.addReply(post.replyToID)
+		r.fanOut(post.id, post.replyToID)
+	}
+	for _, post := range published {
+		r.notifyPublished(post.id)
+	}
+	return len(published), nil
+}
+
+func (r *PostRepositoryImpl) startPublisher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			for {
+				published, err := r.PublishDuePosts(publishBatchSize)
+				if err != nil {
+					log.Printf("Failed to publish scheduled posts: %v", err)
+					break
+				}
+				if published < publishBatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
@@ -27,6 +27,9 @@ type LikeBuffer struct {
 }
 
 func (r *PostRepositoryImpl) LikePost(id, likedByID uint64) error {
+	if err := r.checkVisible(id, likedByID); err != nil {
+		return err
+	}
 	r.lb.lock.Lock()
 	defer r.lb.lock.Unlock()
 	newLike := Like{UserID: likedByID, PostID: id, CreatedAt: time.Now()}
@@ -63,11 +66,24 @@ func (r *PostRepositoryImpl) flushLikes() error {
 	}
 
 	insertFromTmpQuery := `
-	insert into likes (post_id, user_id, created_at)
-	select post_id, user_id, min(created_at) as created_at
-	from tmp_likes group by post_id, user_id on conflict (user_id, post_id) do nothing;
-	`
-	if _, err = tx.Exec(insertFromTmpQuery); err != nil {
+	with inserted as (
+		insert into likes (post_id, user_id, created_at)
+		select post_id, user_id, min(created_at) as created_at
+		from tmp_likes group by post_id, user_id on conflict (user_id, post_id) do nothing
+		returning post_id, user_id, created_at
+	), ` + notificationsFrom(`
+		select p.user_id, i.user_id, 'like', p.id, i.created_at from inserted i join posts p on p.id = i.post_id`) + `, ` +
+		webhooksFrom(`
+		select 'like.created', p.user_id, json_build_object('post_id', p.id, 'user_id', i.user_id, 'created_at', i.created_at)::jsonb
+		from inserted i join posts p on p.id = i.post_id`) +
+		notifiedSelect
+	rows, err := tx.Query(insertFromTmpQuery)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	found, err := scanNotified(rows)
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -88,7 +104,13 @@ func (r *PostRepositoryImpl) flushLikes() error {
 	if err != nil {
 		return err
 	}
+	publishNotified(r.hub, found)
+	postIDs := make([]uint64, len(r.lb.likeBuffer))
+	for i, like := range r.lb.likeBuffer {
+		postIDs[i] = like.PostID
+	}
 	r.lb.likeBuffer = r.lb.likeBuffer[:0]
+	r.publishCounters(postIDs)
 	return nil
 }
 
@@ -170,7 +192,12 @@ func (r *PostRepositoryImpl) flushDislikes() error {
 	if err != nil {
 		return err
 	}
+	postIDs := make([]uint64, len(r.lb.dislikeBuffer))
+	for i, dislike := range r.lb.dislikeBuffer {
+		postIDs[i] = dislike.PostID
+	}
 	r.lb.dislikeBuffer = r.lb.dislikeBuffer[:0]
+	r.publishCounters(postIDs)
 	return nil
 }
 
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+func (r *PostRepositoryImpl) attachMedia(tx *sql.Tx, postID, userID uint64, mediaIDs []uint64) ([]models.ReadMediaDTO, error) {
+	query := `
+		update media set post_id = $1, position = $2
+		where id = $3 and user_id = $4 and post_id is null
+		returning id, url, mime_type, width, height, alt_text;
+	`
+	media := make([]models.ReadMediaDTO, 0, len(mediaIDs))
+	for i, mediaID := range mediaIDs {
+		var m models.ReadMediaDTO
+		err := tx.QueryRow(query, postID, i, mediaID, userID).Scan(
+			&m.ID, &m.URL, &m.MimeType, &m.Width, &m.Height, &m.AltText)
+		if err == sql.ErrNoRows {
+			return nil, ErrMediaNotAvailable
+		}
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// createMentions links the mentioned users to the post. Users with a block
+// between them and the author are skipped, so they are neither linked nor
+// given access to mention-only posts.
+func (r *PostRepositoryImpl) createMentions(
+	tx *sql.Tx,
+	postID, authorID uint64,
+	mentions []models.CreateMentionDTO,
+) ([]models.ReadPostEntityDTO, error) {
+	query := `
+		insert into post_mentions (post_id, user_id, start_offset, length)
+		select $1, u.id, m.start_offset, m.length
+		from (values %s) as m (user_name, start_offset, length)
+		join users u on u.user_name = m.user_name and u.deleted_at is null
+		where not ` + blockedBetween("u.id", "$2") + `
+		returning user_id, start_offset, length;
+	`
+	params := []interface{}{postID, authorID}
+	values := []string{}
+	userNames := make(map[int]string)
+	for i, mention := range mentions {
+		values = append(values, fmt.Sprintf("($%d::varchar, $%d::int, $%d::int)", i*3+3, i*3+4, i*3+5))
+		params = append(params, mention.UserName, mention.Offset, mention.Length)
+		userNames[mention.Offset] = mention.UserName
+	}
+	rows, err := tx.Query(fmt.Sprintf(query, strings.Join(values, ",")), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entities []models.ReadPostEntityDTO
+	for rows.Next() {
+		entity := models.ReadPostEntityDTO{Type: models.EntityTypeMention}
+		if err := rows.Scan(&entity.UserID, &entity.Offset, &entity.Length); err != nil {
+			return nil, err
+		}
+		entity.UserName = userNames[entity.Offset]
+		entities = append(entities, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Offset < entities[j].Offset
+	})
+	return entities, nil
+}
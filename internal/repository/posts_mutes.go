@@ -0,0 +1,54 @@
+package repository
+
+import "fmt"
+
+// notMuted returns a condition on the post aliased as p that drops posts by
+// accounts the user bound to the viewer placeholder has muted, and posts
+// containing one of their active muted words. Keywords match in any language
+// the post may be indexed in; hashtags match the whole tag in the text. The
+// viewer's own posts are never muted.
+func notMuted(viewer string) string {
+	return fmt.Sprintf(`(p.user_id = %[1]s or (
+		not exists (select 1 from muted_users mu where mu.user_id = %[1]s and mu.muted_id = p.user_id)
+		and not exists (
+			select 1 from muted_words mw
+			where mw.user_id = %[1]s and (mw.expires_at is null or mw.expires_at > now())
+			and case when mw.word like '#%%'
+				then lower(p.text) ~ ('(^|[^[:alnum:]_])' || mw.word || '([^[:alnum:]_]|$)')
+				else p.search_vector @@ %[2]s
+			end
+		)))`, viewer, phraseQuery("mw.word"))
+}
+
+// MuteConversation stops notifications about the thread the post belongs to.
+func (r *PostRepositoryImpl) MuteConversation(id, userID uint64) error {
+	if err := r.checkVisible(id, userID); err != nil {
+		return err
+	}
+	query := `
+		insert into muted_conversations (user_id, post_id) values ($1, $2)
+		on conflict (user_id, post_id) do nothing;
+	`
+	_, err := r.db.Exec(query, userID, id)
+	return err
+}
+
+func (r *PostRepositoryImpl) UnmuteConversation(id, userID uint64) error {
+	query := `delete from muted_conversations where user_id = $1 and post_id = $2;`
+	_, err := r.db.Exec(query, userID, id)
+	return err
+}
+
+// threadMuted returns a condition that holds when the user bound to user muted
+// the conversation of the post bound to post: the post itself or any post up
+// its reply chain.
+func threadMuted(user, post string) string {
+	return fmt.Sprintf(`exists (
+		with recursive thread as (
+			select tp.id, tp.reply_to_id from posts tp where tp.id = %[2]s
+			union all
+			select tp.id, tp.reply_to_id from posts tp join thread on tp.id = thread.reply_to_id
+		)
+		select 1 from thread join muted_conversations mc on mc.post_id = thread.id where mc.user_id = %[1]s
+	)`, user, post)
+}
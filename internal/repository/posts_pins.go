@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+const maxPinnedPosts = 3
+
+func (r *PostRepositoryImpl) PinPost(id, ownerID uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	// lock the owner so concurrent pins cannot exceed the limit
+	var userID uint64
+	err = tx.QueryRow(`select id from users where id = $1 for update;`, ownerID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var count int
+	err = tx.QueryRow(`select count(*) from pinned_posts where user_id = $1 and post_id <> $2;`, ownerID, id).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if count >= maxPinnedPosts {
+		tx.Rollback()
+		return ErrTooManyPinned
+	}
+	query := `
+		insert into pinned_posts (user_id, post_id)
+		select user_id, id from posts
+		where id = $1 and user_id = $2 and reply_to_id is null and state = 'published' and deleted_at is null
+		on conflict (post_id) do update set pinned_at = now();
+	`
+	result, err := tx.Exec(query, id, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
+
+func (r *PostRepositoryImpl) UnpinPost(id, ownerID uint64) error {
+	query := `
+		delete from pinned_posts where post_id = $1 and user_id = $2;
+	`
+	result, err := r.db.Exec(query, id, ownerID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+type VoteBuffer struct {
+	options    map[uint64]int
+	voters     map[uint64]int
+	lock       sync.Mutex
+	maxRecords int
+	timer      time.Duration
+}
+
+func (r *PostRepositoryImpl) createPoll(tx *sql.Tx, postID uint64, dto *models.CreatePollDTO) (*models.ReadPollDTO, error) {
+	pollQuery := `
+		insert into polls (post_id, multiple, closes_at) values ($1, $2, $3)
+		returning id, multiple, closes_at, closes_at <= now();
+	`
+	poll := &models.ReadPollDTO{VotersCount: new(uint)}
+	err := tx.QueryRow(pollQuery, postID, dto.Multiple, dto.ClosesAt).Scan(
+		&poll.ID, &poll.Multiple, &poll.ClosesAt, &poll.Closed)
+	if err != nil {
+		return nil, err
+	}
+	optionQuery := `
+		insert into poll_options (poll_id, position, text) values ($1, $2, $3)
+		returning id;
+	`
+	for i, text := range dto.Options {
+		option := models.ReadPollOptionDTO{Text: text, VotesCount: new(uint)}
+		if err := tx.QueryRow(optionQuery, poll.ID, i, text).Scan(&option.ID); err != nil {
+			return nil, err
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	return poll, nil
+}
+
+func (r *PostRepositoryImpl) VotePoll(postID, userID uint64, optionIDs []uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	// only those who can see the post may vote, like for likes and views
+	pollQuery := `
+		select pl.id, pl.multiple, pl.closes_at <= now()
+		from polls pl
+		join posts p on p.id = pl.post_id and p.deleted_at is null and p.state = 'published'
+		where pl.post_id = $1 and ` + visibleTo("$2") + `;
+	`
+	var pollID uint64
+	var multiple, closed bool
+	err = tx.QueryRow(pollQuery, postID, userID).Scan(&pollID, &multiple, &closed)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if closed {
+		tx.Rollback()
+		return ErrPollClosed
+	}
+	if !multiple && len(optionIDs) > 1 {
+		tx.Rollback()
+		return ErrInvalidVote
+	}
+
+	voterQuery := `
+		insert into poll_voters (poll_id, user_id) values ($1, $2) on conflict do nothing;
+	`
+	result, err := tx.Exec(voterQuery, pollID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return ErrAlreadyVoted
+	}
+
+	votesQuery := `
+		insert into poll_votes (poll_id, option_id, user_id)
+		select $1, id, $2 from poll_options where poll_id = $1 and id in (%s);
+	`
+	params := []interface{}{pollID, userID}
+	placeholders := []string{}
+	for _, optionID := range optionIDs {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)+1))
+		params = append(params, optionID)
+	}
+	result, err = tx.Exec(fmt.Sprintf(votesQuery, strings.Join(placeholders, ", ")), params...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rowsAffected != int64(len(optionIDs)) {
+		tx.Rollback()
+		return ErrInvalidVote
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	r.pb.lock.Lock()
+	defer r.pb.lock.Unlock()
+	r.pb.voters[pollID]++
+	for _, optionID := range optionIDs {
+		r.pb.options[optionID]++
+	}
+	if len(r.pb.options) >= r.pb.maxRecords {
+		r.flushVotes()
+	}
+	return nil
+}
+
+func (r *PostRepositoryImpl) flushVotes() {
+	for optionID, count := range r.pb.options {
+		_, err := r.db.Exec(`
+			update poll_options
+			set votes_count = votes_count + $1
+			where id = $2
+		`, count, optionID)
+		if err != nil {
+			log.Printf("Failed to update votes_count for poll option %d: %v", optionID, err)
+		}
+	}
+	for pollID, count := range r.pb.voters {
+		_, err := r.db.Exec(`
+			update polls
+			set voters_count = voters_count + $1
+			where id = $2
+		`, count, pollID)
+		if err != nil {
+			log.Printf("Failed to update voters_count for poll %d: %v", pollID, err)
+		}
+	}
+	r.pb.options = make(map[uint64]int)
+	r.pb.voters = make(map[uint64]int)
+}
+
+func (r *PostRepositoryImpl) startVotesTimer() {
+	ticker := time.NewTicker(r.pb.timer)
+	go func() {
+		for range ticker.C {
+			r.pb.lock.Lock()
+			if len(r.pb.voters) > 0 {
+				r.flushVotes()
+			}
+			r.pb.lock.Unlock()
+		}
+	}()
+}
+
+// fetchPollVotes returns the options the user voted for, keyed by poll ID.
+func (r *PostRepositoryImpl) fetchPollVotes(userID uint64, pollIDs []uint64) (map[uint64][]uint64, error) {
+	query := `
+		select poll_id, option_id
+		from poll_votes
+		where user_id = $1 and poll_id in (%s)
+		order by poll_id, option_id
+	`
+	params := []interface{}{userID}
+	placeholders := []string{}
+	for _, pollID := range pollIDs {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)+1))
+		params = append(params, pollID)
+	}
+	rows, err := r.db.Query(fmt.Sprintf(query, strings.Join(placeholders, ", ")), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := make(map[uint64][]uint64)
+	for rows.Next() {
+		var pollID, optionID uint64
+		if err := rows.Scan(&pollID, &optionID); err != nil {
+			return nil, err
+		}
+		votes[pollID] = append(votes[pollID], optionID)
+	}
+	return votes, rows.Err()
+}
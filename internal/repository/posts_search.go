@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/search"
+)
+
+// languageConfigs maps the languages a post can be written in to the text
+// search configurations used to index it. Posts without a language are
+// indexed with the simple configuration, which does no stemming.
+var languageConfigs = map[string]string{
+	"en": "english",
+	"ru": "russian",
+	"de": "german",
+	"fr": "french",
+	"es": "spanish",
+	"it": "italian",
+	"pt": "portuguese",
+	"nl": "dutch",
+}
+
+// searchConfigs lists every configuration posts may be indexed with, in a
+// fixed order so the generated SQL is stable.
+var searchConfigs = []string{"simple", "english", "russian", "german", "french", "spanish", "italian", "portuguese", "dutch"}
+
+func searchConfig(language string) string {
+	if config, ok := languageConfigs[language]; ok {
+		return config
+	}
+	return "simple"
+}
+
+// searchQuery parses the user input bound to param once per configuration
+// and ORs the results, so a post matches in whatever language it was indexed
+// while the expression stays constant and can use the GIN index.
+func searchQuery(param string) string {
+	return tsqueryAcrossConfigs("websearch_to_tsquery", param)
+}
+
+// phraseQuery matches the words bound to param next to each other.
+func phraseQuery(param string) string {
+	return tsqueryAcrossConfigs("phraseto_tsquery", param)
+}
+
+func tsqueryAcrossConfigs(parser, param string) string {
+	queries := make([]string, 0, len(searchConfigs))
+	for _, config := range searchConfigs {
+		queries = append(queries, fmt.Sprintf("%s('%s', %s)", parser, config, param))
+	}
+	return "(" + strings.Join(queries, " || ") + ")"
+}
+
+// searchHeadline wraps the matched words of the post aliased as p in <mark>.
+func searchHeadline(param string) string {
+	return fmt.Sprintf("ts_headline(p.search_config, p.text, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')", searchQuery(param))
+}
+
+// searchText rebuilds the words and phrases a post has to contain in web
+// search syntax. Excluded terms are compiled separately by compileSearch so
+// that a match in any language excludes the post.
+func searchText(q *search.Query) string {
+	terms := []string{}
+	for _, node := range q.Nodes {
+		term, ok := node.(search.Term)
+		if !ok || term.Negated {
+			continue
+		}
+		if term.Phrase {
+			terms = append(terms, `"`+term.Text+`"`)
+		} else {
+			terms = append(terms, term.Text)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// compileSearch turns the operators and excluded terms of a query into
+// conditions on the post aliased as p and its author u, appending their
+// arguments to params.
+func compileSearch(q *search.Query, params []interface{}) (string, []interface{}) {
+	var conditions strings.Builder
+	next := func(value interface{}) string {
+		params = append(params, value)
+		return fmt.Sprintf("$%d", len(params))
+	}
+	not := func(negated bool) string {
+		if negated {
+			return "not "
+		}
+		return ""
+	}
+	for _, node := range q.Nodes {
+		switch n := node.(type) {
+		case search.Term:
+			if n.Negated {
+				conditions.WriteString(" and not p.search_vector @@ " + phraseQuery(next(n.Text)))
+			}
+		case search.From:
+			operator := "="
+			if n.Negated {
+				operator = "<>"
+			}
+			conditions.WriteString(fmt.Sprintf(" and u.user_name %s %s", operator, next(n.UserName)))
+		case search.To:
+			conditions.WriteString(fmt.Sprintf(
+				" and %sexists (select 1 from posts tp join users tu on tu.id = tp.user_id where tp.id = p.reply_to_id and tu.user_name = %s)",
+				not(n.Negated), next(n.UserName),
+			))
+		case search.Since:
+			conditions.WriteString(" and p.created_at >= " + next(n.Date))
+		case search.Until:
+			conditions.WriteString(" and p.created_at < " + next(n.Date))
+		case search.Has:
+			switch n.Feature {
+			case search.FeatureMedia:
+				conditions.WriteString(fmt.Sprintf(" and %sexists (select 1 from media hm where hm.post_id = p.id)", not(n.Negated)))
+			case search.FeaturePoll:
+				conditions.WriteString(fmt.Sprintf(" and %sexists (select 1 from polls hp where hp.post_id = p.id)", not(n.Negated)))
+			}
+		case search.Is:
+			if n.Kind == search.KindReply {
+				if n.Negated {
+					conditions.WriteString(" and p.reply_to_id is null")
+				} else {
+					conditions.WriteString(" and p.reply_to_id is not null")
+				}
+			}
+		case search.MinLikes:
+			conditions.WriteString(" and p.likes_count >= " + next(n.Count))
+		}
+	}
+	return conditions.String(), params
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// publishCounters announces the current counters of the posts to their
+// streams. The counters are only read when a hub is wired in.
+func (r *PostRepositoryImpl) publishCounters(postIDs []uint64) {
+	if r.hub == nil || len(postIDs) == 0 {
+		return
+	}
+	params := []interface{}{}
+	placeholders := []string{}
+	seen := make(map[uint64]bool)
+	for _, postID := range postIDs {
+		if seen[postID] {
+			continue
+		}
+		seen[postID] = true
+		params = append(params, postID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+	}
+	query := fmt.Sprintf(
+		"select id, likes_count, views_count, replies_count from posts where id in (%s);",
+		strings.Join(placeholders, ", "),
+	)
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		log.Printf("Failed to read post counters: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var counters models.StreamCountersDTO
+		if err := rows.Scan(&counters.PostID, &counters.LikesCount, &counters.ViewsCount, &counters.RepliesCount); err != nil {
+			log.Printf("Failed to read post counters: %v", err)
+			return
+		}
+		r.hub.Publish(events.PostTopic(counters.PostID), events.TypeCounters, counters)
+	}
+}
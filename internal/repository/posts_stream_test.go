@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostRepositoryImpl_publishCounters(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	r.publishCounters([]uint64{1})
+
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	subscription := hub.Subscribe(events.PostTopic(1))
+	r.hub = hub
+	mock.ExpectQuery(regexp.QuoteMeta(`select id, likes_count, views_count, replies_count from posts where id in ($1, $2);`)).
+		WithArgs(uint64(1), uint64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "likes_count", "views_count", "replies_count"}).
+			AddRow(1, 3, 10, 2).
+			AddRow(2, 0, 1, 0))
+	r.publishCounters([]uint64{1, 2, 1})
+
+	event := <-subscription.Events()
+	assert.Equal(t, events.TypeCounters, event.Type, "Event type mismatch")
+	assert.Equal(t, events.PostTopic(1), event.Topic, "Event topic mismatch")
+	assert.JSONEq(t, `{"post_id":1,"likes_count":3,"views_count":10,"replies_count":2}`, string(event.Data), "Event data mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
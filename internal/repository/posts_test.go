@@ -2,22 +2,45 @@ package repository
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
 	"github.com/stretchr/testify/assert"
 )
 
+var deletePostQuery = regexp.QuoteMeta(`
+	with deleted as (
+		update posts set deleted_at = now() where id = $1 and user_id = $2 and deleted_at is null
+		returning id, user_id
+	), hooked (event, user_id, payload) as (
+		select 'post.deleted', user_id, json_build_object('id', id, 'user_id', user_id)::jsonb from deleted
+	)`) + ".*" + regexp.QuoteMeta(`select count(*) from deleted;`)
+
+var notifiedColumns = []string{"id", "user_id", "type"}
+
+var notifyPublishedQuery = regexp.QuoteMeta(`
+	select parent.user_id, p.user_id, 'reply', p.id, p.created_at
+	from posts p join posts parent on parent.id = p.reply_to_id
+	where p.id = $1
+	union all
+	select pm.user_id, p.user_id, 'mention', p.id, p.created_at`)
+
 func TestPostRepositoryImpl_CreatePost(t *testing.T) {
 	testCases := []struct {
 		name      string
 		createDTO models.CreatePostDTO
 		readDTO   models.ReadPostDTO
 		hasError  bool
+		mediaErr  error
 	}{
 		{
 			name: "Success create",
@@ -27,13 +50,111 @@ func TestPostRepositoryImpl_CreatePost(t *testing.T) {
 				ReplyToID: nil,
 			},
 			readDTO: models.ReadPostDTO{
-				ID:        1,
-				Text:      "Lorem ipsum dolor sit amet, consectetur adipiscing",
-				ReplyToID: nil,
-				CreatedAt: time.Now(),
+				ID:          1,
+				State:       models.PostStatePublished,
+				Visibility:  models.PostVisibilityPublic,
+				ReplyPolicy: models.ReplyPolicyEveryone,
+				CanReply:    true,
+				Text:        "Lorem ipsum dolor sit amet, consectetur adipiscing",
+				ReplyToID:   nil,
+				CreatedAt:   time.Now(),
+			},
+			hasError: false,
+		},
+		{
+			name: "Success create with mentions",
+			createDTO: models.CreatePostDTO{
+				Text:   "Hello @johndoe and @ghost_user",
+				UserID: 1,
+				Mentions: []models.CreateMentionDTO{
+					{UserName: "johndoe", Offset: 6, Length: 8},
+					{UserName: "ghost_user", Offset: 19, Length: 11},
+				},
+			},
+			readDTO: models.ReadPostDTO{
+				ID:          1,
+				State:       models.PostStatePublished,
+				Visibility:  models.PostVisibilityPublic,
+				ReplyPolicy: models.ReplyPolicyEveryone,
+				CanReply:    true,
+				Text:        "Hello @johndoe and @ghost_user",
+				CreatedAt:   time.Now(),
+				Entities: []models.ReadPostEntityDTO{
+					{Type: models.EntityTypeMention, Offset: 6, Length: 8, UserID: 2, UserName: "johndoe"},
+				},
 			},
 			hasError: false,
 		},
+		{
+			name: "Success create with media",
+			createDTO: models.CreatePostDTO{
+				Text:     "Look at this",
+				UserID:   1,
+				MediaIDs: []uint64{7},
+			},
+			readDTO: models.ReadPostDTO{
+				ID:          1,
+				State:       models.PostStatePublished,
+				Visibility:  models.PostVisibilityPublic,
+				ReplyPolicy: models.ReplyPolicyEveryone,
+				CanReply:    true,
+				Text:        "Look at this",
+				CreatedAt:   time.Now(),
+				Media: []models.ReadMediaDTO{
+					{ID: 7, URL: "/v1.0/media/files/media/7.png", MimeType: "image/png", Width: 640, Height: 480},
+				},
+			},
+			hasError: false,
+		},
+		{
+			name: "Success create with poll",
+			createDTO: models.CreatePostDTO{
+				Text:   "Tabs or spaces?",
+				UserID: 1,
+				Poll: &models.CreatePollDTO{
+					Options:  []string{"Tabs", "Spaces"},
+					ClosesAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			readDTO: models.ReadPostDTO{
+				ID:          1,
+				State:       models.PostStatePublished,
+				Visibility:  models.PostVisibilityPublic,
+				ReplyPolicy: models.ReplyPolicyEveryone,
+				CanReply:    true,
+				Text:        "Tabs or spaces?",
+				CreatedAt:   time.Now(),
+				Poll: &models.ReadPollDTO{
+					ID:          3,
+					ClosesAt:    time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+					VotersCount: new(uint),
+					Options: []models.ReadPollOptionDTO{
+						{ID: 5, Text: "Tabs", VotesCount: new(uint)},
+						{ID: 6, Text: "Spaces", VotesCount: new(uint)},
+					},
+				},
+			},
+			hasError: false,
+		},
+		{
+			name: "Media belongs to other user",
+			createDTO: models.CreatePostDTO{
+				Text:     "Look at this",
+				UserID:   1,
+				MediaIDs: []uint64{8},
+			},
+			readDTO: models.ReadPostDTO{
+				ID:          1,
+				State:       models.PostStatePublished,
+				Visibility:  models.PostVisibilityPublic,
+				ReplyPolicy: models.ReplyPolicyEveryone,
+				CanReply:    true,
+				Text:        "Look at this",
+				CreatedAt:   time.Now(),
+			},
+			hasError: true,
+			mediaErr: ErrMediaNotAvailable,
+		},
 		{
 			name: "Error on insert SQL",
 			createDTO: models.CreatePostDTO{
@@ -50,42 +171,128 @@ func TestPostRepositoryImpl_CreatePost(t *testing.T) {
 		t.Fatalf("Error creating db mock: %v", err)
 	}
 	defer db.Close()
-	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	tb := &FanoutBuffer{buffer: make([]uint64, 0, 10), maxRecords: 10}
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, tb: tb}
+	attachMediaQuery := regexp.QuoteMeta(`
+		update media set post_id = $1, position = $2
+		where id = $3 and user_id = $4 and post_id is null
+		returning id, url, mime_type, width, height, alt_text;
+	`)
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if !tc.hasError {
+			mock.ExpectBegin()
+			if !tc.hasError || tc.mediaErr != nil {
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					insert into posts (text, user_id, reply_to_id) values ($1, $2, $3)
-					returning id, text, created_at, reply_to_id;
+					insert into posts (text, user_id, reply_to_id, state, publish_at, visibility, reply_policy, search_config)
+					values ($1, $2, $3, $4, $5, $6, $7, $8)
+					returning id, text, created_at, reply_to_id, state, publish_at, visibility, reply_policy;
 					`)).
 					WithArgs(
 						tc.createDTO.Text,
 						tc.createDTO.UserID,
-						tc.createDTO.ReplyToID).
+						tc.createDTO.ReplyToID,
+						models.PostStatePublished,
+						tc.createDTO.PublishAt,
+						models.PostVisibilityPublic,
+						models.ReplyPolicyEveryone,
+						"simple").
 					WillReturnRows(
 						sqlmock.NewRows(
-							[]string{"id", "text", "created_at", "reply_to_id"},
+							[]string{"id", "text", "created_at", "reply_to_id", "state", "publish_at", "visibility", "reply_policy"},
 						).AddRow(
 							tc.readDTO.ID,
 							tc.readDTO.Text,
 							tc.readDTO.CreatedAt,
 							tc.readDTO.ReplyToID,
+							tc.readDTO.State,
+							tc.readDTO.PublishAt,
+							tc.readDTO.Visibility,
+							tc.readDTO.ReplyPolicy,
 						),
 					)
+				if len(tc.createDTO.Mentions) > 0 {
+					mentionRows := sqlmock.NewRows([]string{"user_id", "start_offset", "length"})
+					for _, entity := range tc.readDTO.Entities {
+						mentionRows.AddRow(entity.UserID, entity.Offset, entity.Length)
+					}
+					mock.ExpectQuery(regexp.QuoteMeta(`
+						insert into post_mentions (post_id, user_id, start_offset, length)
+						select $1, u.id, m.start_offset, m.length
+						from (values ($3::varchar, $4::int, $5::int),($6::varchar, $7::int, $8::int)) as m (user_name, start_offset, length)
+						join users u on u.user_name = m.user_name and u.deleted_at is null
+						where not exists (select 1 from blocks bk where (bk.blocker_id = u.id and bk.blocked_id = $2)
+							or (bk.blocker_id = $2 and bk.blocked_id = u.id))
+						returning user_id, start_offset, length;
+						`)).
+						WithArgs(
+							tc.readDTO.ID,
+							tc.createDTO.UserID,
+							"johndoe", 6, 8,
+							"ghost_user", 19, 11,
+						).
+						WillReturnRows(mentionRows)
+				}
+				for i, mediaID := range tc.createDTO.MediaIDs {
+					expect := mock.ExpectQuery(attachMediaQuery).WithArgs(tc.readDTO.ID, i, mediaID, tc.createDTO.UserID)
+					if tc.mediaErr != nil {
+						expect.WillReturnError(sql.ErrNoRows)
+						continue
+					}
+					m := tc.readDTO.Media[i]
+					expect.WillReturnRows(sqlmock.NewRows(
+						[]string{"id", "url", "mime_type", "width", "height", "alt_text"},
+					).AddRow(m.ID, m.URL, m.MimeType, m.Width, m.Height, m.AltText))
+				}
+				if tc.createDTO.Poll != nil {
+					poll := tc.readDTO.Poll
+					mock.ExpectQuery(regexp.QuoteMeta(`
+						insert into polls (post_id, multiple, closes_at) values ($1, $2, $3)
+						returning id, multiple, closes_at, closes_at <= now();
+						`)).
+						WithArgs(tc.readDTO.ID, tc.createDTO.Poll.Multiple, tc.createDTO.Poll.ClosesAt).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "multiple", "closes_at", "closed"}).
+							AddRow(poll.ID, poll.Multiple, poll.ClosesAt, poll.Closed))
+					for i, option := range poll.Options {
+						mock.ExpectQuery(regexp.QuoteMeta(`
+							insert into poll_options (poll_id, position, text) values ($1, $2, $3)
+							returning id;
+							`)).
+							WithArgs(poll.ID, i, option.Text).
+							WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(option.ID))
+					}
+				}
+				if tc.mediaErr != nil {
+					mock.ExpectRollback()
+				} else {
+					mock.ExpectCommit()
+					mock.ExpectQuery(notifyPublishedQuery).
+						WithArgs(tc.readDTO.ID).
+						WillReturnRows(sqlmock.NewRows(notifiedColumns))
+				}
 			} else {
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					insert into posts (text, user_id, reply_to_id) values ($1, $2, $3)
-					returning id, text, created_at, reply_to_id;
+					insert into posts (text, user_id, reply_to_id, state, publish_at, visibility, reply_policy, search_config)
+					values ($1, $2, $3, $4, $5, $6, $7, $8)
+					returning id, text, created_at, reply_to_id, state, publish_at, visibility, reply_policy;
 					`)).
 					WithArgs(
 						tc.createDTO.Text,
 						tc.createDTO.UserID,
-						tc.createDTO.ReplyToID).
+						tc.createDTO.ReplyToID,
+						models.PostStatePublished,
+						tc.createDTO.PublishAt,
+						models.PostVisibilityPublic,
+						models.ReplyPolicyEveryone,
+						"simple").
 					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			}
 			post, err := r.CreatePost(tc.createDTO)
 			if tc.hasError {
 				assert.NotNil(t, err, "Error is nil")
+				if tc.mediaErr != nil {
+					assert.Equal(t, tc.mediaErr, err, "Error mismatch")
+				}
 			} else {
 				assert.Nil(t, err, "Error is not nil")
 				assert.Equal(t, tc.readDTO, *post, "Post mismatch")
@@ -98,6 +305,7 @@ func TestPostRepositoryImpl_CreatePost(t *testing.T) {
 }
 
 func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
+	var votersCount, yesCount, noCount uint = 3, 2, 1
 	testCases := []struct {
 		name      string
 		filterDTO models.FilterPostDTO
@@ -112,6 +320,7 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 				Offset:    0,
 				ReplyToID: 1,
 				Search:    "test",
+				Query:     &search.Query{Nodes: []search.Node{search.Term{Text: "test"}}},
 			},
 			readDTOs: []models.ReadPostDTO{
 				{
@@ -123,12 +332,26 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 						FirstName: "first_name",
 						LastName:  "last_name",
 					},
-					ReplyToID:  nil,
-					CreatedAt:  time.Now(),
-					LikesCount: 10,
-					ViewsCount: 100,
-					UserLiked:  true,
-					UserViewed: true,
+					ReplyToID:   nil,
+					CreatedAt:   time.Now(),
+					LikesCount:  10,
+					ViewsCount:  100,
+					UserLiked:   true,
+					UserViewed:  true,
+					Visibility:  models.PostVisibilityPublic,
+					ReplyPolicy: models.ReplyPolicyEveryone,
+					CanReply:    true,
+					Highlight:   "Lorem ipsum <mark>test</mark>",
+					Poll: &models.ReadPollDTO{
+						ID:          5,
+						ClosesAt:    time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+						VotersCount: &votersCount,
+						Options: []models.ReadPollOptionDTO{
+							{ID: 11, Text: "Yes", VotesCount: &yesCount},
+							{ID: 12, Text: "No", VotesCount: &noCount},
+						},
+						OwnVotes: []uint64{11},
+					},
 				},
 				{
 					ID:   2,
@@ -139,12 +362,32 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 						FirstName: "first_name",
 						LastName:  "last_name",
 					},
-					ReplyToID:  nil,
-					CreatedAt:  time.Now(),
-					LikesCount: 10,
-					ViewsCount: 100,
-					UserLiked:  true,
-					UserViewed: true,
+					ReplyToID:   nil,
+					CreatedAt:   time.Now(),
+					LikesCount:  10,
+					ViewsCount:  100,
+					UserLiked:   true,
+					UserViewed:  true,
+					Visibility:  models.PostVisibilityPublic,
+					ReplyPolicy: models.ReplyPolicyEveryone,
+					CanReply:    true,
+					Entities: []models.ReadPostEntityDTO{
+						{Type: models.EntityTypeMention, Offset: 0, Length: 8, UserID: 2, UserName: "johndoe"},
+					},
+					Media: []models.ReadMediaDTO{
+						{
+							ID:       1,
+							URL:      "/v1.0/media/files/media/1.png",
+							MimeType: "image/png",
+							Width:    640,
+							Height:   480,
+							AltText:  "A gopher",
+							Blurhash: "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+							Variants: []models.ReadMediaVariantDTO{
+								{Name: "thumb", URL: "/v1.0/media/files/media/1_thumb.png", MimeType: "image/png", Width: 150, Height: 150},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -160,6 +403,11 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 
 	r := &PostRepositoryImpl{cfg: &cfg, db: db}
 
+	tsquery := `(websearch_to_tsquery('simple', $2) || websearch_to_tsquery('english', $2) || ` +
+		`websearch_to_tsquery('russian', $2) || websearch_to_tsquery('german', $2) || ` +
+		`websearch_to_tsquery('french', $2) || websearch_to_tsquery('spanish', $2) || ` +
+		`websearch_to_tsquery('italian', $2) || websearch_to_tsquery('portuguese', $2) || ` +
+		`websearch_to_tsquery('dutch', $2))`
 	query := regexp.QuoteMeta(`
 	select 
 		p.id as post_id,
@@ -173,12 +421,74 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 		u.deleted_at,
 		p.likes_count,
 		p.views_count,
-		p.replies_count
+		p.replies_count,
+		(
+			select json_agg(json_build_object(
+				'type', 'mention',
+				'offset', pm.start_offset,
+				'length', pm.length,
+				'user_id', mu.id,
+				'user_name', mu.user_name
+			) order by pm.start_offset)
+			from post_mentions pm
+			join users mu on pm.user_id = mu.id and mu.deleted_at is null
+			where pm.post_id = p.id
+		) as entities,
+		(
+			select json_agg(json_build_object(
+				'id', m.id,
+				'url', m.url,
+				'mime_type', m.mime_type,
+				'width', m.width,
+				'height', m.height,
+				'alt_text', m.alt_text,
+				'blurhash', m.blurhash,
+				'variants', m.variants
+			) order by m.position)
+			from media m
+			where m.post_id = p.id
+		) as media,
+		(
+			select json_build_object(
+				'id', pl.id,
+				'multiple', pl.multiple,
+				'closes_at', pl.closes_at,
+				'closed', pl.closes_at <= now(),
+				'voters_count', pl.voters_count,
+				'options', (
+					select json_agg(json_build_object(
+						'id', po.id,
+						'text', po.text,
+						'votes_count', po.votes_count
+					) order by po.position)
+					from poll_options po
+					where po.poll_id = pl.id
+				)
+			)
+			from polls pl
+			where pl.post_id = p.id
+		) as poll,
+		false as pinned,
+		p.visibility,
+		p.reply_policy,
+		(p.reply_policy = 'everyone' or p.user_id = $1
+			or (p.reply_policy = 'following' and exists (select 1 from follows rf where rf.follower_id = p.user_id and rf.following_id = $1))
+			or (p.reply_policy = 'mentioned' and exists (select 1 from post_mentions rm where rm.post_id = p.id and rm.user_id = $1))) as can_reply,
+		ts_headline(p.search_config, p.text, ` + tsquery + `, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') as highlight
 	from posts p
 	join users u on p.user_id = u.id
-	where p.deleted_at is null and p.text ilike $1 and p.reply_to_id = $2
-	order by p.created_at asc
-	offset $3 limit $4
+	where p.deleted_at is null and p.state = 'published'
+	and ((p.visibility = 'public' or p.user_id = $1
+		or (p.visibility = 'followers' and exists (select 1 from follows f where f.follower_id = $1 and f.following_id = p.user_id))
+		or (p.visibility = 'mentioned' and exists (select 1 from post_mentions vm where vm.post_id = p.id and vm.user_id = $1)))
+		and (p.user_id = $1 or not exists (select 1 from users pa where pa.id = p.user_id and pa.protected)
+			or exists (select 1 from follows pf where pf.follower_id = $1 and pf.following_id = p.user_id))
+		and not exists (select 1 from blocks bk where (bk.blocker_id = $1 and bk.blocked_id = p.user_id)
+			or (bk.blocker_id = p.user_id and bk.blocked_id = $1)))
+	and p.search_vector @@ ` + tsquery + `
+	and p.reply_to_id = $3
+	order by ts_rank(p.search_vector, ` + tsquery + `) desc, p.created_at desc
+	offset $4 limit $5
 	`)
 
 	for _, tc := range testCases {
@@ -196,8 +506,28 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 				"likes_count",
 				"views_count",
 				"replies_count",
+				"entities",
+				"media",
+				"poll",
+				"pinned",
+				"visibility",
+				"reply_policy",
+				"can_reply",
+				"highlight",
 			})
 			for _, post := range tc.readDTOs {
+				var entities, media, poll []byte
+				if post.Entities != nil {
+					entities, _ = json.Marshal(post.Entities)
+				}
+				if post.Media != nil {
+					media, _ = json.Marshal(post.Media)
+				}
+				if post.Poll != nil {
+					p := *post.Poll
+					p.OwnVotes = nil
+					poll, _ = json.Marshal(p)
+				}
 				rows.AddRow(
 					post.ID,
 					post.Text,
@@ -211,11 +541,19 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 					post.LikesCount,
 					post.ViewsCount,
 					post.RepliesCount,
+					entities,
+					media,
+					poll,
+					post.Pinned,
+					post.Visibility,
+					post.ReplyPolicy,
+					post.CanReply,
+					post.Highlight,
 				)
 			}
 
 			mock.ExpectQuery(query).
-				WithArgs("%"+tc.filterDTO.Search+"%", tc.filterDTO.ReplyToID, tc.filterDTO.Offset, tc.filterDTO.Limit).
+				WithArgs(tc.filterDTO.UserID, tc.filterDTO.Search, tc.filterDTO.ReplyToID, tc.filterDTO.Offset, tc.filterDTO.Limit).
 				WillReturnRows(rows)
 
 			likesRows := sqlmock.NewRows([]string{
@@ -242,6 +580,14 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 				where user_id = $1`,
 			)).WithArgs(tc.filterDTO.UserID).WillReturnRows(viewsRows)
 
+			mock.ExpectQuery(regexp.QuoteMeta(`
+				select poll_id, option_id
+				from poll_votes
+				where user_id = $1 and poll_id in ($2)
+				order by poll_id, option_id`,
+			)).WithArgs(tc.filterDTO.UserID, uint64(5)).WillReturnRows(
+				sqlmock.NewRows([]string{"poll_id", "option_id"}).AddRow(5, 11))
+
 			posts, err := r.GetAllPosts(tc.filterDTO)
 			assert.Nil(t, err, "Error is not nil")
 			assert.Equal(t, tc.readDTOs, posts, "Posts mismatch")
@@ -253,6 +599,82 @@ func TestPostRepositoryImpl_GetAllPosts(t *testing.T) {
 	}
 }
 
+func TestPostRepositoryImpl_fetchPosts_Pinned(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}).
+		AddRow(2, "pinned", nil, now.Add(-time.Hour), 1, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, true, "public", "everyone", true, nil).
+		AddRow(3, "latest", nil, now, 1, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, nil)
+	mock.ExpectQuery(`pp\.pinned_at is not null as pinned, p\.visibility, .* left join pinned_posts pp on pp\.post_id = p\.id .* and p\.user_id = \$2 and p\.reply_to_id is null order by pp\.pinned_at desc nulls last, p\.created_at desc, p\.id desc offset \$3 limit \$4`).
+		WithArgs(uint64(1), uint64(1), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	posts, err := r.fetchPosts(models.FilterPostDTO{OwnerID: 1, UserID: 1, Offset: 0, Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, posts, 2)
+	assert.True(t, posts[0].Pinned)
+	assert.False(t, posts[1].Pinned)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_Protected(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	columns := []string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}
+	protectedQuery := regexp.QuoteMeta(`
+		select exists (
+			select 1 from users u
+			where u.id = $1 and u.protected
+			and not exists (select 1 from follows f where f.follower_id = $2 and f.following_id = u.id)
+		);
+	`)
+	mock.ExpectQuery(`and p\.user_id = \$2`).WithArgs(uint64(1), uint64(2), uint64(0), uint64(10)).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(protectedQuery).WithArgs(uint64(2), uint64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`and p\.user_id = \$2`).WithArgs(uint64(1), uint64(3), uint64(0), uint64(10)).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(protectedQuery).WithArgs(uint64(3), uint64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	posts, err := r.fetchPosts(models.FilterPostDTO{OwnerID: 2, UserID: 1, Limit: 10})
+	assert.Equal(t, ErrProtected, err, "Error mismatch")
+	assert.Nil(t, posts)
+	posts, err = r.fetchPosts(models.FilterPostDTO{OwnerID: 3, UserID: 1, Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Empty(t, posts)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
 func TestPostRepositoryImpl_DeletePost(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -282,14 +704,20 @@ func TestPostRepositoryImpl_DeletePost(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectBegin()
 			if !tc.hasError {
-				mock.ExpectExec(regexp.QuoteMeta(`update posts set deleted_at = now() where id = $1 and user_id = $2 and deleted_at is null;`)).
+				mock.ExpectQuery(deletePostQuery).
 					WithArgs(tc.id, uint64(1)).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`delete from pinned_posts where post_id = $1;`)).
+					WithArgs(tc.id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			} else {
-				mock.ExpectExec(regexp.QuoteMeta(`update posts set deleted_at = now() where id = $1 and user_id = $2 and deleted_at is null;`)).
+				mock.ExpectQuery(deletePostQuery).
 					WithArgs(tc.id, uint64(1)).
 					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			}
 
 			err := r.DeletePost(tc.id, uint64(1))
@@ -339,6 +767,9 @@ func TestPostRepositoryImpl_ViewPost(t *testing.T) {
 				timer:      time.Second,
 			}
 			r := &PostRepositoryImpl{cfg: &cfg, db: db, vb: vb}
+			mock.ExpectQuery(regexp.QuoteMeta(`select exists (`)).
+				WithArgs(tc.id, uint64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 			if tc.maxRecords == 1 {
 				mock.ExpectBegin()
 				mock.ExpectExec(regexp.QuoteMeta("create temp table tmp_views (post_id bigint, user_id bigint, created_at timestamp) on commit drop;")).
@@ -411,6 +842,9 @@ func TestPostRepositoryImpl_LikePost(t *testing.T) {
 				timer:      time.Second,
 			}
 			r := &PostRepositoryImpl{cfg: &cfg, db: db, lb: lb}
+			mock.ExpectQuery(regexp.QuoteMeta(`select exists (`)).
+				WithArgs(tc.id, uint64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 			if tc.maxRecords == 1 {
 				mock.ExpectBegin()
 				mock.ExpectExec(regexp.QuoteMeta("create temp table tmp_likes (post_id bigint, user_id bigint, created_at timestamp) on commit drop;")).
@@ -418,11 +852,15 @@ func TestPostRepositoryImpl_LikePost(t *testing.T) {
 				mock.ExpectExec(regexp.QuoteMeta(`insert into tmp_likes (post_id, user_id, created_at) values ($1, $2, $3)`)).
 					WithArgs(tc.id, uint64(1), sqlmock.AnyArg()).
 					WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec(regexp.QuoteMeta(`
-					insert into likes (post_id, user_id, created_at)
-					select post_id, user_id, min(created_at) as created_at
-					from tmp_likes group by post_id, user_id on conflict (user_id, post_id) do nothing;
-					`)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`
+					with inserted as (
+						insert into likes (post_id, user_id, created_at)
+						select post_id, user_id, min(created_at) as created_at
+						from tmp_likes group by post_id, user_id on conflict (user_id, post_id) do nothing
+						returning post_id, user_id, created_at
+					), events (user_id, actor_id, type, post_id, created_at) as (
+						select p.user_id, i.user_id, 'like', p.id, i.created_at from inserted i join posts p on p.id = i.post_id
+					)`)).WillReturnRows(sqlmock.NewRows(notifiedColumns).AddRow(4, 2, models.NotificationTypeLike))
 				mock.ExpectExec(regexp.QuoteMeta(`
 					update posts set likes_count = l.count from (
 						select post_id, count(post_id) as count from likes where post_id in (
@@ -524,3 +962,977 @@ func TestPostRepositoryImpl_DislikePost(t *testing.T) {
 		})
 	}
 }
+
+func TestPostRepositoryImpl_VotePoll(t *testing.T) {
+	testCases := []struct {
+		name      string
+		multiple  bool
+		closed    bool
+		hidden    bool
+		optionIDs []uint64
+		voted     bool
+		inserted  int64
+		err       error
+	}{
+		{
+			name:      "Success vote",
+			optionIDs: []uint64{11},
+			inserted:  1,
+			err:       nil,
+		},
+		{
+			name:      "Success multiple choice vote",
+			multiple:  true,
+			optionIDs: []uint64{11, 12},
+			inserted:  2,
+			err:       nil,
+		},
+		{
+			name:      "Poll is closed",
+			closed:    true,
+			optionIDs: []uint64{11},
+			err:       ErrPollClosed,
+		},
+		{
+			name:      "Several options in single choice poll",
+			optionIDs: []uint64{11, 12},
+			err:       ErrInvalidVote,
+		},
+		{
+			name:      "Already voted",
+			optionIDs: []uint64{11},
+			voted:     true,
+			err:       ErrAlreadyVoted,
+		},
+		{
+			name:      "Option from other poll",
+			optionIDs: []uint64{42},
+			inserted:  0,
+			err:       ErrInvalidVote,
+		},
+		{
+			name:      "Post not visible",
+			hidden:    true,
+			optionIDs: []uint64{11},
+			err:       ErrNotFound,
+		},
+	}
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	pb := &VoteBuffer{options: make(map[uint64]int), voters: make(map[uint64]int), maxRecords: 100}
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, pb: pb}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			rows := sqlmock.NewRows([]string{"id", "multiple", "closed"})
+			if !tc.hidden {
+				rows.AddRow(5, tc.multiple, tc.closed)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(`join posts p on p.id = pl.post_id and p.deleted_at is null and p.state = 'published'`)+
+				".*"+regexp.QuoteMeta(`where pl.post_id = $1 and ((p.visibility = 'public' or p.user_id = $2`)).
+				WithArgs(1, 2).WillReturnRows(rows)
+			if tc.hidden || tc.closed || (!tc.multiple && len(tc.optionIDs) > 1) {
+				mock.ExpectRollback()
+			} else {
+				var affected int64 = 1
+				if tc.voted {
+					affected = 0
+				}
+				mock.ExpectExec(regexp.QuoteMeta(`
+					insert into poll_voters (poll_id, user_id) values ($1, $2) on conflict do nothing;
+				`)).WithArgs(5, 2).WillReturnResult(sqlmock.NewResult(0, affected))
+				if tc.voted {
+					mock.ExpectRollback()
+				} else {
+					query := `
+						insert into poll_votes (poll_id, option_id, user_id)
+						select $1, id, $2 from poll_options where poll_id = $1 and id in ($3);
+					`
+					args := []driver.Value{5, 2}
+					for _, optionID := range tc.optionIDs {
+						args = append(args, optionID)
+					}
+					if len(tc.optionIDs) > 1 {
+						query = strings.Replace(query, "($3)", "($3, $4)", 1)
+					}
+					mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(args...).
+						WillReturnResult(sqlmock.NewResult(0, tc.inserted))
+					if tc.err != nil {
+						mock.ExpectRollback()
+					} else {
+						mock.ExpectCommit()
+					}
+				}
+			}
+			err := r.VotePoll(1, 2, tc.optionIDs)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+	assert.Equal(t, 2, pb.voters[5], "Voters were not buffered")
+	assert.Equal(t, 2, pb.options[11], "Votes were not buffered")
+	assert.Equal(t, 1, pb.options[12], "Votes were not buffered")
+}
+
+func TestPostRepositoryImpl_GetDrafts(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	publishAt := time.Now().Add(time.Hour)
+	readDTOs := []models.ReadPostDTO{
+		{ID: 1, Text: "Scheduled", CreatedAt: time.Now(), State: models.PostStateScheduled, PublishAt: &publishAt},
+		{ID: 2, Text: "Draft", CreatedAt: time.Now(), State: models.PostStateDraft},
+	}
+	rows := sqlmock.NewRows([]string{"id", "text", "reply_to_id", "created_at", "state", "publish_at"})
+	for _, post := range readDTOs {
+		rows.AddRow(post.ID, post.Text, post.ReplyToID, post.CreatedAt, post.State, post.PublishAt)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		select id, text, reply_to_id, created_at, state, publish_at
+		from posts
+		where user_id = $1 and state <> 'published' and deleted_at is null
+		order by publish_at asc nulls last, created_at desc
+		offset $2 limit $3;
+	`)).WithArgs(1, 0, 10).WillReturnRows(rows)
+	posts, err := r.GetDrafts(1, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, readDTOs, posts, "Drafts mismatch")
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_UpdateDraft(t *testing.T) {
+	testCases := []struct {
+		name      string
+		updateDTO models.UpdateDraftDTO
+		readDTO   *models.ReadPostDTO
+		err       error
+	}{
+		{
+			name: "Success update draft with mention",
+			updateDTO: models.UpdateDraftDTO{
+				Text:     "Hi @johndoe",
+				State:    models.PostStateDraft,
+				Mentions: []models.CreateMentionDTO{{UserName: "johndoe", Offset: 3, Length: 8}},
+			},
+			readDTO: &models.ReadPostDTO{
+				ID:        1,
+				Text:      "Hi @johndoe",
+				CreatedAt: time.Now(),
+				State:     models.PostStateDraft,
+				Entities: []models.ReadPostEntityDTO{
+					{Type: models.EntityTypeMention, Offset: 3, Length: 8, UserID: 2, UserName: "johndoe"},
+				},
+			},
+			err: nil,
+		},
+		{
+			name:      "Post is already published",
+			updateDTO: models.UpdateDraftDTO{Text: "Hi", State: models.PostStateDraft},
+			err:       ErrNotFound,
+		},
+	}
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			expect := mock.ExpectQuery(regexp.QuoteMeta(`
+				update posts set text = $1, state = $2, publish_at = $3
+				where id = $4 and user_id = $5 and state <> 'published' and deleted_at is null
+				returning id, text, reply_to_id, created_at, state, publish_at;
+			`)).WithArgs(tc.updateDTO.Text, tc.updateDTO.State, tc.updateDTO.PublishAt, 1, 1)
+			if tc.err != nil {
+				expect.WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			} else {
+				expect.WillReturnRows(sqlmock.NewRows(
+					[]string{"id", "text", "reply_to_id", "created_at", "state", "publish_at"},
+				).AddRow(tc.readDTO.ID, tc.readDTO.Text, nil, tc.readDTO.CreatedAt, tc.readDTO.State, nil))
+				mock.ExpectExec(regexp.QuoteMeta(`delete from post_mentions where post_id = $1;`)).
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`insert into post_mentions`)).
+					WithArgs(1, 1, "johndoe", 3, 8).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "start_offset", "length"}).AddRow(2, 3, 8))
+				mock.ExpectCommit()
+			}
+			post, err := r.UpdateDraft(1, 1, tc.updateDTO)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			assert.Equal(t, tc.readDTO, post, "Post mismatch")
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+var (
+	pollClosedQuery      = regexp.QuoteMeta(`select exists (select 1 from polls where post_id = $1 and closes_at <= now());`)
+	replyVisibilityQuery = `select p\.visibility, \(p\.reply_policy = 'everyone' .* from posts p where p\.id = \$1 and p\.deleted_at is null and p\.state = 'published' and `
+)
+
+func TestPostRepositoryImpl_PublishPost(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	rb := &ReplyBuffer{buffer: make(map[uint64]int), maxRecords: 10}
+	tb := &FanoutBuffer{buffer: make([]uint64, 0, 10), maxRecords: 10}
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, rb: rb, tb: tb}
+	selectQuery := regexp.QuoteMeta(`
+		select reply_to_id, visibility from posts
+		where id = $1 and user_id = $2 and state <> 'published' and deleted_at is null
+		for update;
+	`)
+	updateQuery := regexp.QuoteMeta(`
+		update posts set state = 'published', publish_at = null, created_at = now(), visibility = $2
+		where id = $1;
+	`)
+	selectColumns := []string{"reply_to_id", "visibility"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(7, "public"))
+	mock.ExpectQuery(replyVisibilityQuery).WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"visibility", "allowed"}).AddRow("followers", true))
+	mock.ExpectQuery(pollClosedQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(updateQuery).WithArgs(1, "followers").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(notifyPublishedQuery).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(notifiedColumns).AddRow(5, 2, models.NotificationTypeReply))
+	assert.Nil(t, r.PublishPost(1, 1), "Error is not nil")
+	assert.Equal(t, 1, rb.buffer[7], "Reply was not counted")
+	assert.Empty(t, tb.buffer, "Replies are not fanned out")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(3, 1).WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(nil, "public"))
+	mock.ExpectQuery(pollClosedQuery).WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(updateQuery).WithArgs(3, "public").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(notifyPublishedQuery).WithArgs(3).WillReturnRows(sqlmock.NewRows(notifiedColumns))
+	assert.Nil(t, r.PublishPost(3, 1), "Error is not nil")
+	assert.Equal(t, []uint64{3}, tb.buffer, "Post was not fanned out")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(2, 1).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	assert.Equal(t, ErrNotFound, r.PublishPost(2, 1), "Error mismatch")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(4, 1).WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(7, "public"))
+	mock.ExpectQuery(replyVisibilityQuery).WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"visibility", "allowed"}).AddRow("public", false))
+	mock.ExpectRollback()
+	assert.Equal(t, ErrReplyNotAllowed, r.PublishPost(4, 1), "Reply policy was not checked again")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(5, 1).WillReturnRows(sqlmock.NewRows(selectColumns).AddRow(nil, "public"))
+	mock.ExpectQuery(pollClosedQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	assert.Equal(t, ErrPollClosed, r.PublishPost(5, 1), "Closed poll was published")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_PublishDuePosts(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+	rb := &ReplyBuffer{buffer: make(map[uint64]int), maxRecords: 10}
+	tb := &FanoutBuffer{buffer: make([]uint64, 0, 10), maxRecords: 10}
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, rb: rb, tb: tb}
+	updateQuery := regexp.QuoteMeta(`
+				update posts set state = 'published', publish_at = null, created_at = now(), visibility = $2
+				where id = $1;
+	`)
+	draftQuery := regexp.QuoteMeta(`update posts set state = 'draft', publish_at = null where id = $1;`)
+	notClosed := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"exists"}).AddRow(false) }
+	allowed := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"visibility", "allowed"}).AddRow("public", true)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		select id, user_id, reply_to_id, visibility from posts
+		where state = 'scheduled' and publish_at <= now() and deleted_at is null
+		order by publish_at
+		limit $1
+		for update skip locked;
+	`)).WithArgs(100).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "reply_to_id", "visibility"}).
+		AddRow(1, 1, nil, "public").AddRow(2, 1, 7, "public").AddRow(3, 2, 7, "public").
+		AddRow(4, 3, 7, "public").AddRow(5, 1, nil, "public"))
+	mock.ExpectQuery(pollClosedQuery).WithArgs(1).WillReturnRows(notClosed())
+	mock.ExpectExec(updateQuery).WithArgs(1, "public").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(replyVisibilityQuery).WithArgs(7, 1).WillReturnRows(allowed())
+	mock.ExpectQuery(pollClosedQuery).WithArgs(2).WillReturnRows(notClosed())
+	mock.ExpectExec(updateQuery).WithArgs(2, "public").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(replyVisibilityQuery).WithArgs(7, 2).WillReturnRows(allowed())
+	mock.ExpectQuery(pollClosedQuery).WithArgs(3).WillReturnRows(notClosed())
+	mock.ExpectExec(updateQuery).WithArgs(3, "public").WillReturnResult(sqlmock.NewResult(0, 1))
+	// the parent's author blocked user 3 after the reply was scheduled
+	mock.ExpectQuery(replyVisibilityQuery).WithArgs(7, 3).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(draftQuery).WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(pollClosedQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(draftQuery).WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	for _, id := range []int{1, 2, 3} {
+		mock.ExpectQuery(notifyPublishedQuery).WithArgs(id).WillReturnRows(sqlmock.NewRows(notifiedColumns))
+	}
+	published, err := r.PublishDuePosts(100)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, 3, published, "Published count mismatch")
+	assert.Equal(t, 2, rb.buffer[7], "Replies were not counted")
+	assert.Equal(t, []uint64{1}, tb.buffer, "Only top-level posts are fanned out")
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_PinPost(t *testing.T) {
+	testCases := []struct {
+		name         string
+		pinned       int
+		rowsAffected int64
+		err          error
+	}{
+		{
+			name:         "Success pin post",
+			pinned:       1,
+			rowsAffected: 1,
+			err:          nil,
+		},
+		{
+			name:   "Too many pinned posts",
+			pinned: 3,
+			err:    ErrTooManyPinned,
+		},
+		{
+			name:         "Post not found",
+			pinned:       0,
+			rowsAffected: 0,
+			err:          ErrNotFound,
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`select id from users where id = $1 for update;`)).
+				WithArgs(uint64(2)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectQuery(regexp.QuoteMeta(`select count(*) from pinned_posts where user_id = $1 and post_id <> $2;`)).
+				WithArgs(uint64(2), uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tc.pinned))
+			if tc.err != ErrTooManyPinned {
+				mock.ExpectExec(regexp.QuoteMeta(`insert into pinned_posts (user_id, post_id)`)).
+					WithArgs(uint64(1), uint64(2)).
+					WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+			}
+			if tc.err == nil {
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err := r.PinPost(1, 2)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostRepositoryImpl_UnpinPost(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rowsAffected int64
+		err          error
+	}{
+		{
+			name:         "Success unpin post",
+			rowsAffected: 1,
+			err:          nil,
+		},
+		{
+			name:         "Post is not pinned",
+			rowsAffected: 0,
+			err:          ErrNotFound,
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectExec(regexp.QuoteMeta(`delete from pinned_posts where post_id = $1 and user_id = $2;`)).
+				WithArgs(uint64(1), uint64(2)).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			err := r.UnpinPost(1, 2)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostRepositoryImpl_LikeInvisiblePost(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	lb := &LikeBuffer{maxRecords: 1, timer: time.Second}
+	vb := &ViewBuffer{maxRecords: 1, timer: time.Second}
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, lb: lb, vb: vb}
+	query := regexp.QuoteMeta(`
+		select exists (
+			select 1 from posts p
+			where p.id = $1 and p.deleted_at is null and p.state = 'published' and ((p.visibility = 'public' or p.user_id = $2
+				or (p.visibility = 'followers' and exists (select 1 from follows f where f.follower_id = $2 and f.following_id = p.user_id))
+				or (p.visibility = 'mentioned' and exists (select 1 from post_mentions vm where vm.post_id = p.id and vm.user_id = $2)))
+				and (p.user_id = $2 or not exists (select 1 from users pa where pa.id = p.user_id and pa.protected)
+					or exists (select 1 from follows pf where pf.follower_id = $2 and pf.following_id = p.user_id))
+				and not exists (select 1 from blocks bk where (bk.blocker_id = $2 and bk.blocked_id = p.user_id)
+					or (bk.blocker_id = p.user_id and bk.blocked_id = $2)))
+		);
+	`)
+	mock.ExpectQuery(query).WithArgs(uint64(1), uint64(2)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(query).WithArgs(uint64(1), uint64(2)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	assert.Equal(t, ErrNotFound, r.LikePost(1, 2), "Error mismatch")
+	assert.Equal(t, ErrNotFound, r.ViewPost(1, 2), "Error mismatch")
+	assert.Empty(t, lb.likeBuffer, "Invisible like was buffered")
+	assert.Empty(t, vb.buffer, "Invisible view was buffered")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_CreateReply(t *testing.T) {
+	testCases := []struct {
+		name             string
+		visibility       string
+		parentVisibility string
+		allowed          bool
+		expected         string
+		err              error
+	}{
+		{
+			name:             "Reply to public post keeps its visibility",
+			visibility:       models.PostVisibilityFollowers,
+			parentVisibility: models.PostVisibilityPublic,
+			allowed:          true,
+			expected:         models.PostVisibilityFollowers,
+		},
+		{
+			name:             "Reply to restricted post inherits parent visibility",
+			visibility:       models.PostVisibilityPublic,
+			parentVisibility: models.PostVisibilityMentioned,
+			allowed:          true,
+			expected:         models.PostVisibilityMentioned,
+		},
+		{
+			name:             "Reply restricted by parent policy",
+			visibility:       models.PostVisibilityPublic,
+			parentVisibility: models.PostVisibilityPublic,
+			allowed:          false,
+			err:              ErrReplyNotAllowed,
+		},
+		{
+			name:       "Reply to invisible post",
+			visibility: models.PostVisibilityPublic,
+			err:        ErrNotFound,
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	rb := &ReplyBuffer{buffer: make(map[uint64]int), maxRecords: 10, timer: time.Second}
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, rb: rb}
+	replyToID := uint64(7)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			parent := mock.ExpectQuery(regexp.QuoteMeta(`select p.visibility, (p.reply_policy = 'everyone'`)).WithArgs(replyToID, uint64(1))
+			if tc.err == ErrNotFound {
+				parent.WillReturnError(sql.ErrNoRows)
+			} else {
+				parent.WillReturnRows(sqlmock.NewRows([]string{"visibility", "can_reply"}).AddRow(tc.parentVisibility, tc.allowed))
+			}
+			if tc.err != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectQuery(regexp.QuoteMeta(`insert into posts`)).
+					WithArgs("reply", uint64(1), &replyToID, models.PostStatePublished, nil, tc.expected, models.ReplyPolicyEveryone, "simple").
+					WillReturnRows(sqlmock.NewRows(
+						[]string{"id", "text", "created_at", "reply_to_id", "state", "publish_at", "visibility", "reply_policy"},
+					).AddRow(8, "reply", time.Now(), replyToID, models.PostStatePublished, nil, tc.expected, models.ReplyPolicyEveryone))
+				mock.ExpectCommit()
+			}
+
+			post, err := r.CreatePost(models.CreatePostDTO{
+				Text:       "reply",
+				UserID:     1,
+				ReplyToID:  &replyToID,
+				Visibility: tc.visibility,
+			})
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			if tc.err == nil {
+				assert.Equal(t, tc.expected, post.Visibility, "Visibility mismatch")
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_Cursor(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	columns := []string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}
+	testCases := []struct {
+		name     string
+		cursor   models.Cursor
+		expected string
+		ids      []uint64
+		want     []uint64
+	}{
+		{
+			name:     "Forward page",
+			cursor:   models.Cursor{CreatedAt: now, ID: 10},
+			expected: `and p\.reply_to_id is null and \(p\.created_at, p\.id\) < \(\$2, \$3\) order by p\.created_at desc, p\.id desc offset \$4 limit \$5`,
+			ids:      []uint64{9, 8},
+			want:     []uint64{9, 8},
+		},
+		{
+			name:     "Backward page is reversed",
+			cursor:   models.Cursor{CreatedAt: now, ID: 10, Backward: true},
+			expected: `and p\.reply_to_id is null and \(p\.created_at, p\.id\) > \(\$2, \$3\) order by p\.created_at asc, p\.id asc offset \$4 limit \$5`,
+			ids:      []uint64{11, 12},
+			want:     []uint64{12, 11},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := sqlmock.NewRows(columns)
+			for _, id := range tc.ids {
+				rows.AddRow(id, "text", nil, now, 1, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, nil)
+			}
+			mock.ExpectQuery(tc.expected).
+				WithArgs(uint64(1), tc.cursor.CreatedAt, tc.cursor.ID, uint64(0), uint64(3)).
+				WillReturnRows(rows)
+
+			cursor := tc.cursor
+			posts, err := r.fetchPosts(models.FilterPostDTO{UserID: 1, Cursor: &cursor, Limit: 3})
+			assert.Nil(t, err, "Error is not nil")
+			ids := []uint64{}
+			for _, post := range posts {
+				ids = append(ids, post.ID)
+			}
+			assert.Equal(t, tc.want, ids, "Order mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_Home(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.TimelineFanoutLimit = 500
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	rows := sqlmock.NewRows([]string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}).AddRow(1, "text", nil, time.Now(), 2, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`and (p.user_id = $1 `+
+		`or p.id in (select t.post_id from timelines t where t.user_id = $1) `+
+		`or p.user_id in ( select f.following_id from follows f join users fa on fa.id = f.following_id `+
+		`where f.follower_id = $1 and fa.followers_count >= $2 )) `+
+		`and p.reply_to_id is null order by p.created_at desc offset $3 limit $4`)).
+		WithArgs(uint64(1), 500, uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	posts, err := r.fetchPosts(models.FilterPostDTO{UserID: 1, Home: true, Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, posts, 1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_Muted(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	rows := sqlmock.NewRows([]string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}).AddRow(1, "text", nil, time.Now(), 2, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`and (p.user_id = $1 or ( `+
+		`not exists (select 1 from muted_users mu where mu.user_id = $1 and mu.muted_id = p.user_id) `+
+		`and not exists ( select 1 from muted_words mw `+
+		`where mw.user_id = $1 and (mw.expires_at is null or mw.expires_at > now()) `+
+		`and case when mw.word like '#%' `+
+		`then lower(p.text) ~ ('(^|[^[:alnum:]_])' || mw.word || '([^[:alnum:]_]|$)') `+
+		`else p.search_vector @@ (phraseto_tsquery('simple', mw.word) || `)+`.*`+regexp.QuoteMeta(`end ))) `+
+		`and p.reply_to_id is null order by p.created_at desc offset $2 limit $3`)).
+		WithArgs(uint64(1), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	posts, err := r.fetchPosts(models.FilterPostDTO{UserID: 1, Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, posts, 1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_MuteConversation(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	visible := `select exists ( select 1 from posts p where p.id = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(visible)).WithArgs(uint64(1), uint64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta(`insert into muted_conversations (user_id, post_id) values ($1, $2) on conflict (user_id, post_id) do nothing;`)).
+		WithArgs(uint64(2), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(visible)).WithArgs(uint64(3), uint64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from muted_conversations where user_id = $1 and post_id = $2;`)).
+		WithArgs(uint64(2), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Nil(t, r.MuteConversation(1, 2), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.MuteConversation(3, 2), "Error mismatch")
+	assert.Nil(t, r.UnmuteConversation(1, 2), "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_List(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	rows := sqlmock.NewRows([]string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}).AddRow(1, "text", nil, time.Now(), 2, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`and p.user_id in ( `+
+		`select lm.user_id from list_members lm join lists l on l.id = lm.list_id `+
+		`where lm.list_id = $2 and (l.user_id = $1 or (not l.private and not exists`)+`.*`+
+		regexp.QuoteMeta(`and p.reply_to_id is null order by p.created_at desc offset $3 limit $4`)).
+		WithArgs(uint64(1), uint64(5), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	posts, err := r.fetchPosts(models.FilterPostDTO{UserID: 1, ListID: 5, Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, posts, 1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fanOut(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.TimelineFanoutLimit = 500
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	tb := &FanoutBuffer{buffer: make([]uint64, 0, 2), maxRecords: 2, timer: time.Second}
+	hub := events.NewHub(events.NewMemoryBackend(10), 10, time.Minute)
+	defer hub.Close()
+	subscription := hub.Subscribe(events.UserTopic(10))
+	r := &PostRepositoryImpl{cfg: &cfg, db: db, tb: tb, hub: hub}
+	mock.ExpectQuery(`insert into timelines \(user_id, post_id, author_id, created_at\) `+
+		`select fl\.follower_id, p\.id, p\.user_id, p\.created_at from posts p `+
+		`join users a on a\.id = p\.user_id join follows fl on fl\.following_id = p\.user_id `+
+		`where p\.id in \(\$2, \$3\) and a\.followers_count < \$1 `+
+		`and \(\(p\.visibility = 'public' or p\.user_id = fl\.follower_id .* `+
+		`on conflict \(user_id, post_id\) do nothing returning user_id, post_id, author_id;`).
+		WithArgs(500, uint64(1), uint64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "post_id", "author_id"}).AddRow(10, 1, 5).AddRow(11, 3, 6))
+
+	replyToID := uint64(1)
+	r.fanOut(1, nil)
+	r.fanOut(2, &replyToID)
+	assert.Equal(t, []uint64{1}, tb.buffer, "Replies are not fanned out")
+	r.fanOut(3, nil)
+	assert.Empty(t, tb.buffer, "Buffer was not flushed")
+	event := <-subscription.Events()
+	assert.Equal(t, events.TypePost, event.Type, "Event type mismatch")
+	assert.JSONEq(t, `{"post_id":1,"user_id":5}`, string(event.Data), "Event data mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_TimelineJobs(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.TimelineFanoutLimit = 500
+	cfg.TimelineMaxLength = 800
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	mock.ExpectExec(`with picked as \( delete from timeline_rebuilds .* limit \$1 for update skip locked \) returning user_id \) `+
+		`insert into timelines .* a\.followers_count < \$2 .* p\.reply_to_id is null `+
+		`and \(\(p\.visibility = 'public' or p\.user_id = picked\.user_id .* where position <= \$3 on conflict \(user_id, post_id\) do nothing;`).
+		WithArgs(100, 500, 800).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+	mock.ExpectExec(`delete from timelines t using \( .* where position > \$1 union all .* where p\.deleted_at is not null \) stale`).
+		WithArgs(800).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	rebuilt, err := r.RebuildTimelines(100)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, int64(42), rebuilt, "Rebuilt count mismatch")
+	trimmed, err := r.TrimTimelines()
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, int64(7), trimmed, "Trimmed count mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_SearchRecent(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	rows := sqlmock.NewRows([]string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}).AddRow(1, "Gophers run", nil, time.Now(), 1, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, "Gophers <mark>run</mark>")
+	mock.ExpectQuery(`and p\.search_vector @@ \(websearch_to_tsquery\('simple', \$2\) .* and p\.reply_to_id is null order by p\.created_at desc offset \$3 limit \$4`).
+		WithArgs(uint64(1), "running", uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	posts, err := r.fetchPosts(models.FilterPostDTO{
+		UserID: 1,
+		Search: "running",
+		Query:  &search.Query{Nodes: []search.Node{search.Term{Text: "running"}}},
+		Sort:   models.PostSortRecent,
+		Limit:  10,
+	})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, posts, 1)
+	assert.Equal(t, "Gophers <mark>run</mark>", posts[0].Highlight, "Highlight mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_SearchPages(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	columns := []string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	}
+	addRows := func(rows *sqlmock.Rows, ids ...uint64) *sqlmock.Rows {
+		for _, id := range ids {
+			rows.AddRow(id, "Gophers run", nil, now, 1, "username", "first_name", "last_name", nil, 0, 0, 0, nil, nil, nil, false, "public", "everyone", true, nil)
+		}
+		return rows
+	}
+	query := &search.Query{Nodes: []search.Node{search.Term{Text: "running"}}}
+
+	// relevance would order the first page differently from the cursor that
+	// resumes it, so paged searches follow recency from the start
+	mock.ExpectQuery(`and p\.reply_to_id is null order by p\.created_at desc, p\.id desc offset \$3 limit \$4`).
+		WithArgs(uint64(1), "running", uint64(0), uint64(3)).
+		WillReturnRows(addRows(sqlmock.NewRows(columns), 9, 8, 7))
+	first, err := r.fetchPosts(models.FilterPostDTO{UserID: 1, Search: "running", Query: query, Paged: true, Limit: 3})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, first, 3)
+
+	cursor := models.Cursor{CreatedAt: first[1].CreatedAt, ID: first[1].ID}
+	mock.ExpectQuery(`and p\.reply_to_id is null and \(p\.created_at, p\.id\) < \(\$3, \$4\) order by p\.created_at desc, p\.id desc offset \$5 limit \$6`).
+		WithArgs(uint64(1), "running", cursor.CreatedAt, cursor.ID, uint64(0), uint64(3)).
+		WillReturnRows(addRows(sqlmock.NewRows(columns), 7, 6))
+	second, err := r.fetchPosts(models.FilterPostDTO{UserID: 1, Search: "running", Query: query, Paged: true, Cursor: &cursor, Limit: 3})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(7), second[0].ID, "Second page should resume after the first")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestPostRepositoryImpl_fetchPosts_SearchOperators(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &PostRepositoryImpl{cfg: &cfg, db: db}
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, err := search.Parse(`"exact phrase" -spoiler from:alice -to:bob since:2026-01-01 has:media is:reply min_likes:10`)
+	if err != nil {
+		t.Fatalf("Error parsing query: %v", err)
+	}
+	rows := sqlmock.NewRows([]string{
+		"p.id", "p.text", "p.reply_to_id", "p.created_at",
+		"u.id", "u.user_name", "u.first_name", "u.last_name", "u.deleted_at",
+		"likes_count", "views_count", "replies_count",
+		"entities", "media", "poll", "pinned", "visibility", "reply_policy", "can_reply", "highlight",
+	})
+	mock.ExpectQuery(`and p\.search_vector @@ \(websearch_to_tsquery\('simple', \$2\) .* `+
+		`and not p\.search_vector @@ \(phraseto_tsquery\('simple', \$3\) .* `+
+		`and u\.user_name = \$4 `+
+		`and not exists \(select 1 from posts tp join users tu on tu\.id = tp\.user_id where tp\.id = p\.reply_to_id and tu\.user_name = \$5\) `+
+		`and p\.created_at >= \$6 `+
+		`and exists \(select 1 from media hm where hm\.post_id = p\.id\) `+
+		`and p\.reply_to_id is not null `+
+		`and p\.likes_count >= \$7 `+
+		`order by ts_rank\(.*\) desc, p\.created_at desc offset \$8 limit \$9`).
+		WithArgs(uint64(1), `"exact phrase"`, "spoiler", "alice", "bob", since, uint64(10), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	_, err = r.fetchPosts(models.FilterPostDTO{UserID: 1, Search: "ignored", Query: query, Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	query, err := search.Parse(`go "exact phrase" -rust from:alice or gophers`)
+	assert.Nil(t, err)
+	assert.Equal(t, `go "exact phrase" or gophers`, searchText(query))
+}
+
+func TestSearchConfig(t *testing.T) {
+	assert.Equal(t, "russian", searchConfig("ru"))
+	assert.Equal(t, "english", searchConfig("en"))
+	assert.Equal(t, "simple", searchConfig(""))
+	assert.Equal(t, "simple", searchConfig("xx"))
+	for _, config := range languageConfigs {
+		assert.Contains(t, searchConfigs, config, "Language config is not searched")
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/events"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+const timelineRebuildBatchSize = 100
+
+// FanoutBuffer collects published top-level posts that still have to be
+// copied into the home timelines of their authors' followers.
+type FanoutBuffer struct {
+	buffer     []uint64
+	lock       sync.Mutex
+	maxRecords int
+	timer      time.Duration
+}
+
+func (r *PostRepositoryImpl) fanOut(postID uint64, replyToID *uint64) {
+	if replyToID != nil && *replyToID > 0 {
+		return
+	}
+	r.tb.lock.Lock()
+	defer r.tb.lock.Unlock()
+	r.tb.buffer = append(r.tb.buffer, postID)
+	if len(r.tb.buffer) >= r.tb.maxRecords {
+		r.flushFanout()
+	}
+}
+
+// flushFanout writes the buffered posts into the timelines of the followers
+// allowed to see them. Authors with TimelineFanoutLimit followers or more are
+// skipped: copying their posts would cost more than merging them in when a
+// timeline is read.
+func (r *PostRepositoryImpl) flushFanout() {
+	params := []interface{}{r.cfg.TimelineFanoutLimit}
+	placeholders := []string{}
+	for _, postID := range r.tb.buffer {
+		params = append(params, postID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+	}
+	query := fmt.Sprintf(`
+		insert into timelines (user_id, post_id, author_id, created_at)
+		select fl.follower_id, p.id, p.user_id, p.created_at
+		from posts p
+		join users a on a.id = p.user_id
+		join follows fl on fl.following_id = p.user_id
+		where p.id in (%s) and a.followers_count < $1 and %s
+		on conflict (user_id, post_id) do nothing
+		returning user_id, post_id, author_id;
+	`, strings.Join(placeholders, ", "), visibleTo("fl.follower_id"))
+	r.tb.buffer = r.tb.buffer[:0]
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		log.Printf("Failed to fan out posts: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID uint64
+		var post models.StreamPostDTO
+		if err := rows.Scan(&userID, &post.PostID, &post.UserID); err != nil {
+			log.Printf("Failed to fan out posts: %v", err)
+			return
+		}
+		r.hub.Publish(events.UserTopic(userID), events.TypePost, post)
+	}
+}
+
+func (r *PostRepositoryImpl) startFanoutTimer() {
+	ticker := time.NewTicker(r.tb.timer)
+	go func() {
+		for range ticker.C {
+			r.tb.lock.Lock()
+			if len(r.tb.buffer) > 0 {
+				r.flushFanout()
+			}
+			r.tb.lock.Unlock()
+		}
+	}()
+}
+
+// homeTimeline limits posts aliased as p to the home timeline of the user
+// bound to viewer: their own posts, posts fanned out to them, and posts of
+// followed authors too popular to fan out, bound to limit.
+func homeTimeline(viewer, limit string) string {
+	return fmt.Sprintf(`(p.user_id = %[1]s
+		or p.id in (select t.post_id from timelines t where t.user_id = %[1]s)
+		or p.user_id in (
+			select f.following_id from follows f join users fa on fa.id = f.following_id
+			where f.follower_id = %[1]s and fa.followers_count >= %[2]s
+		))`, viewer, limit)
+}
+
+// RebuildTimelines fills the timelines of users queued in timeline_rebuilds,
+// such as users who just followed someone, with the latest posts they may see
+// of the authors they follow. Claimed rows are locked with skip locked, so several
+// instances can rebuild at once.
+func (r *PostRepositoryImpl) RebuildTimelines(limit int) (int64, error) {
+	query := `
+		with picked as (
+			delete from timeline_rebuilds where user_id in (
+				select user_id from timeline_rebuilds
+				order by requested_at
+				limit $1
+				for update skip locked
+			)
+			returning user_id
+		)
+		insert into timelines (user_id, post_id, author_id, created_at)
+		select user_id, post_id, author_id, created_at from (
+			select picked.user_id, p.id as post_id, p.user_id as author_id, p.created_at,
+				row_number() over (partition by picked.user_id order by p.created_at desc, p.id desc) as position
+			from picked
+			join follows f on f.follower_id = picked.user_id
+			join users a on a.id = f.following_id and a.followers_count < $2
+			join posts p on p.user_id = f.following_id
+			where p.state = 'published' and p.deleted_at is null and p.reply_to_id is null
+				and ` + visibleTo("picked.user_id") + `
+		) latest
+		where position <= $3
+		on conflict (user_id, post_id) do nothing;
+	`
+	result, err := r.db.Exec(query, limit, r.cfg.TimelineFanoutLimit, r.cfg.TimelineMaxLength)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// TrimTimelines keeps the newest TimelineMaxLength entries of every timeline
+// and drops entries of deleted posts.
+func (r *PostRepositoryImpl) TrimTimelines() (int64, error) {
+	query := `
+		delete from timelines t using (
+			select user_id, post_id from (
+				select user_id, post_id,
+					row_number() over (partition by user_id order by created_at desc, post_id desc) as position
+				from timelines
+			) ranked
+			where position > $1
+			union all
+			select tl.user_id, tl.post_id from timelines tl
+			join posts p on p.id = tl.post_id
+			where p.deleted_at is not null
+		) stale
+		where t.user_id = stale.user_id and t.post_id = stale.post_id;
+	`
+	result, err := r.db.Exec(query, r.cfg.TimelineMaxLength)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *PostRepositoryImpl) startTimelineJobs() {
+	rebuild := time.NewTicker(r.cfg.TimelineRebuildInterval)
+	go func() {
+		for range rebuild.C {
+			if _, err := r.RebuildTimelines(timelineRebuildBatchSize); err != nil {
+				log.Printf("Failed to rebuild timelines: %v", err)
+			}
+		}
+	}()
+	trim := time.NewTicker(r.cfg.TimelineTrimInterval)
+	go func() {
+		for range trim.C {
+			if _, err := r.TrimTimelines(); err != nil {
+				log.Printf("Failed to trim timelines: %v", err)
+			}
+		}
+	}()
+}
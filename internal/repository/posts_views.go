@@ -21,6 +21,9 @@ type ViewBuffer struct {
 }
 
 func (r *PostRepositoryImpl) ViewPost(id, viewedByID uint64) error {
+	if err := r.checkVisible(id, viewedByID); err != nil {
+		return err
+	}
 	r.vb.lock.Lock()
 	defer r.vb.lock.Unlock()
 	newView := View{UserID: viewedByID, PostID: id, CreatedAt: time.Now()}
@@ -82,7 +85,12 @@ func (r *PostRepositoryImpl) flushViews() error {
 	if err != nil {
 		return err
 	}
+	postIDs := make([]uint64, len(r.vb.buffer))
+	for i, view := range r.vb.buffer {
+		postIDs[i] = view.PostID
+	}
 	r.vb.buffer = r.vb.buffer[:0]
+	r.publishCounters(postIDs)
 	return nil
 }
 
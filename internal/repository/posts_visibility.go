@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// visibleTo returns a condition on the post aliased as p that holds when the
+// user bound to the viewer placeholder is allowed to see it. Posts of a
+// protected account are only visible to its approved followers, and posts by
+// users who blocked the viewer, or whom the viewer blocked, are never visible.
+func visibleTo(viewer string) string {
+	return fmt.Sprintf(`((p.visibility = 'public' or p.user_id = %[1]s
+		or (p.visibility = 'followers' and exists (select 1 from follows f where f.follower_id = %[1]s and f.following_id = p.user_id))
+		or (p.visibility = 'mentioned' and exists (select 1 from post_mentions vm where vm.post_id = p.id and vm.user_id = %[1]s)))
+		and (p.user_id = %[1]s or not exists (select 1 from users pa where pa.id = p.user_id and pa.protected)
+			or exists (select 1 from follows pf where pf.follower_id = %[1]s and pf.following_id = p.user_id))
+		and not %[2]s)`, viewer, blockedBetween(viewer, "p.user_id"))
+}
+
+// checkProtected returns ErrProtected when the owner's account is protected
+// and the viewer is not among its followers.
+func (r *PostRepositoryImpl) checkProtected(ownerID, viewerID uint64) error {
+	query := `
+		select exists (
+			select 1 from users u
+			where u.id = $1 and u.protected
+			and not exists (select 1 from follows f where f.follower_id = $2 and f.following_id = u.id)
+		);
+	`
+	var protected bool
+	if err := r.db.QueryRow(query, ownerID, viewerID).Scan(&protected); err != nil {
+		return err
+	}
+	if protected {
+		return ErrProtected
+	}
+	return nil
+}
+
+func (r *PostRepositoryImpl) checkVisible(id, userID uint64) error {
+	query := `
+		select exists (
+			select 1 from posts p
+			where p.id = $1 and p.deleted_at is null and p.state = 'published' and ` + visibleTo("$2") + `
+		);
+	`
+	var visible bool
+	if err := r.db.QueryRow(query, id, userID).Scan(&visible); err != nil {
+		return err
+	}
+	if !visible {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// canReply returns a condition on the post aliased as p that holds when the
+// user bound to the viewer placeholder passes its reply policy.
+func canReply(viewer string) string {
+	return fmt.Sprintf(`(p.reply_policy = 'everyone' or p.user_id = %[1]s
+		or (p.reply_policy = 'following' and exists (select 1 from follows rf where rf.follower_id = p.user_id and rf.following_id = %[1]s))
+		or (p.reply_policy = 'mentioned' and exists (select 1 from post_mentions rm where rm.post_id = p.id and rm.user_id = %[1]s)))`, viewer)
+}
+
+// replyVisibility checks that the user may reply to the given post and
+// returns the visibility the reply gets: replies to restricted posts inherit
+// the parent's audience.
+func replyVisibility(tx *sql.Tx, replyToID, userID uint64, requested string) (string, error) {
+	query := `
+		select p.visibility, ` + canReply("$2") + `
+		from posts p
+		where p.id = $1 and p.deleted_at is null and p.state = 'published' and ` + visibleTo("$2") + `;
+	`
+	var visibility string
+	var allowed bool
+	err := tx.QueryRow(query, replyToID, userID).Scan(&visibility, &allowed)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "", ErrReplyNotAllowed
+	}
+	if visibility != models.PostVisibilityPublic {
+		return visibility, nil
+	}
+	return requested, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/shekshuev/gophertalk-backend/internal/models"
 )
@@ -11,9 +12,28 @@ type DatabaseChecker interface {
 }
 
 type UserRepository interface {
-	GetAllUsers(limit, offset uint64) ([]models.ReadUserDTO, error)
-	GetUserByID(id uint64) (*models.ReadUserDTO, error)
+	GetAllUsers(viewerID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	GetUsersPage(viewerID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadUserDTO, error)
+	GetUserByID(id, viewerID uint64) (*models.ReadUserDTO, error)
 	GetUserByUserName(userName string) (*models.ReadAuthUserDataDTO, error)
+	GetProfileByUserName(userName string, viewerID uint64) (*models.ReadUserDTO, error)
+	SearchUsers(viewerID uint64, q string, limit, offset uint64) ([]models.ReadUserDTO, error)
+	FollowUser(followerID, followingID uint64) (bool, error)
+	UnfollowUser(followerID, followingID uint64) error
+	GetFollows(dto models.FilterFollowDTO) ([]models.ReadUserDTO, error)
+	GetFollowRequests(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	ApproveFollowRequest(userID, requesterID uint64) error
+	RejectFollowRequest(userID, requesterID uint64) error
+	BlockUser(blockerID, blockedID uint64) error
+	UnblockUser(blockerID, blockedID uint64) error
+	GetBlockedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	MuteUser(userID, mutedID uint64) error
+	UnmuteUser(userID, mutedID uint64) error
+	GetMutedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	GetMutedWords(userID uint64) ([]models.ReadMutedWordDTO, error)
+	CreateMutedWord(userID uint64, dto models.CreateMutedWordDTO) (*models.ReadMutedWordDTO, error)
+	DeleteMutedWord(id, userID uint64) error
+	GetRecommendedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
 	CreateUser(user models.CreateUserDTO) (*models.ReadAuthUserDataDTO, error)
 	UpdateUser(id uint64, user models.UpdateUserDTO) (*models.ReadUserDTO, error)
 	DeleteUser(id uint64) error
@@ -21,11 +41,80 @@ type UserRepository interface {
 
 type PostRepository interface {
 	GetAllPosts(dto models.FilterPostDTO) ([]models.ReadPostDTO, error)
+	GetPostByID(id, userID uint64) (*models.ReadPostDTO, error)
 	CreatePost(post models.CreatePostDTO) (*models.ReadPostDTO, error)
 	DeletePost(id, ownerID uint64) error
 	ViewPost(id, viewedByID uint64) error
 	LikePost(id, likedByID uint64) error
 	DislikePost(id, dislikedByID uint64) error
+	VotePoll(postID, userID uint64, optionIDs []uint64) error
+	GetDrafts(userID, limit, offset uint64) ([]models.ReadPostDTO, error)
+	UpdateDraft(id, ownerID uint64, dto models.UpdateDraftDTO) (*models.ReadPostDTO, error)
+	PublishPost(id, ownerID uint64) error
+	PinPost(id, ownerID uint64) error
+	UnpinPost(id, ownerID uint64) error
+	MuteConversation(id, userID uint64) error
+	UnmuteConversation(id, userID uint64) error
+}
+
+type ListRepository interface {
+	CreateList(dto models.CreateListDTO) (*models.ReadListDTO, error)
+	GetListByID(id, viewerID uint64) (*models.ReadListDTO, error)
+	GetLists(dto models.FilterListDTO) ([]models.ReadListDTO, error)
+	UpdateList(id, ownerID uint64, dto models.UpdateListDTO) (*models.ReadListDTO, error)
+	DeleteList(id, ownerID uint64) error
+	AddListMember(id, ownerID, userID uint64) error
+	RemoveListMember(id, ownerID, userID uint64) error
+	GetListMembers(id, viewerID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	SubscribeList(id, userID uint64) error
+	UnsubscribeList(id, userID uint64) error
+}
+
+type NotificationRepository interface {
+	GetNotifications(userID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadNotificationDTO, error)
+	CountUnread(userID uint64) (uint64, error)
+	MarkRead(id, userID uint64) error
+	MarkAllRead(userID uint64) error
+	GetPreferences(userID uint64) (models.NotificationPreferencesDTO, error)
+	UpdatePreferences(userID uint64, dto models.NotificationPreferencesDTO) error
+}
+
+type WebhookRepository interface {
+	CreateWebhook(dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error)
+	GetWebhooks(userID uint64) ([]models.ReadWebhookDTO, error)
+	GetWebhookByID(id, userID uint64) (*models.ReadWebhookDTO, error)
+	UpdateWebhook(id, userID uint64, dto models.UpdateWebhookDTO) (*models.ReadWebhookDTO, error)
+	DeleteWebhook(id, userID uint64) error
+	GetDeliveries(webhookID, userID, limit, offset uint64) ([]models.ReadWebhookDeliveryDTO, error)
+	Redeliver(id, webhookID, userID uint64) (*models.ReadWebhookDeliveryDTO, error)
+	ClaimDeliveries(limit int, leaseUntil time.Time) ([]models.PendingWebhookDeliveryDTO, error)
+	RecordAttempt(dto models.WebhookAttemptDTO) error
+}
+
+type ChatRepository interface {
+	CreateDirectChat(userID, recipientID uint64) (uint64, bool, error)
+	CreateGroupChat(dto models.CreateGroupChatDTO) (uint64, error)
+	UpdateChat(id, userID uint64, dto models.UpdateChatDTO) error
+	AddChatMember(id, userID, memberID uint64) error
+	UpdateChatMember(id, userID, memberID uint64, role string) error
+	RemoveChatMember(id, userID, memberID uint64) error
+	GetChats(userID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadChatDTO, error)
+	GetChatByID(id, userID uint64) (*models.ReadChatDTO, error)
+	CountUnread(userID uint64) (uint64, error)
+	MarkRead(id, userID, messageID uint64) error
+	MuteChat(id, userID uint64) error
+	UnmuteChat(id, userID uint64) error
+	GetMessages(chatID, userID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadMessageDTO, error)
+	CreateMessage(dto models.CreateMessageDTO) (*models.ReadMessageDTO, error)
+	UnsendMessage(id, chatID, senderID uint64) error
+	DeleteMessage(id, chatID, userID uint64) error
+}
+
+type MediaRepository interface {
+	CreateMedia(dto models.CreateMediaDTO) (*models.ReadMediaDTO, error)
+	GetMediaByID(id, userID uint64) (*models.ReadMediaDTO, error)
+	UpdateMedia(id, ownerID uint64, dto models.UpdateMediaDTO) (*models.ReadMediaDTO, error)
+	UpdateMediaVariants(id uint64, blurhash string, variants []models.ReadMediaVariantDTO) error
 }
 
 var ErrNotFound = fmt.Errorf("not found")
@@ -33,3 +122,15 @@ var ErrUserExists = fmt.Errorf("user with same name already exists")
 var ErrNoFieldsToUpdate = fmt.Errorf("no fields to update")
 var ErrAlreadyLiked = fmt.Errorf("already liked")
 var ErrAlreadyViewed = fmt.Errorf("already viewed")
+var ErrMediaNotAvailable = fmt.Errorf("media not found or already attached")
+var ErrPollClosed = fmt.Errorf("poll is closed")
+var ErrAlreadyVoted = fmt.Errorf("already voted")
+var ErrInvalidVote = fmt.Errorf("invalid poll options")
+var ErrBlocked = fmt.Errorf("one of the users has blocked the other")
+var ErrProtected = fmt.Errorf("this account is protected")
+var ErrTooManyPinned = fmt.Errorf("no more than 3 posts can be pinned")
+var ErrReplyNotAllowed = fmt.Errorf("replies to this post are restricted by its author")
+var ErrMessagesRestricted = fmt.Errorf("this user only accepts messages from accounts they follow")
+var ErrChatAdminOnly = fmt.Errorf("only group admins can do this")
+var ErrChatFull = fmt.Errorf("group has reached its member limit")
+var ErrLastChatAdmin = fmt.Errorf("a group needs at least one admin")
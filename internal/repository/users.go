@@ -1,37 +1,82 @@
 package repository
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"database/sql"
 
 	_ "github.com/jackc/pgx/stdlib"
 
 	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/events"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
 )
 
+// userColumns selects the user aliased as users as seen by the user bound to
+// the viewer placeholder.
+func userColumns(viewer string) string {
+	return `users.id, users.user_name, users.first_name, users.last_name, users.status, users.created_at, users.updated_at, (
+		select json_build_object(
+			'id', m.id,
+			'url', m.url,
+			'mime_type', m.mime_type,
+			'width', m.width,
+			'height', m.height,
+			'alt_text', m.alt_text,
+			'blurhash', m.blurhash,
+			'variants', m.variants
+		)
+		from media m where m.id = users.avatar_media_id
+	) as avatar, users.followers_count, users.following_count, exists (
+		select 1 from follows vf where vf.follower_id = ` + viewer + ` and vf.following_id = users.id
+	) as is_followed_by_me, users.protected, exists (
+		select 1 from follow_requests vr where vr.requester_id = ` + viewer + ` and vr.target_id = users.id
+	) as is_follow_requested, users.messages_from_followers_only`
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type UserRepositoryImpl struct {
 	db  *sql.DB
 	cfg *config.Config
+	fb  *FollowBuffer
+	hub *events.Hub
 }
 
-func NewUserRepositoryImpl(cfg *config.Config) *UserRepositoryImpl {
+func NewUserRepositoryImpl(cfg *config.Config, hub *events.Hub) *UserRepositoryImpl {
 	db, err := sql.Open("pgx", cfg.DatabaseDSN)
 	if err != nil {
 		log.Fatal("Error connecting to database", err)
 		return nil
 	}
-	repository := &UserRepositoryImpl{cfg: cfg, db: db}
+	followsBufferSize := 100
+	followsBufferTimer := 5 * time.Second
+	fb := &FollowBuffer{
+		buffer:     make([]uint64, 0, followsBufferSize),
+		maxRecords: followsBufferSize,
+		timer:      followsBufferTimer,
+	}
+	repository := &UserRepositoryImpl{cfg: cfg, db: db, fb: fb, hub: hub}
+	go repository.startFollowsTimer()
+	go repository.startRecommendationsJob()
 	return repository
 }
 
 func (r *UserRepositoryImpl) CreateUser(dto models.CreateUserDTO) (*models.ReadAuthUserDataDTO, error) {
 	query := `
-		insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4)
-		returning id, user_name, password_hash, status;
+		with u as (
+			insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4)
+			returning id, user_name, password_hash, status, created_at
+		), ` + webhooksFrom(`
+			select 'user.registered', id, json_build_object('id', id, 'user_name', user_name, 'created_at', created_at)::jsonb
+			from u`) + `
+		select id, user_name, password_hash, status from u;
 	`
 	var user models.ReadAuthUserDataDTO
 	err := r.db.QueryRow(
@@ -42,39 +87,71 @@ func (r *UserRepositoryImpl) CreateUser(dto models.CreateUserDTO) (*models.ReadA
 	return &user, nil
 }
 
-func (r *UserRepositoryImpl) GetAllUsers(limit, offset uint64) ([]models.ReadUserDTO, error) {
-	query := `
-		select id, user_name, first_name, last_name, status, created_at, updated_at from users 
-		where deleted_at is null offset $1 limit $2;
-	`
+func (r *UserRepositoryImpl) GetAllUsers(viewerID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + " from users where deleted_at is null offset $2 limit $3;"
 	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
-	rows, err := r.db.Query(query, offset, limit)
+	rows, err := r.db.Query(query, viewerID, offset, limit)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
-		var user models.ReadUserDTO
-		err := rows.Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Status, &user.CreatedAt, &user.UpdatedAt)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
-		readDTO = append(readDTO, user)
+		readDTO = append(readDTO, *user)
 	}
 	return readDTO, nil
 }
 
-func (r *UserRepositoryImpl) GetUserByID(id uint64) (*models.ReadUserDTO, error) {
-	query := `
-		select 
-			id, user_name, first_name, last_name, status, created_at, updated_at 
-		from users where id = $1 and deleted_at is null;
-	`
-	var user models.ReadUserDTO
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Status, &user.CreatedAt, &user.UpdatedAt)
+func (r *UserRepositoryImpl) GetUsersPage(viewerID uint64, cursor *models.Cursor, limit uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + " from users where deleted_at is null"
+	params := []interface{}{viewerID}
+	direction := "asc"
+	if cursor != nil {
+		operator := ">"
+		if cursor.Backward {
+			direction, operator = "desc", "<"
+		}
+		if cursor.ID > 0 {
+			query += fmt.Sprintf(" and (created_at, id) %s ($2, $3)", operator)
+			params = append(params, cursor.CreatedAt, cursor.ID)
+		}
+	}
+	query += fmt.Sprintf(" order by created_at %s, id %s limit $%d;", direction, direction, len(params)+1)
+	params = append(params, limit)
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *user)
+	}
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(readDTO)-1; i < j; i, j = i+1, j-1 {
+			readDTO[i], readDTO[j] = readDTO[j], readDTO[i]
+		}
+	}
+	return readDTO, nil
+}
+
+func (r *UserRepositoryImpl) GetUserByID(id, viewerID uint64) (*models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + " from users where id = $2 and deleted_at is null;"
+	user, err := scanUser(r.db.QueryRow(query, viewerID, id))
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
 	}
-	return &user, nil
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (r *UserRepositoryImpl) GetUserByUserName(userName string) (*models.ReadAuthUserDataDTO, error) {
@@ -110,24 +187,40 @@ func (r *UserRepositoryImpl) UpdateUser(id uint64, dto models.UpdateUserDTO) (*m
 		fields = append(fields, fmt.Sprintf("last_name = $%d", len(args)+1))
 		args = append(args, dto.LastName)
 	}
+	if dto.Protected != nil {
+		fields = append(fields, fmt.Sprintf("protected = $%d", len(args)+1))
+		args = append(args, *dto.Protected)
+	}
+	if dto.MessagesFromFollowersOnly != nil {
+		fields = append(fields, fmt.Sprintf("messages_from_followers_only = $%d", len(args)+1))
+		args = append(args, *dto.MessagesFromFollowersOnly)
+	}
+	conditions := ""
+	if dto.AvatarMediaID > 0 {
+		fields = append(fields, fmt.Sprintf("avatar_media_id = $%d", len(args)+1))
+		conditions = fmt.Sprintf(" and exists (select 1 from media m where m.id = $%d and m.user_id = users.id)", len(args)+1)
+		args = append(args, dto.AvatarMediaID)
+	}
 	if len(fields) == 0 {
 		return nil, ErrNoFieldsToUpdate
 	}
 
 	fields = append(fields, "updated_at = now()")
 
-	query := fmt.Sprintf("update users set %s where id = $%d and deleted_at is null returning id, user_name, first_name, last_name, status, created_at, updated_at",
-		strings.Join(fields, ", "), len(args)+1)
+	idParam := fmt.Sprintf("$%d", len(args)+1)
+	query := fmt.Sprintf("update users set %s where id = %s and deleted_at is null%s returning %s",
+		strings.Join(fields, ", "), idParam, conditions, userColumns(idParam))
 
 	args = append(args, id)
 
-	var user models.ReadUserDTO
-	err := r.db.QueryRow(query, args...).Scan(
-		&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Status, &user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows && dto.AvatarMediaID > 0 {
+		return nil, ErrMediaNotAvailable
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepositoryImpl) DeleteUser(id uint64) error {
@@ -147,3 +240,28 @@ func (r *UserRepositoryImpl) DeleteUser(id uint64) error {
 	}
 	return nil
 }
+
+// scanUser reads the columns selected by userColumns followed by any extra
+// columns the query adds.
+func scanUser(row rowScanner, extra ...interface{}) (*models.ReadUserDTO, error) {
+	var user models.ReadUserDTO
+	var avatar []byte
+	dest := []interface{}{
+		&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Status, &user.CreatedAt, &user.UpdatedAt, &avatar,
+		&user.FollowersCount, &user.FollowingCount, &user.IsFollowedByMe,
+		&user.Protected, &user.IsFollowRequested, &user.MessagesFromFollowersOnly,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if avatar != nil {
+		if err := json.Unmarshal(avatar, &user.Avatar); err != nil {
+			return nil, err
+		}
+		if len(user.Avatar.Variants) == 0 {
+			user.Avatar.Variants = nil
+		}
+	}
+	return &user, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// blockedBetween returns a condition that holds when either of the two users
+// has blocked the other.
+func blockedBetween(a, b string) string {
+	return fmt.Sprintf(`exists (select 1 from blocks bk where (bk.blocker_id = %[1]s and bk.blocked_id = %[2]s)
+		or (bk.blocker_id = %[2]s and bk.blocked_id = %[1]s))`, a, b)
+}
+
+// BlockUser records the block and drops the follows and follow requests
+// between the two users in both directions, along with what they copied into
+// each other's timelines.
+func (r *UserRepositoryImpl) BlockUser(blockerID, blockedID uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `
+		with target as (
+			select id from users where id = $2 and deleted_at is null
+		), inserted as (
+			insert into blocks (blocker_id, blocked_id)
+			select $1, id from target
+			on conflict (blocker_id, blocked_id) do nothing
+			returning blocked_id
+		)
+		select exists (select 1 from target);
+	`
+	var found bool
+	if err = tx.QueryRow(query, blockerID, blockedID).Scan(&found); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !found {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	followsQuery := `
+		delete from follows
+		where (follower_id = $1 and following_id = $2) or (follower_id = $2 and following_id = $1);
+	`
+	result, err := tx.Exec(followsQuery, blockerID, blockedID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	unfollowed, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	requestsQuery := `
+		delete from follow_requests
+		where (requester_id = $1 and target_id = $2) or (requester_id = $2 and target_id = $1);
+	`
+	if _, err = tx.Exec(requestsQuery, blockerID, blockedID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	timelinesQuery := `
+		delete from timelines
+		where (user_id = $1 and author_id = $2) or (user_id = $2 and author_id = $1);
+	`
+	if _, err = tx.Exec(timelinesQuery, blockerID, blockedID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	if unfollowed == 0 {
+		return nil
+	}
+	return r.bufferFollowCounts(blockerID, blockedID)
+}
+
+func (r *UserRepositoryImpl) UnblockUser(blockerID, blockedID uint64) error {
+	query := `delete from blocks where blocker_id = $1 and blocked_id = $2;`
+	_, err := r.db.Exec(query, blockerID, blockedID)
+	return err
+}
+
+// GetBlockedUsers lists the users the given user has blocked, most recent
+// first, with the block time in BlockedAt.
+func (r *UserRepositoryImpl) GetBlockedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + `, b.created_at
+		from blocks b join users on users.id = b.blocked_id
+		where b.blocker_id = $1 and users.deleted_at is null
+		order by b.created_at desc, users.id desc
+		offset $2 limit $3;`
+	rows, err := r.db.Query(query, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		var blockedAt time.Time
+		user, err := scanUser(rows, &blockedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.BlockedAt = &blockedAt
+		readDTO = append(readDTO, *user)
+	}
+	return readDTO, rows.Err()
+}
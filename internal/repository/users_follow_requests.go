@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// GetFollowRequests lists the pending requests to follow the user, most recent
+// first, with the request time in RequestedAt.
+func (r *UserRepositoryImpl) GetFollowRequests(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + `, fr.created_at
+		from follow_requests fr join users on users.id = fr.requester_id
+		where fr.target_id = $1 and users.deleted_at is null
+		order by fr.created_at desc, users.id desc
+		offset $2 limit $3;`
+	rows, err := r.db.Query(query, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		var requestedAt time.Time
+		user, err := scanUser(rows, &requestedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.RequestedAt = &requestedAt
+		readDTO = append(readDTO, *user)
+	}
+	return readDTO, rows.Err()
+}
+
+// ApproveFollowRequest turns the pending request into a follow.
+func (r *UserRepositoryImpl) ApproveFollowRequest(userID, requesterID uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `delete from follow_requests where requester_id = $1 and target_id = $2;`
+	result, err := tx.Exec(query, requesterID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	followQuery := `
+		insert into follows (follower_id, following_id) values ($1, $2)
+		on conflict (follower_id, following_id) do nothing;
+	`
+	if _, err = tx.Exec(followQuery, requesterID, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	rebuildQuery := `
+		insert into timeline_rebuilds (user_id) values ($1)
+		on conflict (user_id) do nothing;
+	`
+	if _, err = tx.Exec(rebuildQuery, requesterID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	return r.bufferFollowCounts(requesterID, userID)
+}
+
+func (r *UserRepositoryImpl) RejectFollowRequest(userID, requesterID uint64) error {
+	query := `delete from follow_requests where requester_id = $1 and target_id = $2;`
+	result, err := r.db.Exec(query, requesterID, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
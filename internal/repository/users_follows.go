@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// FollowBuffer collects the users whose follower or following counters
+// changed so they can be recounted in one statement.
+type FollowBuffer struct {
+	buffer     []uint64
+	lock       sync.Mutex
+	maxRecords int
+	timer      time.Duration
+}
+
+// FollowUser follows the user, or asks to when the account is protected. It
+// reports whether the follow waits for the owner's approval.
+func (r *UserRepositoryImpl) FollowUser(followerID, followingID uint64) (bool, error) {
+	query := `
+		with target as (
+			select id, ` + blockedBetween("$1", "id") + ` as blocked,
+				protected and not exists (select 1 from follows ef where ef.follower_id = $1 and ef.following_id = users.id) as pending
+			from users where id = $2 and deleted_at is null
+		), inserted as (
+			insert into follows (follower_id, following_id)
+			select $1, id from target where not blocked and not pending
+			on conflict (follower_id, following_id) do nothing
+			returning following_id
+		), requested as (
+			insert into follow_requests (requester_id, target_id)
+			select $1, id from target where not blocked and pending
+			on conflict (requester_id, target_id) do nothing
+			returning target_id
+		), ` + notificationsFrom(`
+			select following_id, $1::bigint, 'follow', null::bigint, now() from inserted
+			union all
+			select target_id, $1::bigint, 'follow_request', null::bigint, now() from requested`) + `
+		select exists (select 1 from target), exists (select 1 from target where blocked),
+			exists (select 1 from target where pending), exists (select 1 from inserted),
+			(select g.id from grouped g where g.id in (select notification_id from notified));
+	`
+	var found, blocked, pending, inserted bool
+	var notificationID *uint64
+	err := r.db.QueryRow(query, followerID, followingID).Scan(&found, &blocked, &pending, &inserted, &notificationID)
+	if err != nil {
+		return false, err
+	}
+	if notificationID != nil {
+		notificationType := models.NotificationTypeFollow
+		if pending {
+			notificationType = models.NotificationTypeFollowRequest
+		}
+		publishNotified(r.hub, []notified{{id: *notificationID, userID: followingID, notificationType: notificationType}})
+	}
+	if !found {
+		return false, ErrNotFound
+	}
+	if blocked {
+		return false, ErrBlocked
+	}
+	if pending || !inserted {
+		return pending, nil
+	}
+	// the background rebuild copies the new author's recent posts in
+	rebuildQuery := `
+		insert into timeline_rebuilds (user_id) values ($1)
+		on conflict (user_id) do nothing;
+	`
+	if _, err := r.db.Exec(rebuildQuery, followerID); err != nil {
+		return false, err
+	}
+	return false, r.bufferFollowCounts(followerID, followingID)
+}
+
+// UnfollowUser stops following the user and withdraws a pending request.
+func (r *UserRepositoryImpl) UnfollowUser(followerID, followingID uint64) error {
+	requestQuery := `delete from follow_requests where requester_id = $1 and target_id = $2;`
+	if _, err := r.db.Exec(requestQuery, followerID, followingID); err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	query := `delete from follows where follower_id = $1 and following_id = $2;`
+	result, err := tx.Exec(query, followerID, followingID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rowsAffected == 0 {
+		return tx.Rollback()
+	}
+	timelineQuery := `delete from timelines where user_id = $1 and author_id = $2;`
+	if _, err = tx.Exec(timelineQuery, followerID, followingID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	return r.bufferFollowCounts(followerID, followingID)
+}
+
+func (r *UserRepositoryImpl) bufferFollowCounts(followerID, followingID uint64) error {
+	r.fb.lock.Lock()
+	defer r.fb.lock.Unlock()
+	r.fb.buffer = append(r.fb.buffer, followerID, followingID)
+	if len(r.fb.buffer) >= r.fb.maxRecords {
+		return r.flushFollowCounts()
+	}
+	return nil
+}
+
+// flushFollowCounts recounts rather than increments, so a follow that was
+// undone before the flush or a failed flush cannot leave counters drifting.
+func (r *UserRepositoryImpl) flushFollowCounts() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	createQuery := "create temp table tmp_follow_counts (user_id bigint) on commit drop;"
+	if _, err = tx.Exec(createQuery); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	insertToTmpQuery := `insert into tmp_follow_counts (user_id) values `
+	params := []interface{}{}
+	values := []string{}
+	for i, userID := range r.fb.buffer {
+		values = append(values, fmt.Sprintf("($%d)", i+1))
+		params = append(params, userID)
+	}
+	insertToTmpQuery += strings.Join(values, ",")
+	if _, err = tx.Exec(insertToTmpQuery, params...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	updateQuery := `
+	update users set
+		followers_count = (select count(*) from follows f where f.following_id = users.id),
+		following_count = (select count(*) from follows f where f.follower_id = users.id)
+	where id in (select distinct user_id from tmp_follow_counts);
+	`
+	if _, err = tx.Exec(updateQuery); err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	r.fb.buffer = r.fb.buffer[:0]
+	return nil
+}
+
+func (r *UserRepositoryImpl) startFollowsTimer() {
+	ticker := time.NewTicker(r.fb.timer)
+	go func() {
+		for range ticker.C {
+			r.fb.lock.Lock()
+			if len(r.fb.buffer) > 0 {
+				r.flushFollowCounts()
+			}
+			r.fb.lock.Unlock()
+		}
+	}()
+}
+
+// GetFollows lists one side of a user's follow graph, most recent follows
+// first. The follow time of every user is returned in FollowedAt and anchors
+// the cursor.
+func (r *UserRepositoryImpl) GetFollows(dto models.FilterFollowDTO) ([]models.ReadUserDTO, error) {
+	join, filter := "f.follower_id", "f.following_id"
+	if dto.Following {
+		join, filter = "f.following_id", "f.follower_id"
+	}
+	query := fmt.Sprintf(
+		"select %s, f.created_at from follows f join users on users.id = %s where %s = $2 and users.deleted_at is null",
+		userColumns("$1"), join, filter,
+	)
+	params := []interface{}{dto.ViewerID, dto.UserID}
+	direction := "desc"
+	if dto.Cursor != nil {
+		operator := "<"
+		if dto.Cursor.Backward {
+			direction, operator = "asc", ">"
+		}
+		if dto.Cursor.ID > 0 {
+			query += fmt.Sprintf(" and (f.created_at, users.id) %s ($3, $4)", operator)
+			params = append(params, dto.Cursor.CreatedAt, dto.Cursor.ID)
+		}
+	}
+	query += fmt.Sprintf(" order by f.created_at %s, users.id %s", direction, direction)
+	if dto.Cursor == nil {
+		query += fmt.Sprintf(" offset $%d", len(params)+1)
+		params = append(params, dto.Offset)
+	}
+	query += fmt.Sprintf(" limit $%d;", len(params)+1)
+	params = append(params, dto.Limit)
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		var followedAt time.Time
+		user, err := scanUser(rows, &followedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.FollowedAt = &followedAt
+		readDTO = append(readDTO, *user)
+	}
+	if dto.Cursor != nil && dto.Cursor.Backward {
+		for i, j := 0, len(readDTO)-1; i < j; i, j = i+1, j-1 {
+			readDTO[i], readDTO[j] = readDTO[j], readDTO[i]
+		}
+	}
+	return readDTO, nil
+}
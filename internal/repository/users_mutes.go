@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+func (r *UserRepositoryImpl) MuteUser(userID, mutedID uint64) error {
+	query := `
+		with target as (
+			select id from users where id = $2 and deleted_at is null
+		), inserted as (
+			insert into muted_users (user_id, muted_id)
+			select $1, id from target
+			on conflict (user_id, muted_id) do nothing
+			returning muted_id
+		)
+		select exists (select 1 from target);
+	`
+	var found bool
+	if err := r.db.QueryRow(query, userID, mutedID).Scan(&found); err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) UnmuteUser(userID, mutedID uint64) error {
+	query := `delete from muted_users where user_id = $1 and muted_id = $2;`
+	_, err := r.db.Exec(query, userID, mutedID)
+	return err
+}
+
+// GetMutedUsers lists the users the given user has muted, most recent first,
+// with the mute time in MutedAt.
+func (r *UserRepositoryImpl) GetMutedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + `, mu.created_at
+		from muted_users mu join users on users.id = mu.muted_id
+		where mu.user_id = $1 and users.deleted_at is null
+		order by mu.created_at desc, users.id desc
+		offset $2 limit $3;`
+	rows, err := r.db.Query(query, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		var mutedAt time.Time
+		user, err := scanUser(rows, &mutedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.MutedAt = &mutedAt
+		readDTO = append(readDTO, *user)
+	}
+	return readDTO, rows.Err()
+}
+
+// GetMutedWords lists the words the user has muted that have not expired.
+func (r *UserRepositoryImpl) GetMutedWords(userID uint64) ([]models.ReadMutedWordDTO, error) {
+	query := `
+		select id, word, created_at, expires_at from muted_words
+		where user_id = $1 and (expires_at is null or expires_at > now())
+		order by created_at desc, id desc;
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadMutedWordDTO = make([]models.ReadMutedWordDTO, 0)
+	for rows.Next() {
+		var word models.ReadMutedWordDTO
+		if err := rows.Scan(&word.ID, &word.Word, &word.CreatedAt, &word.ExpiresAt); err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, word)
+	}
+	return readDTO, rows.Err()
+}
+
+// CreateMutedWord mutes the word for the user. Muting a word again replaces
+// its expiry.
+func (r *UserRepositoryImpl) CreateMutedWord(userID uint64, dto models.CreateMutedWordDTO) (*models.ReadMutedWordDTO, error) {
+	query := `
+		insert into muted_words (user_id, word, expires_at) values ($1, $2, $3)
+		on conflict (user_id, word) do update set expires_at = excluded.expires_at, created_at = now()
+		returning id, word, created_at, expires_at;
+	`
+	var word models.ReadMutedWordDTO
+	err := r.db.QueryRow(query, userID, dto.Word, dto.ExpiresAt).Scan(&word.ID, &word.Word, &word.CreatedAt, &word.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &word, nil
+}
+
+func (r *UserRepositoryImpl) DeleteMutedWord(id, userID uint64) error {
+	query := `delete from muted_words where id = $1 and user_id = $2 returning id;`
+	var deletedID uint64
+	err := r.db.QueryRow(query, id, userID).Scan(&deletedID)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+// recommendationsLock is the advisory lock key held while recommendations are
+// recomputed, so that only one instance does the work at a time. It is a
+// session lock, as the work is split across several transactions.
+const recommendationsLock int64 = 0x7265636f
+
+// notRecommendable filters out candidates aliased as users that the user
+// bound to viewer already follows, asked to follow, muted, blocked or was
+// blocked by.
+func notRecommendable(viewer string) string {
+	return `not exists (select 1 from follows rf where rf.follower_id = ` + viewer + ` and rf.following_id = users.id)
+		and not exists (select 1 from follow_requests rr where rr.requester_id = ` + viewer + ` and rr.target_id = users.id)
+		and not exists (select 1 from muted_users rm where rm.user_id = ` + viewer + ` and rm.muted_id = users.id)
+		and not ` + blockedBetween(viewer, "users.id")
+}
+
+// RefreshRecommendations recomputes who-to-follow suggestions for every user.
+// Candidates come from friends of friends, weighted by the number of followed
+// users who follow them, and from co-engagement, weighted by the number of
+// posts liked by both users within RecommendationsLikesAge. Only the latest
+// RecommendationsLikesPerPost likes of each post are paired, so a viral post
+// does not blow up the join. Each user keeps the RecommendationsLimit best
+// candidates. Users are refreshed RecommendationsBatchSize at a time, each
+// batch replacing its own rows in a short transaction, so suggestions are
+// never missing while the refresh runs. It returns -1 without doing anything
+// if another instance is already refreshing.
+func (r *UserRepositoryImpl) RefreshRecommendations() (int64, error) {
+	ctx := context.Background()
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "select pg_try_advisory_lock($1);", recommendationsLock).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return -1, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "select pg_advisory_unlock($1);", recommendationsLock); err != nil {
+			log.Printf("Failed to release recommendations lock: %v", err)
+		}
+	}()
+	since := time.Now().Add(-r.cfg.RecommendationsLikesAge)
+	var total int64
+	var after uint64
+	for {
+		last, count, err := r.refreshRecommendationsBatch(ctx, conn, after, since)
+		if err != nil {
+			return total, err
+		}
+		if last == 0 {
+			return total, nil
+		}
+		total += count
+		after = last
+	}
+}
+
+// refreshRecommendationsBatch replaces the suggestions of the next batch of
+// users with ids above after. It returns the last id of the batch, or zero
+// when there are no users left.
+func (r *UserRepositoryImpl) refreshRecommendationsBatch(ctx context.Context, conn *sql.Conn, after uint64, since time.Time) (uint64, int64, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	var last sql.NullInt64
+	err = tx.QueryRow(
+		"select max(id) from (select id from users where id > $1 order by id limit $2) batch;",
+		after, r.cfg.RecommendationsBatchSize,
+	).Scan(&last)
+	if err != nil || !last.Valid {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	if _, err := tx.Exec("delete from recommendations where user_id > $1 and user_id <= $2;", after, last.Int64); err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	query := `
+		with recent_likes as (
+			select user_id, post_id from (
+				select user_id, post_id, row_number() over (partition by post_id order by created_at desc) as position
+				from likes
+				where created_at > $1 and post_id in (
+					select post_id from likes where user_id > $3 and user_id <= $4 and created_at > $1
+				)
+			) ranked
+			where position <= $5
+		), candidates as (
+			select f1.follower_id as user_id, f2.following_id as recommended_id, 1.0 as weight, 'follows' as reason
+			from follows f1 join follows f2 on f2.follower_id = f1.following_id
+			where f1.follower_id > $3 and f1.follower_id <= $4
+			union all
+			select l1.user_id, l2.user_id, 0.5, 'likes'
+			from likes l1 join recent_likes l2 on l2.post_id = l1.post_id and l2.user_id <> l1.user_id
+			where l1.user_id > $3 and l1.user_id <= $4 and l1.created_at > $1
+		), scored as (
+			select c.user_id, users.id as recommended_id, sum(c.weight) as score,
+				case when coalesce(sum(c.weight) filter (where c.reason = 'follows'), 0)
+					>= coalesce(sum(c.weight) filter (where c.reason = 'likes'), 0)
+					then 'follows' else 'likes' end as reason
+			from candidates c
+			join users on users.id = c.recommended_id and users.deleted_at is null
+			where c.user_id <> c.recommended_id and ` + notRecommendable("c.user_id") + `
+			group by c.user_id, users.id
+		)
+		insert into recommendations (user_id, recommended_id, score, reason)
+		select user_id, recommended_id, score, reason from (
+			select scored.*, row_number() over (partition by user_id order by score desc, recommended_id) as position
+			from scored
+		) ranked
+		where position <= $2;
+	`
+	result, err := tx.Exec(query, since, r.cfg.RecommendationsLimit, after, last.Int64, r.cfg.RecommendationsLikesPerPost)
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	count, err := result.RowsAffected()
+	return uint64(last.Int64), count, err
+}
+
+// GetRecommendedUsers lists the precomputed suggestions for the user, best
+// first. Follows, mutes and blocks made since the last refresh are applied on
+// read, so a suggestion disappears as soon as it stops being valid.
+func (r *UserRepositoryImpl) GetRecommendedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + `, rc.reason
+		from recommendations rc join users on users.id = rc.recommended_id
+		where rc.user_id = $1 and users.deleted_at is null and ` + notRecommendable("$1") + `
+		order by rc.score desc, users.id
+		offset $2 limit $3;`
+	rows, err := r.db.Query(query, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		var reason string
+		user, err := scanUser(rows, &reason)
+		if err != nil {
+			return nil, err
+		}
+		user.RecommendationReason = reason
+		readDTO = append(readDTO, *user)
+	}
+	return readDTO, rows.Err()
+}
+
+func (r *UserRepositoryImpl) startRecommendationsJob() {
+	ticker := time.NewTicker(r.cfg.RecommendationsInterval)
+	go func() {
+		for range ticker.C {
+			if _, err := r.RefreshRecommendations(); err != nil {
+				log.Printf("Failed to refresh recommendations: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers matches q as a prefix of, or similar to, any of the user's
+// names. Prefix matches on the user name come first, then closer matches,
+// then users with more followers.
+func (r *UserRepositoryImpl) SearchUsers(viewerID uint64, q string, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + ` from users
+		where deleted_at is null and (
+			user_name ilike $3 or first_name ilike $3 or last_name ilike $3
+			or user_name % $2 or first_name % $2 or last_name % $2
+		)
+		order by
+			user_name ilike $3 desc,
+			greatest(similarity(user_name, $2), similarity(first_name, $2), similarity(last_name, $2)) desc,
+			followers_count desc,
+			id
+		offset $4 limit $5;`
+	rows, err := r.db.Query(query, viewerID, q, likeEscaper.Replace(q)+"%", offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadUserDTO = make([]models.ReadUserDTO, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *user)
+	}
+	return readDTO, nil
+}
+
+func (r *UserRepositoryImpl) GetProfileByUserName(userName string, viewerID uint64) (*models.ReadUserDTO, error) {
+	query := "select " + userColumns("$1") + " from users where user_name = $2 and deleted_at is null;"
+	user, err := scanUser(r.db.QueryRow(query, viewerID, userName))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
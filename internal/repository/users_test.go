@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"regexp"
 	"testing"
 	"time"
@@ -12,6 +14,40 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func avatarColumns(viewer string) string {
+	return `users.id, users.user_name, users.first_name, users.last_name, users.status, users.created_at, users.updated_at, (
+	select json_build_object(
+		'id', m.id,
+		'url', m.url,
+		'mime_type', m.mime_type,
+		'width', m.width,
+		'height', m.height,
+		'alt_text', m.alt_text,
+		'blurhash', m.blurhash,
+		'variants', m.variants
+	)
+	from media m where m.id = users.avatar_media_id
+) as avatar, users.followers_count, users.following_count, exists (
+	select 1 from follows vf where vf.follower_id = ` + viewer + ` and vf.following_id = users.id
+) as is_followed_by_me, users.protected, exists (
+	select 1 from follow_requests vr where vr.requester_id = ` + viewer + ` and vr.target_id = users.id
+) as is_follow_requested, users.messages_from_followers_only`
+}
+
+var userRowColumns = []string{
+	"id", "user_name", "first_name", "last_name", "status", "created_at", "updated_at", "avatar",
+	"followers_count", "following_count", "is_followed_by_me", "protected", "is_follow_requested",
+	"messages_from_followers_only",
+}
+
+func avatarValue(user *models.ReadUserDTO) driver.Value {
+	if user.Avatar == nil {
+		return nil
+	}
+	data, _ := json.Marshal(user.Avatar)
+	return data
+}
+
 func TestUserRepositoryImpl_CreateUser(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -57,9 +93,13 @@ func TestUserRepositoryImpl_CreateUser(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			if !tc.hasError {
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4) 
-					returning id, user_name, password_hash, status;
-					`)).
+					with u as (
+						insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4)
+						returning id, user_name, password_hash, status, created_at
+					), hooked (event, user_id, payload) as (
+						select 'user.registered', id, json_build_object('id', id, 'user_name', user_name, 'created_at', created_at)::jsonb
+						from u
+					)`)+".*"+regexp.QuoteMeta(`select id, user_name, password_hash, status from u;`)).
 					WithArgs(
 						tc.createDTO.UserName,
 						tc.createDTO.FirstName,
@@ -77,9 +117,10 @@ func TestUserRepositoryImpl_CreateUser(t *testing.T) {
 					)
 			} else {
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4) 
-					returning id, user_name, password_hash, status;
-					`)).
+					with u as (
+						insert into users (user_name, first_name, last_name, password_hash) values ($1, $2, $3, $4)
+						returning id, user_name, password_hash, status, created_at
+					)`)).
 					WithArgs(
 						tc.createDTO.UserName,
 						tc.createDTO.FirstName,
@@ -127,6 +168,17 @@ func TestUserRepositoryImpl_GetAllUsers(t *testing.T) {
 					Status:    1,
 					CreatedAt: time.Now(),
 					UpdatedAt: time.Now(),
+					Avatar: &models.ReadMediaDTO{
+						ID:       3,
+						URL:      "/v1.0/media/files/media/3.jpg",
+						MimeType: "image/jpeg",
+						Width:    400,
+						Height:   400,
+						Blurhash: "LEHV6nWB2yk8pyo0adR*.7kCMdnj",
+						Variants: []models.ReadMediaVariantDTO{
+							{Name: "thumb", URL: "/v1.0/media/files/media/3_thumb.jpg", MimeType: "image/jpeg", Width: 150, Height: 150},
+						},
+					},
 				},
 			},
 			hasError: false,
@@ -150,23 +202,21 @@ func TestUserRepositoryImpl_GetAllUsers(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if !tc.hasError {
-				rows := sqlmock.NewRows([]string{
-					"id", "user_name", "first_name", "last_name", "status", "created_at", "updated_at",
-				})
+				rows := sqlmock.NewRows(userRowColumns)
 				for _, user := range tc.readDTOs {
-					rows.AddRow(user.ID, user.UserName, user.FirstName, user.LastName, user.Status, user.CreatedAt, user.UpdatedAt)
+					rows.AddRow(user.ID, user.UserName, user.FirstName, user.LastName, user.Status, user.CreatedAt, user.UpdatedAt, avatarValue(&user), user.FollowersCount, user.FollowingCount, user.IsFollowedByMe, user.Protected, user.IsFollowRequested, user.MessagesFromFollowersOnly)
 				}
 
-				mock.ExpectQuery(regexp.QuoteMeta(`select id, user_name, first_name, last_name, status, created_at, updated_at from users where deleted_at is null offset $1 limit $2;`)).
-					WithArgs(0, 100).
+				mock.ExpectQuery(regexp.QuoteMeta(`select `+avatarColumns("$1")+` from users where deleted_at is null offset $2 limit $3;`)).
+					WithArgs(1, 0, 100).
 					WillReturnRows(rows)
 			} else {
-				mock.ExpectQuery(regexp.QuoteMeta(`select id, user_name, first_name, last_name, status, created_at, updated_at from users where deleted_at is null offset $1 limit $2;`)).
-					WithArgs(0, 100).
+				mock.ExpectQuery(regexp.QuoteMeta(`select `+avatarColumns("$1")+` from users where deleted_at is null offset $2 limit $3;`)).
+					WithArgs(1, 0, 100).
 					WillReturnError(sql.ErrNoRows)
 			}
 
-			users, err := r.GetAllUsers(100, 0)
+			users, err := r.GetAllUsers(1, 100, 0)
 			if tc.hasError {
 				assert.NotNil(t, err, "Error is nil")
 			} else {
@@ -222,22 +272,22 @@ func TestUserRepositoryImpl_GetUserByID(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if !tc.hasError {
-				rows := sqlmock.NewRows([]string{
-					"id", "user_name", "first_name", "last_name", "status", "created_at", "updated_at",
-				}).AddRow(
+				rows := sqlmock.NewRows(userRowColumns).AddRow(
 					tc.readDTO.ID, tc.readDTO.UserName, tc.readDTO.FirstName, tc.readDTO.LastName, tc.readDTO.Status, tc.readDTO.CreatedAt, tc.readDTO.UpdatedAt,
+					avatarValue(tc.readDTO), tc.readDTO.FollowersCount, tc.readDTO.FollowingCount, tc.readDTO.IsFollowedByMe,
+					tc.readDTO.Protected, tc.readDTO.IsFollowRequested, tc.readDTO.MessagesFromFollowersOnly,
 				)
 
-				mock.ExpectQuery(regexp.QuoteMeta(`select id, user_name, first_name, last_name, status, created_at, updated_at from users where id = $1 and deleted_at is null;`)).
-					WithArgs(tc.id).
+				mock.ExpectQuery(regexp.QuoteMeta(`select `+avatarColumns("$1")+` from users where id = $2 and deleted_at is null;`)).
+					WithArgs(uint64(1), tc.id).
 					WillReturnRows(rows)
 			} else {
-				mock.ExpectQuery(regexp.QuoteMeta(`select id, user_name, first_name, last_name, status, created_at, updated_at from users where id = $1 and deleted_at is null;`)).
-					WithArgs(tc.id).
+				mock.ExpectQuery(regexp.QuoteMeta(`select `+avatarColumns("$1")+` from users where id = $2 and deleted_at is null;`)).
+					WithArgs(uint64(1), tc.id).
 					WillReturnError(sql.ErrNoRows)
 			}
 
-			user, err := r.GetUserByID(tc.id)
+			user, err := r.GetUserByID(tc.id, 1)
 			if tc.hasError {
 				assert.NotNil(t, err, "Error is nil")
 				assert.Nil(t, user, "User should be nil")
@@ -373,13 +423,13 @@ func TestUserRepositoryImpl_UpdateUser(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if !tc.hasError {
-				rows := sqlmock.NewRows([]string{
-					"id", "user_name", "first_name", "last_name", "status", "created_at", "updated_at",
-				}).AddRow(
+				rows := sqlmock.NewRows(userRowColumns).AddRow(
 					tc.readDTO.ID, tc.readDTO.UserName, tc.readDTO.FirstName, tc.readDTO.LastName, tc.readDTO.Status, tc.readDTO.CreatedAt, tc.readDTO.UpdatedAt,
+					avatarValue(tc.readDTO), tc.readDTO.FollowersCount, tc.readDTO.FollowingCount, tc.readDTO.IsFollowedByMe,
+					tc.readDTO.Protected, tc.readDTO.IsFollowRequested, tc.readDTO.MessagesFromFollowersOnly,
 				)
 
-				mock.ExpectQuery(regexp.QuoteMeta(`update users set password_hash = $1, user_name = $2, first_name = $3, last_name = $4, updated_at = now() where id = $5 and deleted_at is null returning id, user_name, first_name, last_name, status, created_at, updated_at`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`update users set password_hash = $1, user_name = $2, first_name = $3, last_name = $4, updated_at = now() where id = $5 and deleted_at is null returning `+avatarColumns("$5"))).
 					WithArgs(
 						tc.updateDTO.PasswordHash,
 						tc.updateDTO.UserName,
@@ -388,7 +438,7 @@ func TestUserRepositoryImpl_UpdateUser(t *testing.T) {
 						tc.id).
 					WillReturnRows(rows)
 			} else {
-				mock.ExpectQuery(regexp.QuoteMeta(`update users set user_name = $1, updated_at = now() where id = $2 and deleted_at is null returning id, user_name, first_name, last_name, status, created_at, updated_at`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`update users set user_name = $1, updated_at = now() where id = $2 and deleted_at is null returning `+avatarColumns("$2"))).
 					WithArgs(tc.updateDTO.UserName, tc.id).
 					WillReturnError(sql.ErrNoRows)
 			}
@@ -461,3 +511,728 @@ func TestUserRepositoryImpl_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepositoryImpl_UpdateUserAvatar(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`update users set avatar_media_id = $1, updated_at = now() where id = $2 and deleted_at is null and exists (select 1 from media m where m.id = $1 and m.user_id = users.id) returning ` + avatarColumns("$2"))
+
+	mock.ExpectQuery(query).WithArgs(uint64(5), uint64(1)).WillReturnError(sql.ErrNoRows)
+	user, err := r.UpdateUser(1, models.UpdateUserDTO{AvatarMediaID: 5})
+	assert.Equal(t, ErrMediaNotAvailable, err, "Error mismatch")
+	assert.Nil(t, user, "User should be nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_GetUsersPage(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name   string
+		cursor *models.Cursor
+		query  string
+		args   []driver.Value
+		ids    []uint64
+		want   []uint64
+	}{
+		{
+			name:  "First page",
+			query: `select ` + avatarColumns("$1") + ` from users where deleted_at is null order by created_at asc, id asc limit $2;`,
+			args:  []driver.Value{uint64(1), uint64(3)},
+			ids:   []uint64{1, 2},
+			want:  []uint64{1, 2},
+		},
+		{
+			name:   "Next page",
+			cursor: &models.Cursor{CreatedAt: now, ID: 2},
+			query:  `select ` + avatarColumns("$1") + ` from users where deleted_at is null and (created_at, id) > ($2, $3) order by created_at asc, id asc limit $4;`,
+			args:   []driver.Value{uint64(1), now, uint64(2), uint64(3)},
+			ids:    []uint64{3, 4},
+			want:   []uint64{3, 4},
+		},
+		{
+			name:   "Previous page is reversed",
+			cursor: &models.Cursor{CreatedAt: now, ID: 5, Backward: true},
+			query:  `select ` + avatarColumns("$1") + ` from users where deleted_at is null and (created_at, id) < ($2, $3) order by created_at desc, id desc limit $4;`,
+			args:   []driver.Value{uint64(1), now, uint64(5), uint64(3)},
+			ids:    []uint64{4, 3},
+			want:   []uint64{3, 4},
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := sqlmock.NewRows(userRowColumns)
+			for _, id := range tc.ids {
+				rows.AddRow(id, "john", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(tc.query)).WithArgs(tc.args...).WillReturnRows(rows)
+
+			users, err := r.GetUsersPage(1, tc.cursor, 3)
+			assert.Nil(t, err, "Error is not nil")
+			ids := []uint64{}
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tc.want, ids, "Order mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryImpl_SearchUsers(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	rows := sqlmock.NewRows(userRowColumns).AddRow(1, "john_doe", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false)
+	mock.ExpectQuery(`select .* from users where deleted_at is null and \( user_name ilike \$3 .* or user_name % \$2 .* \) `+
+		`order by user_name ilike \$3 desc, greatest\(similarity\(user_name, \$2\), .*\) desc, `+
+		`followers_count desc, id offset \$4 limit \$5;`).
+		WithArgs(uint64(1), "jo_h%", `jo\_h\%%`, uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	users, err := r.SearchUsers(1, "jo_h%", 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, users, 1)
+	assert.Equal(t, "john_doe", users[0].UserName, "User name mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_GetProfileByUserName(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	query := regexp.QuoteMeta(`select ` + avatarColumns("$1") + ` from users where user_name = $2 and deleted_at is null;`)
+	rows := sqlmock.NewRows(userRowColumns).AddRow(1, "john", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false)
+	mock.ExpectQuery(query).WithArgs(uint64(1), "john").WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs(uint64(1), "ghost").WillReturnError(sql.ErrNoRows)
+
+	user, err := r.GetProfileByUserName("john", 1)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(1), user.ID, "ID mismatch")
+	_, err = r.GetProfileByUserName("ghost", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_FollowUser(t *testing.T) {
+	testCases := []struct {
+		name       string
+		found      bool
+		blocked    bool
+		pending    bool
+		inserted   bool
+		maxRecords int
+		buffered   int
+		err        error
+	}{
+		{
+			name:       "Follow flushes counters",
+			found:      true,
+			inserted:   true,
+			maxRecords: 2,
+		},
+		{
+			name:       "Follow buffers counters",
+			found:      true,
+			inserted:   true,
+			maxRecords: 10,
+			buffered:   2,
+		},
+		{
+			name:       "Repeated follow leaves counters alone",
+			found:      true,
+			maxRecords: 10,
+		},
+		{
+			name:       "User not found",
+			maxRecords: 10,
+			err:        ErrNotFound,
+		},
+		{
+			name:       "Blocked user",
+			found:      true,
+			blocked:    true,
+			maxRecords: 10,
+			err:        ErrBlocked,
+		},
+		{
+			name:       "Protected user gets a request",
+			found:      true,
+			pending:    true,
+			maxRecords: 10,
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := &FollowBuffer{
+				buffer:     make([]uint64, 0, tc.maxRecords),
+				maxRecords: tc.maxRecords,
+				timer:      time.Second,
+			}
+			r := &UserRepositoryImpl{cfg: &cfg, db: db, fb: fb}
+			mock.ExpectQuery(regexp.QuoteMeta(`
+				events (user_id, actor_id, type, post_id, created_at) as (
+					select following_id, $1::bigint, 'follow', null::bigint, now() from inserted
+					union all
+					select target_id, $1::bigint, 'follow_request', null::bigint, now() from requested
+				)`)+".*"+regexp.QuoteMeta(`
+				select exists (select 1 from target), exists (select 1 from target where blocked),
+					exists (select 1 from target where pending), exists (select 1 from inserted),
+					(select g.id from grouped g where g.id in (select notification_id from notified));
+				`)).
+				WithArgs(uint64(1), uint64(2)).
+				WillReturnRows(sqlmock.NewRows([]string{"found", "blocked", "pending", "inserted", "notification_id"}).
+					AddRow(tc.found, tc.blocked, tc.pending, tc.inserted, nil))
+			if tc.inserted {
+				mock.ExpectExec(regexp.QuoteMeta(`insert into timeline_rebuilds (user_id) values ($1) on conflict (user_id) do nothing;`)).
+					WithArgs(uint64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tc.inserted && tc.maxRecords == 2 {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("create temp table tmp_follow_counts (user_id bigint) on commit drop;")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`insert into tmp_follow_counts (user_id) values ($1),($2)`)).
+					WithArgs(uint64(1), uint64(2)).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				mock.ExpectExec(regexp.QuoteMeta(`
+					update users set
+						followers_count = (select count(*) from follows f where f.following_id = users.id),
+						following_count = (select count(*) from follows f where f.follower_id = users.id)
+					where id in (select distinct user_id from tmp_follow_counts);
+					`)).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				mock.ExpectCommit()
+			}
+
+			pending, err := r.FollowUser(1, 2)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.pending, pending, "Pending mismatch")
+			assert.Len(t, r.fb.buffer, tc.buffered, "Buffer size mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryImpl_UnfollowUser(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	fb := &FollowBuffer{buffer: make([]uint64, 0, 10), maxRecords: 10, timer: time.Second}
+	r := &UserRepositoryImpl{cfg: &cfg, db: db, fb: fb}
+	query := regexp.QuoteMeta(`delete from follows where follower_id = $1 and following_id = $2;`)
+	requestQuery := regexp.QuoteMeta(`delete from follow_requests where requester_id = $1 and target_id = $2;`)
+	mock.ExpectExec(requestQuery).WithArgs(uint64(1), uint64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WithArgs(uint64(1), uint64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from timelines where user_id = $1 and author_id = $2;`)).
+		WithArgs(uint64(1), uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectCommit()
+	mock.ExpectExec(requestQuery).WithArgs(uint64(1), uint64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WithArgs(uint64(1), uint64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.Nil(t, r.UnfollowUser(1, 2), "Error is not nil")
+	assert.Equal(t, []uint64{1, 2}, r.fb.buffer, "Counters should be queued")
+	assert.Nil(t, r.UnfollowUser(1, 2), "Repeated unfollow should succeed")
+	assert.Len(t, r.fb.buffer, 2, "Repeated unfollow should not queue counters")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_BlockUser(t *testing.T) {
+	testCases := []struct {
+		name       string
+		found      bool
+		unfollowed int64
+		buffered   int
+		err        error
+	}{
+		{
+			name:       "Block drops follows",
+			found:      true,
+			unfollowed: 2,
+			buffered:   2,
+		},
+		{
+			name:  "Block without follows",
+			found: true,
+		},
+		{
+			name: "User not found",
+			err:  ErrNotFound,
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := &FollowBuffer{buffer: make([]uint64, 0, 10), maxRecords: 10, timer: time.Second}
+			r := &UserRepositoryImpl{cfg: &cfg, db: db, fb: fb}
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`
+				insert into blocks (blocker_id, blocked_id)
+				select $1, id from target
+				on conflict (blocker_id, blocked_id) do nothing
+				returning blocked_id
+				)
+				select exists (select 1 from target);
+				`)).
+				WithArgs(uint64(1), uint64(2)).
+				WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(tc.found))
+			if tc.found {
+				mock.ExpectExec(regexp.QuoteMeta(`
+					delete from follows
+					where (follower_id = $1 and following_id = $2) or (follower_id = $2 and following_id = $1);
+					`)).
+					WithArgs(uint64(1), uint64(2)).
+					WillReturnResult(sqlmock.NewResult(0, tc.unfollowed))
+				mock.ExpectExec(regexp.QuoteMeta(`
+					delete from follow_requests
+					where (requester_id = $1 and target_id = $2) or (requester_id = $2 and target_id = $1);
+					`)).
+					WithArgs(uint64(1), uint64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`
+					delete from timelines
+					where (user_id = $1 and author_id = $2) or (user_id = $2 and author_id = $1);
+					`)).
+					WithArgs(uint64(1), uint64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err := r.BlockUser(1, 2)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Len(t, r.fb.buffer, tc.buffered, "Buffer size mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryImpl_UnblockUser(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	mock.ExpectExec(regexp.QuoteMeta(`delete from blocks where blocker_id = $1 and blocked_id = $2;`)).
+		WithArgs(uint64(1), uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Nil(t, r.UnblockUser(1, 2), "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_GetBlockedUsers(t *testing.T) {
+	now := time.Now()
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	query := `select ` + avatarColumns("$1") + `, b.created_at from blocks b join users on users.id = b.blocked_id where b.blocker_id = $1 and users.deleted_at is null order by b.created_at desc, users.id desc offset $2 limit $3;`
+	rows := sqlmock.NewRows(append(userRowColumns, "blocked_at")).
+		AddRow(3, "john", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, now).
+		AddRow(2, "jane", "Jane", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, now)
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(uint64(1), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	users, err := r.GetBlockedUsers(1, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, users, 2, "Users count mismatch")
+	for _, user := range users {
+		assert.NotNil(t, user.BlockedAt, "Block time is missing")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_MuteUser(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`
+		insert into muted_users (user_id, muted_id)
+		select $1, id from target
+		on conflict (user_id, muted_id) do nothing
+		returning muted_id
+		)
+		select exists (select 1 from target);
+	`)
+	mock.ExpectQuery(query).WithArgs(uint64(1), uint64(2)).WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(true))
+	mock.ExpectQuery(query).WithArgs(uint64(1), uint64(3)).WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from muted_users where user_id = $1 and muted_id = $2;`)).
+		WithArgs(uint64(1), uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Nil(t, r.MuteUser(1, 2), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.MuteUser(1, 3), "Error mismatch")
+	assert.Nil(t, r.UnmuteUser(1, 2), "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_MutedWords(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	columns := []string{"id", "word", "created_at", "expires_at"}
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		insert into muted_words (user_id, word, expires_at) values ($1, $2, $3)
+		on conflict (user_id, word) do update set expires_at = excluded.expires_at, created_at = now()
+		returning id, word, created_at, expires_at;
+	`)).
+		WithArgs(uint64(1), "#spoilers", &expiresAt).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(5, "#spoilers", now, expiresAt))
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		select id, word, created_at, expires_at from muted_words
+		where user_id = $1 and (expires_at is null or expires_at > now())
+		order by created_at desc, id desc;
+	`)).
+		WithArgs(uint64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(5, "#spoilers", now, expiresAt).AddRow(4, "election", now, nil))
+	deleteQuery := regexp.QuoteMeta(`delete from muted_words where id = $1 and user_id = $2 returning id;`)
+	mock.ExpectQuery(deleteQuery).WithArgs(uint64(5), uint64(1)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(deleteQuery).WithArgs(uint64(5), uint64(1)).WillReturnError(sql.ErrNoRows)
+
+	word, err := r.CreateMutedWord(1, models.CreateMutedWordDTO{Word: "#spoilers", ExpiresAt: &expiresAt})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(5), word.ID, "ID mismatch")
+	words, err := r.GetMutedWords(1)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, words, 2, "Words count mismatch")
+	assert.Nil(t, words[1].ExpiresAt, "Permanent mute has an expiry")
+	assert.Nil(t, r.DeleteMutedWord(5, 1), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.DeleteMutedWord(5, 1), "Error mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_ApproveFollowRequest(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	fb := &FollowBuffer{buffer: make([]uint64, 0, 10), maxRecords: 10, timer: time.Second}
+	r := &UserRepositoryImpl{cfg: &cfg, db: db, fb: fb}
+	requestQuery := regexp.QuoteMeta(`delete from follow_requests where requester_id = $1 and target_id = $2;`)
+	mock.ExpectBegin()
+	mock.ExpectExec(requestQuery).WithArgs(uint64(2), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`
+		insert into follows (follower_id, following_id) values ($1, $2)
+		on conflict (follower_id, following_id) do nothing;
+	`)).WithArgs(uint64(2), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`insert into timeline_rebuilds (user_id) values ($1) on conflict (user_id) do nothing;`)).
+		WithArgs(uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(requestQuery).WithArgs(uint64(3), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectExec(requestQuery).WithArgs(uint64(2), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(requestQuery).WithArgs(uint64(3), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Nil(t, r.ApproveFollowRequest(1, 2), "Error is not nil")
+	assert.Equal(t, []uint64{2, 1}, r.fb.buffer, "Counters should be queued")
+	assert.Equal(t, ErrNotFound, r.ApproveFollowRequest(1, 3), "Error mismatch")
+	assert.Nil(t, r.RejectFollowRequest(1, 2), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.RejectFollowRequest(1, 3), "Error mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_GetFollowRequests(t *testing.T) {
+	now := time.Now()
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	query := `select ` + avatarColumns("$1") + `, fr.created_at from follow_requests fr join users on users.id = fr.requester_id where fr.target_id = $1 and users.deleted_at is null order by fr.created_at desc, users.id desc offset $2 limit $3;`
+	rows := sqlmock.NewRows(append(userRowColumns, "requested_at")).
+		AddRow(2, "jane", "Jane", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, now)
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(uint64(1), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	users, err := r.GetFollowRequests(1, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, users, 1, "Users count mismatch")
+	assert.NotNil(t, users[0].RequestedAt, "Request time is missing")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_GetFollows(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name  string
+		dto   models.FilterFollowDTO
+		query string
+		args  []driver.Value
+		ids   []uint64
+		want  []uint64
+	}{
+		{
+			name:  "Followers by offset",
+			dto:   models.FilterFollowDTO{UserID: 2, ViewerID: 1, Limit: 10, Offset: 5},
+			query: `select ` + avatarColumns("$1") + `, f.created_at from follows f join users on users.id = f.follower_id where f.following_id = $2 and users.deleted_at is null order by f.created_at desc, users.id desc offset $3 limit $4;`,
+			args:  []driver.Value{uint64(1), uint64(2), uint64(5), uint64(10)},
+			ids:   []uint64{3, 4},
+			want:  []uint64{3, 4},
+		},
+		{
+			name:  "Following after cursor",
+			dto:   models.FilterFollowDTO{UserID: 2, ViewerID: 1, Following: true, Cursor: &models.Cursor{CreatedAt: now, ID: 7}, Limit: 10},
+			query: `select ` + avatarColumns("$1") + `, f.created_at from follows f join users on users.id = f.following_id where f.follower_id = $2 and users.deleted_at is null and (f.created_at, users.id) < ($3, $4) order by f.created_at desc, users.id desc limit $5;`,
+			args:  []driver.Value{uint64(1), uint64(2), now, uint64(7), uint64(10)},
+			ids:   []uint64{6, 5},
+			want:  []uint64{6, 5},
+		},
+		{
+			name:  "Previous page is reversed",
+			dto:   models.FilterFollowDTO{UserID: 2, ViewerID: 1, Cursor: &models.Cursor{CreatedAt: now, ID: 7, Backward: true}, Limit: 10},
+			query: `select ` + avatarColumns("$1") + `, f.created_at from follows f join users on users.id = f.follower_id where f.following_id = $2 and users.deleted_at is null and (f.created_at, users.id) > ($3, $4) order by f.created_at asc, users.id asc limit $5;`,
+			args:  []driver.Value{uint64(1), uint64(2), now, uint64(7), uint64(10)},
+			ids:   []uint64{8, 9},
+			want:  []uint64{9, 8},
+		},
+	}
+
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := sqlmock.NewRows(append(userRowColumns, "followed_at"))
+			for _, id := range tc.ids {
+				rows.AddRow(id, "john", "John", "Doe", 1, now, now, nil, 0, 0, true, false, false, false, now)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(tc.query)).WithArgs(tc.args...).WillReturnRows(rows)
+
+			users, err := r.GetFollows(tc.dto)
+			assert.Nil(t, err, "Error is not nil")
+			ids := []uint64{}
+			for _, user := range users {
+				ids = append(ids, user.ID)
+				assert.True(t, user.IsFollowedByMe, "Followed flag mismatch")
+				assert.NotNil(t, user.FollowedAt, "Follow time is missing")
+			}
+			assert.Equal(t, tc.want, ids, "Order mismatch")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Not all expectations were met: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryImpl_RefreshRecommendations(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	cfg.RecommendationsBatchSize = 100
+	cfg.RecommendationsLikesPerPost = 50
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	lockQuery := regexp.QuoteMeta(`select pg_try_advisory_lock($1);`)
+	unlockQuery := regexp.QuoteMeta(`select pg_advisory_unlock($1);`)
+	batchQuery := regexp.QuoteMeta(`select max(id) from (select id from users where id > $1 order by id limit $2) batch;`)
+	deleteQuery := regexp.QuoteMeta(`delete from recommendations where user_id > $1 and user_id <= $2;`)
+	insertQuery := `with recent_likes as .* where position <= \$5 .* ` +
+		`insert into recommendations \(user_id, recommended_id, score, reason\)`
+
+	mock.ExpectQuery(lockQuery).
+		WithArgs(recommendationsLock).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(batchQuery).
+		WithArgs(uint64(0), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(120))
+	mock.ExpectExec(deleteQuery).
+		WithArgs(uint64(0), int64(120)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(insertQuery).
+		WithArgs(sqlmock.AnyArg(), cfg.RecommendationsLimit, uint64(0), int64(120), 50).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(batchQuery).
+		WithArgs(uint64(120), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(130))
+	mock.ExpectExec(deleteQuery).
+		WithArgs(uint64(120), int64(130)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(insertQuery).
+		WithArgs(sqlmock.AnyArg(), cfg.RecommendationsLimit, uint64(120), int64(130), 50).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(batchQuery).
+		WithArgs(uint64(130), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	mock.ExpectRollback()
+	mock.ExpectExec(unlockQuery).
+		WithArgs(recommendationsLock).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	count, err := r.RefreshRecommendations()
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, int64(6), count, "Recommendations count mismatch")
+
+	mock.ExpectQuery(lockQuery).
+		WithArgs(recommendationsLock).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	count, err = r.RefreshRecommendations()
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, int64(-1), count, "Refresh should be skipped while another instance holds the lock")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestUserRepositoryImpl_GetRecommendedUsers(t *testing.T) {
+	now := time.Now()
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &UserRepositoryImpl{cfg: &cfg, db: db}
+	query := `select ` + avatarColumns("$1") + `, rc.reason from recommendations rc join users on users.id = rc.recommended_id where rc.user_id = $1 and users.deleted_at is null and ` + notRecommendable("$1") + ` order by rc.score desc, users.id offset $2 limit $3;`
+	rows := sqlmock.NewRows(append(userRowColumns, "reason")).
+		AddRow(3, "john", "John", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, "follows").
+		AddRow(2, "jane", "Jane", "Doe", 1, now, now, nil, 0, 0, false, false, false, false, "likes")
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs(uint64(1), uint64(0), uint64(10)).
+		WillReturnRows(rows)
+
+	users, err := r.GetRecommendedUsers(1, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, users, 2, "Users count mismatch")
+	assert.Equal(t, "follows", users[0].RecommendationReason, "Recommendation reason mismatch")
+	assert.Equal(t, "likes", users[1].RecommendationReason, "Recommendation reason mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
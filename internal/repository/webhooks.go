@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+type WebhookRepositoryImpl struct {
+	db  *sql.DB
+	cfg *config.Config
+}
+
+func NewWebhookRepositoryImpl(cfg *config.Config) *WebhookRepositoryImpl {
+	db, err := sql.Open("pgx", cfg.DatabaseDSN)
+	if err != nil {
+		log.Fatal("Error connecting to database", err)
+		return nil
+	}
+	repository := &WebhookRepositoryImpl{cfg: cfg, db: db}
+	return repository
+}
+
+// webhooksFrom returns common table expressions, to follow a with keyword,
+// that queue deliveries for the events selected by source. The source yields
+// the event name, the user the event is about and its payload. Active
+// webhooks subscribed to the event receive it when their owner is that user
+// or when they watch all users.
+func webhooksFrom(source string) string {
+	return `hooked (event, user_id, payload) as (` + source + `
+		), queued as (
+			insert into webhook_deliveries (webhook_id, event, payload)
+			select w.id, h.event, h.payload from hooked h
+			join webhook_events we on we.event = h.event
+			join webhooks w on w.id = we.webhook_id
+			where w.active and (w.all_users or w.user_id = h.user_id)
+			returning id
+		)`
+}
+
+const webhookColumns = `w.id, w.url,
+	coalesce((select string_agg(we.event, ',' order by we.event) from webhook_events we where we.webhook_id = w.id), ''),
+	w.all_users, w.active, w.failures, w.disabled_at, w.created_at, w.updated_at`
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts,
+	case when d.status = 'pending' then d.next_attempt_at end, d.last_attempt_at,
+	d.response_status, d.error, d.created_at`
+
+func scanWebhook(row rowScanner) (*models.ReadWebhookDTO, error) {
+	var webhook models.ReadWebhookDTO
+	var events string
+	err := row.Scan(
+		&webhook.ID, &webhook.URL, &events, &webhook.AllUsers, &webhook.Active,
+		&webhook.Failures, &webhook.DisabledAt, &webhook.CreatedAt, &webhook.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	webhook.Events = []string{}
+	if events != "" {
+		webhook.Events = strings.Split(events, ",")
+	}
+	return &webhook, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.ReadWebhookDeliveryDTO, error) {
+	var delivery models.ReadWebhookDeliveryDTO
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.Event, &payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &delivery.LastAttemptAt, &delivery.ResponseStatus, &delivery.Error,
+		&delivery.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return &delivery, nil
+}
+
+// eventValues returns a values list of the events with placeholders numbered
+// after the given params, which it extends.
+func eventValues(params []interface{}, events []string) ([]interface{}, string) {
+	sorted := append([]string{}, events...)
+	sort.Strings(sorted)
+	values := []string{}
+	for _, event := range sorted {
+		params = append(params, event)
+		values = append(values, fmt.Sprintf("($%d)", len(params)))
+	}
+	return params, strings.Join(values, ", ")
+}
+
+func (r *WebhookRepositoryImpl) CreateWebhook(dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	params, values := eventValues([]interface{}{dto.UserID, dto.URL, dto.Secret, dto.AllUsers}, dto.Events)
+	query := fmt.Sprintf(`
+		with w as (
+			insert into webhooks (user_id, url, secret, all_users) values ($1, $2, $3, $4)
+			returning id, url, all_users, active, failures, disabled_at, created_at, updated_at
+		), e as (
+			insert into webhook_events (webhook_id, event)
+			select w.id, v.event from w, (values %s) as v (event)
+		)
+		select w.id, w.url, $%d, w.all_users, w.active, w.failures, w.disabled_at, w.created_at, w.updated_at from w;
+	`, values, len(params)+1)
+	// events inserted by the statement are not visible to it, so echo them
+	sorted := append([]string{}, dto.Events...)
+	sort.Strings(sorted)
+	params = append(params, strings.Join(sorted, ","))
+	webhook, err := scanWebhook(r.db.QueryRow(query, params...))
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = dto.Secret
+	return webhook, nil
+}
+
+func (r *WebhookRepositoryImpl) GetWebhooks(userID uint64) ([]models.ReadWebhookDTO, error) {
+	query := "select " + webhookColumns + " from webhooks w where w.user_id = $1 order by w.id;"
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadWebhookDTO = make([]models.ReadWebhookDTO, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *webhook)
+	}
+	return readDTO, rows.Err()
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookByID(id, userID uint64) (*models.ReadWebhookDTO, error) {
+	query := "select " + webhookColumns + " from webhooks w where w.id = $1 and w.user_id = $2;"
+	return scanWebhook(r.db.QueryRow(query, id, userID))
+}
+
+// UpdateWebhook replaces the events when given. Activating the webhook
+// clears the failures that disabled it.
+func (r *WebhookRepositoryImpl) UpdateWebhook(id, userID uint64, dto models.UpdateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	fields := make([]string, 0)
+	args := []interface{}{id, userID}
+	if dto.URL != "" {
+		args = append(args, dto.URL)
+		fields = append(fields, fmt.Sprintf("url = $%d", len(args)))
+	}
+	if dto.Active != nil {
+		args = append(args, *dto.Active)
+		fields = append(fields, fmt.Sprintf("active = $%d", len(args)))
+		if *dto.Active {
+			fields = append(fields, "failures = 0", "disabled_at = null")
+		}
+	}
+	if len(fields) == 0 && len(dto.Events) == 0 {
+		return nil, ErrNoFieldsToUpdate
+	}
+	fields = append(fields, "updated_at = now()")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("update webhooks set %s where id = $1 and user_id = $2 returning id;", strings.Join(fields, ", "))
+	if err := tx.QueryRow(query, args...).Scan(&id); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if len(dto.Events) > 0 {
+		if _, err := tx.Exec(`delete from webhook_events where webhook_id = $1;`, id); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		params, values := eventValues([]interface{}{id}, dto.Events)
+		query := fmt.Sprintf(`
+			insert into webhook_events (webhook_id, event) select $1, v.event from (values %s) as v (event);
+		`, values)
+		if _, err := tx.Exec(query, params...); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetWebhookByID(id, userID)
+}
+
+func (r *WebhookRepositoryImpl) DeleteWebhook(id, userID uint64) error {
+	query := `delete from webhooks where id = $1 and user_id = $2;`
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetDeliveries lists the deliveries of the user's webhook, newest first.
+func (r *WebhookRepositoryImpl) GetDeliveries(webhookID, userID, limit, offset uint64) ([]models.ReadWebhookDeliveryDTO, error) {
+	query := "select " + webhookDeliveryColumns + `
+		from webhook_deliveries d join webhooks w on w.id = d.webhook_id
+		where d.webhook_id = $1 and w.user_id = $2
+		order by d.id desc
+		offset $3 limit $4;`
+	rows, err := r.db.Query(query, webhookID, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var readDTO []models.ReadWebhookDeliveryDTO = make([]models.ReadWebhookDeliveryDTO, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		readDTO = append(readDTO, *delivery)
+	}
+	return readDTO, rows.Err()
+}
+
+// Redeliver queues a new delivery with the payload of an earlier one.
+func (r *WebhookRepositoryImpl) Redeliver(id, webhookID, userID uint64) (*models.ReadWebhookDeliveryDTO, error) {
+	query := `
+		with d as (
+			insert into webhook_deliveries (webhook_id, event, payload)
+			select od.webhook_id, od.event, od.payload
+			from webhook_deliveries od join webhooks w on w.id = od.webhook_id
+			where od.id = $1 and od.webhook_id = $2 and w.user_id = $3
+			returning *
+		)
+		select ` + webhookDeliveryColumns + " from d;"
+	return scanWebhookDelivery(r.db.QueryRow(query, id, webhookID, userID))
+}
+
+// ClaimDeliveries takes up to limit due deliveries of active webhooks and
+// postpones them until leaseUntil, so that other instances skip them while
+// they are sent. A delivery whose attempt is never recorded, because the
+// instance stopped, is retried once the lease ends.
+func (r *WebhookRepositoryImpl) ClaimDeliveries(limit int, leaseUntil time.Time) ([]models.PendingWebhookDeliveryDTO, error) {
+	query := `
+		with due as (
+			select d.id from webhook_deliveries d join webhooks w on w.id = d.webhook_id
+			where d.status = 'pending' and d.next_attempt_at <= now() and w.active
+			order by d.next_attempt_at
+			limit $1
+			for update of d skip locked
+		), claimed as (
+			update webhook_deliveries d set next_attempt_at = $2
+			from due where d.id = due.id
+			returning d.id, d.webhook_id, d.event, d.payload, d.attempts, d.created_at
+		)
+		select c.id, c.webhook_id, w.url, w.secret, c.event, c.payload, c.attempts, c.created_at
+		from claimed c join webhooks w on w.id = c.webhook_id
+		order by c.id;
+	`
+	rows, err := r.db.Query(query, limit, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	claimed := []models.PendingWebhookDeliveryDTO{}
+	for rows.Next() {
+		var delivery models.PendingWebhookDeliveryDTO
+		var payload []byte
+		err := rows.Scan(
+			&delivery.ID, &delivery.WebhookID, &delivery.URL, &delivery.Secret, &delivery.Event,
+			&payload, &delivery.Attempts, &delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Payload = payload
+		claimed = append(claimed, delivery)
+	}
+	return claimed, rows.Err()
+}
+
+// RecordAttempt stores the outcome of an attempt. Failed attempts count
+// against the webhook, which is disabled after WebhookDisableAfter of them in
+// a row; a successful one resets the count.
+func (r *WebhookRepositoryImpl) RecordAttempt(dto models.WebhookAttemptDTO) error {
+	query := `
+		with attempt as (
+			update webhook_deliveries set
+				attempts = attempts + 1,
+				last_attempt_at = now(),
+				status = case when $2::boolean then 'succeeded' when $5::timestamp is null then 'failed' else 'pending' end,
+				next_attempt_at = coalesce($5, next_attempt_at),
+				response_status = $3,
+				error = nullif($4, '')
+			where id = $1
+			returning webhook_id
+		)
+		update webhooks w set
+			failures = case when $2 then 0 else w.failures + 1 end,
+			active = w.active and ($2 or w.failures + 1 < $6),
+			disabled_at = case when w.active and not $2 and w.failures + 1 >= $6 then now() else w.disabled_at end
+		from attempt where w.id = attempt.webhook_id;
+	`
+	_, err := r.db.Exec(
+		query, dto.DeliveryID, dto.Succeeded, dto.ResponseStatus, dto.Error, dto.NextAttemptAt,
+		r.cfg.WebhookDisableAfter,
+	)
+	return err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookRowColumns = []string{
+	"id", "url", "events", "all_users", "active", "failures", "disabled_at", "created_at", "updated_at",
+}
+
+func TestWebhookRepositoryImpl_CreateWebhook(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &WebhookRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into webhooks (user_id, url, secret, all_users) values ($1, $2, $3, $4)`)+
+		".*"+regexp.QuoteMeta(`(values ($5), ($6)) as v (event)`)+".*"+regexp.QuoteMeta(`select w.id, w.url, $7,`)).
+		WithArgs(uint64(1), "https://example.com/hook", "secret", false, "like.created", "post.created", "like.created,post.created").
+		WillReturnRows(sqlmock.NewRows(webhookRowColumns).
+			AddRow(1, "https://example.com/hook", "like.created,post.created", false, true, 0, nil, now, now))
+
+	webhook, err := r.CreateWebhook(models.CreateWebhookDTO{
+		UserID: 1,
+		URL:    "https://example.com/hook",
+		Events: []string{"post.created", "like.created"},
+		Secret: "secret",
+	})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, []string{"like.created", "post.created"}, webhook.Events, "Events mismatch")
+	assert.Equal(t, "secret", webhook.Secret, "Secret should be returned on creation")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestWebhookRepositoryImpl_UpdateWebhook(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &WebhookRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	active := true
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`update webhooks set active = $3, failures = 0, disabled_at = null, updated_at = now() where id = $1 and user_id = $2 returning id;`)).
+		WithArgs(uint64(2), uint64(1), true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta(`delete from webhook_events where webhook_id = $1;`)).
+		WithArgs(uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`select $1, v.event from (values ($2)) as v (event);`)).
+		WithArgs(uint64(2), "post.deleted").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`from webhooks w where w.id = $1 and w.user_id = $2;`)).
+		WithArgs(uint64(2), uint64(1)).
+		WillReturnRows(sqlmock.NewRows(webhookRowColumns).
+			AddRow(2, "https://example.com/hook", "post.deleted", false, true, 0, nil, now, now))
+
+	webhook, err := r.UpdateWebhook(2, 1, models.UpdateWebhookDTO{Events: []string{"post.deleted"}, Active: &active})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, []string{"post.deleted"}, webhook.Events, "Events mismatch")
+
+	_, err = r.UpdateWebhook(2, 1, models.UpdateWebhookDTO{})
+	assert.Equal(t, ErrNoFieldsToUpdate, err, "Empty update should be rejected")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`update webhooks set url = $3, updated_at = now() where id = $1 and user_id = $2 returning id;`)).
+		WithArgs(uint64(3), uint64(1), "https://example.com/other").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+	_, err = r.UpdateWebhook(3, 1, models.UpdateWebhookDTO{URL: "https://example.com/other"})
+	assert.Equal(t, ErrNotFound, err, "Foreign webhook should not be found")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestWebhookRepositoryImpl_DeleteWebhook(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &WebhookRepositoryImpl{cfg: &cfg, db: db}
+	query := regexp.QuoteMeta(`delete from webhooks where id = $1 and user_id = $2;`)
+	mock.ExpectExec(query).WithArgs(uint64(2), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(uint64(3), uint64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Nil(t, r.DeleteWebhook(2, 1), "Error is not nil")
+	assert.Equal(t, ErrNotFound, r.DeleteWebhook(3, 1), "Foreign webhook should not be found")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestWebhookRepositoryImpl_ClaimDeliveries(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &WebhookRepositoryImpl{cfg: &cfg, db: db}
+	now := time.Now()
+	lease := now.Add(time.Minute)
+	mock.ExpectQuery(regexp.QuoteMeta(`for update of d skip locked`)+".*"+
+		regexp.QuoteMeta(`update webhook_deliveries d set next_attempt_at = $2`)).
+		WithArgs(20, lease).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "url", "secret", "event", "payload", "attempts", "created_at"}).
+			AddRow(7, 2, "https://example.com/hook", "secret", "post.created", []byte(`{"id":1}`), 1, now))
+
+	deliveries, err := r.ClaimDeliveries(20, lease)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, deliveries, 1, "Deliveries count mismatch")
+	assert.Equal(t, "https://example.com/hook", deliveries[0].URL, "URL mismatch")
+	assert.JSONEq(t, `{"id":1}`, string(deliveries[0].Payload), "Payload mismatch")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
+
+func TestWebhookRepositoryImpl_RecordAttempt(t *testing.T) {
+	cfg := config.GetConfig()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("Error creating db mock: %v", err)
+	}
+	defer db.Close()
+
+	r := &WebhookRepositoryImpl{cfg: &cfg, db: db}
+	status := 500
+	next := time.Now().Add(time.Minute)
+	mock.ExpectExec(regexp.QuoteMeta(`update webhook_deliveries set`)+".*"+
+		regexp.QuoteMeta(`active = w.active and ($2 or w.failures + 1 < $6)`)).
+		WithArgs(uint64(7), false, &status, "500 Internal Server Error", &next, cfg.WebhookDisableAfter).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.RecordAttempt(models.WebhookAttemptDTO{
+		DeliveryID:     7,
+		ResponseStatus: &status,
+		Error:          "500 Internal Server Error",
+		NextAttemptAt:  &next,
+	})
+	assert.Nil(t, err, "Error is not nil")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Not all expectations were met: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package search parses the post search syntax into a typed query that the
+// repository compiles into SQL.
+package search
+
+import "time"
+
+// Node is a single element of a search query.
+type Node interface {
+	node()
+}
+
+// Term is a word or an exact phrase to match in the post text.
+type Term struct {
+	Text    string
+	Phrase  bool
+	Negated bool
+}
+
+// From matches posts written by a user.
+type From struct {
+	UserName string
+	Negated  bool
+}
+
+// To matches replies to a user's posts.
+type To struct {
+	UserName string
+	Negated  bool
+}
+
+// Since matches posts created on or after a day.
+type Since struct {
+	Date time.Time
+}
+
+// Until matches posts created before a day.
+type Until struct {
+	Date time.Time
+}
+
+// Has matches posts with an attachment such as media or a poll.
+type Has struct {
+	Feature string
+	Negated bool
+}
+
+// Is matches posts of a kind, such as replies.
+type Is struct {
+	Kind    string
+	Negated bool
+}
+
+// MinLikes matches posts with at least Count likes.
+type MinLikes struct {
+	Count uint64
+}
+
+func (Term) node()     {}
+func (From) node()     {}
+func (To) node()       {}
+func (Since) node()    {}
+func (Until) node()    {}
+func (Has) node()      {}
+func (Is) node()       {}
+func (MinLikes) node() {}
+
+const (
+	FeatureMedia = "media"
+	FeaturePoll  = "poll"
+	KindReply    = "reply"
+)
+
+// Query is a parsed search string. Nodes keep the order they were written in.
+type Query struct {
+	Nodes []Node
+}
+
+// HasKind reports whether the query filters on the given is: kind, negated
+// or not.
+func (q *Query) HasKind(kind string) bool {
+	for _, node := range q.Nodes {
+		if is, ok := node.(Is); ok && is.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
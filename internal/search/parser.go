@@ -0,0 +1,189 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const dateLayout = "2006-01-02"
+
+var userNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Issue describes one problem in a search string. Offset is the byte offset
+// of the offending token.
+type Issue struct {
+	Offset  int    `json:"offset"`
+	Token   string `json:"token"`
+	Message string `json:"message"`
+}
+
+// ParseError lists every problem found in a search string.
+type ParseError struct {
+	Issues []Issue
+}
+
+func (e *ParseError) Error() string {
+	if len(e.Issues) == 0 {
+		return "invalid search query"
+	}
+	issue := e.Issues[0]
+	return fmt.Sprintf("invalid search query: %s at offset %d", issue.Message, issue.Offset)
+}
+
+type token struct {
+	text    string
+	offset  int
+	quoted  bool
+	negated bool
+}
+
+// Parse turns a search string into a query. Words and "exact phrases" may be
+// negated with a leading -, as may the from:, to:, has: and is: operators.
+// Unknown operators are searched for as plain words.
+func Parse(input string) (*Query, error) {
+	tokens, issues := tokenize(input)
+	query := &Query{}
+	var since *Since
+	var until *Until
+	for _, tok := range tokens {
+		node, issue := parseToken(tok)
+		if issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		switch n := node.(type) {
+		case Since:
+			since = &n
+		case Until:
+			until = &n
+		}
+		query.Nodes = append(query.Nodes, node)
+	}
+	if since != nil && until != nil && !since.Date.Before(until.Date) {
+		issues = append(issues, Issue{Offset: 0, Token: input, Message: "since must be before until"})
+	}
+	if len(issues) > 0 {
+		return nil, &ParseError{Issues: issues}
+	}
+	return query, nil
+}
+
+func tokenize(input string) ([]token, []Issue) {
+	var tokens []token
+	var issues []Issue
+	i := 0
+	for i < len(input) {
+		if isSpace(input[i]) {
+			i++
+			continue
+		}
+		start := i
+		negated := false
+		if input[i] == '-' {
+			negated = true
+			i++
+		}
+		if i < len(input) && input[i] == '"' {
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				issues = append(issues, Issue{Offset: start, Token: input[start:], Message: "unterminated quote"})
+				break
+			}
+			text := input[i+1 : i+1+end]
+			i += end + 2
+			if strings.TrimSpace(text) == "" {
+				issues = append(issues, Issue{Offset: start, Token: input[start:i], Message: "empty phrase"})
+				continue
+			}
+			tokens = append(tokens, token{text: text, offset: start, quoted: true, negated: negated})
+			continue
+		}
+		for i < len(input) && !isSpace(input[i]) {
+			i++
+		}
+		text := input[start:i]
+		if negated {
+			text = text[1:]
+		}
+		if text == "" {
+			issues = append(issues, Issue{Offset: start, Token: "-", Message: "nothing to exclude"})
+			continue
+		}
+		tokens = append(tokens, token{text: text, offset: start, negated: negated})
+	}
+	return tokens, issues
+}
+
+func parseToken(tok token) (Node, *Issue) {
+	if tok.quoted {
+		return Term{Text: tok.text, Phrase: true, Negated: tok.negated}, nil
+	}
+	key, value, ok := strings.Cut(tok.text, ":")
+	if !ok {
+		return Term{Text: tok.text, Negated: tok.negated}, nil
+	}
+	raw := tok.text
+	if tok.negated {
+		raw = "-" + raw
+	}
+	fail := func(format string, args ...interface{}) (Node, *Issue) {
+		return nil, &Issue{Offset: tok.offset, Token: raw, Message: fmt.Sprintf(format, args...)}
+	}
+	switch strings.ToLower(key) {
+	case "from", "to":
+		userName := strings.TrimPrefix(value, "@")
+		if !userNamePattern.MatchString(userName) {
+			return fail("%s: needs a user name", key)
+		}
+		if strings.ToLower(key) == "from" {
+			return From{UserName: userName, Negated: tok.negated}, nil
+		}
+		return To{UserName: userName, Negated: tok.negated}, nil
+	case "since", "until":
+		if tok.negated {
+			return fail("%s: cannot be negated", key)
+		}
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return fail("%s: needs a date like 2026-01-31", key)
+		}
+		if strings.ToLower(key) == "since" {
+			return Since{Date: date}, nil
+		}
+		return Until{Date: date}, nil
+	case "has":
+		switch strings.ToLower(value) {
+		case FeatureMedia, FeaturePoll:
+			return Has{Feature: strings.ToLower(value), Negated: tok.negated}, nil
+		}
+		return fail("has: supports media and poll")
+	case "is":
+		if strings.ToLower(value) == KindReply {
+			return Is{Kind: KindReply, Negated: tok.negated}, nil
+		}
+		return fail("is: supports reply")
+	case "min_likes":
+		if tok.negated {
+			return fail("min_likes: cannot be negated")
+		}
+		// 63 bits keep the count within the range of a bigint
+		count, err := strconv.ParseUint(value, 10, 63)
+		if errors.Is(err, strconv.ErrRange) {
+			return fail("min_likes: number is too large")
+		}
+		if err != nil {
+			return fail("min_likes: needs a non-negative number")
+		}
+		return MinLikes{Count: count}, nil
+	}
+	return Term{Text: tok.text, Negated: tok.negated}, nil
+}
+
+func isSpace(b byte) bool {
+	return unicode.IsSpace(rune(b))
+}
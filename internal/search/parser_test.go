@@ -0,0 +1,111 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		nodes  []Node
+		issues []Issue
+	}{
+		{
+			name:  "Words and phrases",
+			input: `gopher "exact  phrase" -rust -"bad news"`,
+			nodes: []Node{
+				Term{Text: "gopher"},
+				Term{Text: "exact  phrase", Phrase: true},
+				Term{Text: "rust", Negated: true},
+				Term{Text: "bad news", Phrase: true, Negated: true},
+			},
+		},
+		{
+			name:  "All operators",
+			input: "from:@alice -to:bob since:2026-01-01 until:2026-02-01 has:media -has:poll is:reply min_likes:10",
+			nodes: []Node{
+				From{UserName: "alice"},
+				To{UserName: "bob", Negated: true},
+				Since{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Until{Date: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+				Has{Feature: FeatureMedia},
+				Has{Feature: FeaturePoll, Negated: true},
+				Is{Kind: KindReply},
+				MinLikes{Count: 10},
+			},
+		},
+		{
+			name:  "Unknown operator is a word",
+			input: "https://go.dev FROM:Alice",
+			nodes: []Node{
+				Term{Text: "https://go.dev"},
+				From{UserName: "Alice"},
+			},
+		},
+		{
+			name:  "Empty input",
+			input: "   ",
+		},
+		{
+			name:  "Malformed operators",
+			input: "from: since:yesterday has:video is:quote min_likes:-1 -until:2026-01-01",
+			issues: []Issue{
+				{Offset: 0, Token: "from:", Message: "from: needs a user name"},
+				{Offset: 6, Token: "since:yesterday", Message: "since: needs a date like 2026-01-31"},
+				{Offset: 22, Token: "has:video", Message: "has: supports media and poll"},
+				{Offset: 32, Token: "is:quote", Message: "is: supports reply"},
+				{Offset: 41, Token: "min_likes:-1", Message: "min_likes: needs a non-negative number"},
+				{Offset: 54, Token: "-until:2026-01-01", Message: "until: cannot be negated"},
+			},
+		},
+		{
+			name:  "Like count out of range",
+			input: "min_likes:9223372036854775807 min_likes:9223372036854775808",
+			nodes: []Node{MinLikes{Count: 9223372036854775807}},
+			issues: []Issue{
+				{Offset: 30, Token: "min_likes:9223372036854775808", Message: "min_likes: number is too large"},
+			},
+		},
+		{
+			name:  "Unterminated quote and lone minus",
+			input: `go - "open`,
+			issues: []Issue{
+				{Offset: 3, Token: "-", Message: "nothing to exclude"},
+				{Offset: 5, Token: `"open`, Message: "unterminated quote"},
+			},
+		},
+		{
+			name:  "Empty date range",
+			input: "since:2026-02-01 until:2026-02-01",
+			issues: []Issue{
+				{Offset: 0, Token: "since:2026-02-01 until:2026-02-01", Message: "since must be before until"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := Parse(tc.input)
+			if tc.issues != nil {
+				var parseErr *ParseError
+				assert.ErrorAs(t, err, &parseErr)
+				assert.Equal(t, tc.issues, parseErr.Issues, "Issues mismatch")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.nodes, query.Nodes, "Nodes mismatch")
+		})
+	}
+}
+
+func TestQuery_HasKind(t *testing.T) {
+	query, err := Parse("-is:reply gopher")
+	assert.NoError(t, err)
+	assert.True(t, query.HasKind(KindReply))
+	query, err = Parse("gopher")
+	assert.NoError(t, err)
+	assert.False(t, query.HasKind(KindReply))
+}
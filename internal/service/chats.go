@@ -0,0 +1,146 @@
+package service
+
+import (
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+)
+
+type ChatServiceImpl struct {
+	repo repository.ChatRepository
+	cfg  *config.Config
+}
+
+func NewChatServiceImpl(repo repository.ChatRepository, cfg *config.Config) *ChatServiceImpl {
+	return &ChatServiceImpl{repo: repo, cfg: cfg}
+}
+
+// CreateDirectChat returns the chat between the two users and tells whether
+// it was created.
+func (s *ChatServiceImpl) CreateDirectChat(userID, recipientID uint64) (*models.ReadChatDTO, bool, error) {
+	if userID == recipientID {
+		return nil, false, ErrMessageSelf
+	}
+	id, created, err := s.repo.CreateDirectChat(userID, recipientID)
+	if err != nil {
+		return nil, false, err
+	}
+	chat, err := s.repo.GetChatByID(id, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return chat, created, nil
+}
+
+// CreateGroupChat starts a group with the creator and the users, who must
+// fit within ChatMaxMembers together.
+func (s *ChatServiceImpl) CreateGroupChat(dto models.CreateGroupChatDTO) (*models.ReadChatDTO, error) {
+	userIDs := make([]uint64, 0, len(dto.UserIDs))
+	for _, userID := range dto.UserIDs {
+		if userID != dto.CreatorID {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, ErrMessageSelf
+	}
+	if len(userIDs)+1 > s.cfg.ChatMaxMembers {
+		return nil, repository.ErrChatFull
+	}
+	dto.UserIDs = userIDs
+	id, err := s.repo.CreateGroupChat(dto)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetChatByID(id, dto.CreatorID)
+}
+
+func (s *ChatServiceImpl) UpdateChat(id, userID uint64, dto models.UpdateChatDTO) (*models.ReadChatDTO, error) {
+	if err := s.repo.UpdateChat(id, userID, dto); err != nil {
+		return nil, err
+	}
+	return s.repo.GetChatByID(id, userID)
+}
+
+func (s *ChatServiceImpl) AddChatMember(id, userID, memberID uint64) error {
+	return s.repo.AddChatMember(id, userID, memberID)
+}
+
+func (s *ChatServiceImpl) UpdateChatMember(id, userID, memberID uint64, dto models.UpdateChatMemberDTO) error {
+	return s.repo.UpdateChatMember(id, userID, memberID, dto.Role)
+}
+
+func (s *ChatServiceImpl) RemoveChatMember(id, userID, memberID uint64) error {
+	return s.repo.RemoveChatMember(id, userID, memberID)
+}
+
+func (s *ChatServiceImpl) LeaveChat(id, userID uint64) error {
+	return s.repo.RemoveChatMember(id, userID, userID)
+}
+
+func (s *ChatServiceImpl) GetChatsPage(userID uint64, cursor *models.Cursor, limit uint64) (*models.ChatPageDTO, error) {
+	chats, err := s.repo.GetChats(userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*models.Cursor, len(chats))
+	for i, chat := range chats {
+		keys[i] = &models.Cursor{CreatedAt: chat.LastMessageAt, ID: chat.ID}
+	}
+	from, to, next, prev := paginate(cursor, keys, limit)
+	return &models.ChatPageDTO{Items: chats[from:to], NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (s *ChatServiceImpl) GetChatByID(id, userID uint64) (*models.ReadChatDTO, error) {
+	return s.repo.GetChatByID(id, userID)
+}
+
+func (s *ChatServiceImpl) CountUnread(userID uint64) (uint64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+func (s *ChatServiceImpl) MarkRead(id, userID, messageID uint64) error {
+	return s.repo.MarkRead(id, userID, messageID)
+}
+
+func (s *ChatServiceImpl) MuteChat(id, userID uint64) error {
+	return s.repo.MuteChat(id, userID)
+}
+
+func (s *ChatServiceImpl) UnmuteChat(id, userID uint64) error {
+	return s.repo.UnmuteChat(id, userID)
+}
+
+// GetMessagesPage answers with ErrNotFound when the user is not a member of
+// the chat.
+func (s *ChatServiceImpl) GetMessagesPage(
+	chatID, userID uint64,
+	cursor *models.Cursor,
+	limit uint64,
+) (*models.MessagePageDTO, error) {
+	if _, err := s.repo.GetChatByID(chatID, userID); err != nil {
+		return nil, err
+	}
+	messages, err := s.repo.GetMessages(chatID, userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*models.Cursor, len(messages))
+	for i, message := range messages {
+		keys[i] = &models.Cursor{CreatedAt: message.CreatedAt, ID: message.ID}
+	}
+	from, to, next, prev := paginate(cursor, keys, limit)
+	return &models.MessagePageDTO{Items: messages[from:to], NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (s *ChatServiceImpl) SendMessage(dto models.CreateMessageDTO) (*models.ReadMessageDTO, error) {
+	return s.repo.CreateMessage(dto)
+}
+
+func (s *ChatServiceImpl) UnsendMessage(id, chatID, senderID uint64) error {
+	return s.repo.UnsendMessage(id, chatID, senderID)
+}
+
+func (s *ChatServiceImpl) DeleteMessage(id, chatID, userID uint64) error {
+	return s.repo.DeleteMessage(id, chatID, userID)
+}
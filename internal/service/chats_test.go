@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatServiceImpl_CreateDirectChat(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockChatRepository(ctrl)
+	s := NewChatServiceImpl(m, &cfg)
+
+	_, _, err := s.CreateDirectChat(1, 1)
+	assert.Equal(t, ErrMessageSelf, err, "Users should not message themselves")
+
+	m.EXPECT().CreateDirectChat(uint64(1), uint64(2)).Return(uint64(5), true, nil)
+	m.EXPECT().GetChatByID(uint64(5), uint64(1)).Return(&models.ReadChatDTO{ID: 5}, nil)
+	chat, created, err := s.CreateDirectChat(1, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.True(t, created, "Chat should be created")
+	assert.Equal(t, uint64(5), chat.ID, "ID mismatch")
+
+	m.EXPECT().CreateDirectChat(uint64(1), uint64(3)).Return(uint64(0), false, repository.ErrBlocked)
+	_, _, err = s.CreateDirectChat(1, 3)
+	assert.Equal(t, repository.ErrBlocked, err, "Error mismatch")
+}
+
+func TestChatServiceImpl_GetMessagesPage(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockChatRepository(ctrl)
+	s := NewChatServiceImpl(m, &cfg)
+
+	now := time.Now()
+	messages := []models.ReadMessageDTO{
+		{ID: 3, ChatID: 5, CreatedAt: now},
+		{ID: 2, ChatID: 5, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, ChatID: 5, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	m.EXPECT().GetChatByID(uint64(5), uint64(1)).Return(&models.ReadChatDTO{ID: 5}, nil)
+	m.EXPECT().GetMessages(uint64(5), uint64(1), nil, uint64(3)).Return(messages, nil)
+	page, err := s.GetMessagesPage(5, 1, nil, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, page.Items, 2, "Page size mismatch")
+	assert.NotEmpty(t, page.NextCursor, "Next cursor is missing")
+
+	m.EXPECT().GetChatByID(uint64(5), uint64(3)).Return(nil, repository.ErrNotFound)
+	_, err = s.GetMessagesPage(5, 3, nil, 2)
+	assert.Equal(t, repository.ErrNotFound, err, "Non-members should not read messages")
+}
+
+func TestChatServiceImpl_CreateGroupChat(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.ChatMaxMembers = 3
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockChatRepository(ctrl)
+	s := NewChatServiceImpl(m, &cfg)
+
+	m.EXPECT().CreateGroupChat(models.CreateGroupChatDTO{CreatorID: 1, Name: "Gophers", UserIDs: []uint64{2, 3}}).
+		Return(uint64(5), nil)
+	m.EXPECT().GetChatByID(uint64(5), uint64(1)).Return(&models.ReadChatDTO{ID: 5, IsGroup: true}, nil)
+	chat, err := s.CreateGroupChat(models.CreateGroupChatDTO{CreatorID: 1, Name: "Gophers", UserIDs: []uint64{1, 2, 3}})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, uint64(5), chat.ID, "ID mismatch")
+
+	_, err = s.CreateGroupChat(models.CreateGroupChatDTO{CreatorID: 1, Name: "Gophers", UserIDs: []uint64{1}})
+	assert.Equal(t, ErrMessageSelf, err, "Groups need someone besides the creator")
+
+	_, err = s.CreateGroupChat(models.CreateGroupChatDTO{CreatorID: 1, Name: "Gophers", UserIDs: []uint64{2, 3, 4}})
+	assert.Equal(t, repository.ErrChatFull, err, "Member limit should include the creator")
+}
+
+func TestChatServiceImpl_LeaveChat(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockChatRepository(ctrl)
+	s := NewChatServiceImpl(m, &cfg)
+
+	m.EXPECT().RemoveChatMember(uint64(5), uint64(1), uint64(1)).Return(nil)
+	assert.Nil(t, s.LeaveChat(5, 1), "Error is not nil")
+}
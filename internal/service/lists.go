@@ -0,0 +1,61 @@
+package service
+
+import (
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+)
+
+type ListServiceImpl struct {
+	repo repository.ListRepository
+	cfg  *config.Config
+}
+
+func NewListServiceImpl(repo repository.ListRepository, cfg *config.Config) *ListServiceImpl {
+	return &ListServiceImpl{repo: repo, cfg: cfg}
+}
+
+func (s *ListServiceImpl) CreateList(dto models.CreateListDTO) (*models.ReadListDTO, error) {
+	return s.repo.CreateList(dto)
+}
+
+func (s *ListServiceImpl) GetListByID(id, viewerID uint64) (*models.ReadListDTO, error) {
+	return s.repo.GetListByID(id, viewerID)
+}
+
+func (s *ListServiceImpl) GetLists(dto models.FilterListDTO) ([]models.ReadListDTO, error) {
+	return s.repo.GetLists(dto)
+}
+
+func (s *ListServiceImpl) UpdateList(id, ownerID uint64, dto models.UpdateListDTO) (*models.ReadListDTO, error) {
+	return s.repo.UpdateList(id, ownerID, dto)
+}
+
+func (s *ListServiceImpl) DeleteList(id, ownerID uint64) error {
+	return s.repo.DeleteList(id, ownerID)
+}
+
+func (s *ListServiceImpl) AddListMember(id, ownerID, userID uint64) error {
+	return s.repo.AddListMember(id, ownerID, userID)
+}
+
+func (s *ListServiceImpl) RemoveListMember(id, ownerID, userID uint64) error {
+	return s.repo.RemoveListMember(id, ownerID, userID)
+}
+
+// GetListMembers answers with ErrNotFound rather than an empty page when the
+// viewer may not see the list.
+func (s *ListServiceImpl) GetListMembers(id, viewerID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	if _, err := s.repo.GetListByID(id, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetListMembers(id, viewerID, limit, offset)
+}
+
+func (s *ListServiceImpl) SubscribeList(id, userID uint64) error {
+	return s.repo.SubscribeList(id, userID)
+}
+
+func (s *ListServiceImpl) UnsubscribeList(id, userID uint64) error {
+	return s.repo.UnsubscribeList(id, userID)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListServiceImpl_GetListMembers(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockListRepository(ctrl)
+	s := &ListServiceImpl{cfg: &cfg, repo: m}
+
+	members := []models.ReadUserDTO{{ID: 2, UserName: "jane"}}
+	m.EXPECT().GetListByID(uint64(5), uint64(1)).Return(&models.ReadListDTO{ID: 5}, nil)
+	m.EXPECT().GetListMembers(uint64(5), uint64(1), uint64(10), uint64(0)).Return(members, nil)
+	users, err := s.GetListMembers(5, 1, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, members, users, "Members mismatch")
+
+	m.EXPECT().GetListByID(uint64(6), uint64(1)).Return(nil, repository.ErrNotFound)
+	_, err = s.GetListMembers(6, 1, 10, 0)
+	assert.Equal(t, repository.ErrNotFound, err, "Private list members should not be listed")
+}
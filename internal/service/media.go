@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/imaging"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/storage"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/shekshuev/gophertalk-backend/internal/worker"
+)
+
+const thumbnailSize = 150
+
+var mediaSizes = []struct {
+	name string
+	size int
+}{
+	{"small", 320},
+	{"medium", 640},
+	{"large", 1280},
+}
+
+type MediaServiceImpl struct {
+	repo  repository.MediaRepository
+	store storage.BlobStore
+	pool  *worker.Pool
+	cfg   *config.Config
+}
+
+func NewMediaServiceImpl(repo repository.MediaRepository, store storage.BlobStore, pool *worker.Pool, cfg *config.Config) *MediaServiceImpl {
+	return &MediaServiceImpl{repo: repo, store: store, pool: pool, cfg: cfg}
+}
+
+func (s *MediaServiceImpl) UploadMedia(dto models.UploadMediaDTO) (*models.ReadMediaDTO, error) {
+	if int64(len(dto.Data)) > s.cfg.MediaMaxSize {
+		return nil, ErrMediaTooLarge
+	}
+	mimeType, err := utils.DetectImageType(dto.Data)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+	data, err := utils.StripImageMetadata(dto.Data, mimeType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+	width, height, err := utils.ImageSize(data, mimeType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+	// decoding takes memory in proportion to the declared size, not the file size
+	if int64(width)*int64(height) > s.cfg.MediaMaxPixels {
+		return nil, ErrImageTooLarge
+	}
+	key := "media/" + uuid.New().String() + utils.ImageExtension(mimeType)
+	err = s.store.Put(context.Background(), key, bytes.NewReader(data), int64(len(data)), mimeType)
+	if err != nil {
+		return nil, err
+	}
+	media, err := s.repo.CreateMedia(models.CreateMediaDTO{
+		UserID:     dto.UserID,
+		StorageKey: key,
+		URL:        s.store.URL(key),
+		MimeType:   mimeType,
+		SizeBytes:  int64(len(data)),
+		Width:      width,
+		Height:     height,
+		AltText:    dto.AltText,
+	})
+	if err != nil {
+		s.store.Delete(context.Background(), key)
+		return nil, err
+	}
+	if s.pool != nil {
+		err = s.pool.Submit(func() {
+			if err := s.processMedia(media.ID, key, data); err != nil {
+				log.Print("Error processing media ", media.ID, ": ", err)
+			}
+		})
+		if err != nil {
+			log.Print("Media ", media.ID, " left without variants: ", err)
+		}
+	}
+	return media, nil
+}
+
+// processMedia generates the thumbnail and responsive variants of an uploaded
+// image together with its blurhash placeholder. Every variant is stored in the
+// format of the original (PNG when it has transparency, JPEG otherwise) and
+// as WebP. Variants are never larger than the original.
+func (s *MediaServiceImpl) processMedia(id uint64, key string, data []byte) error {
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return err
+	}
+	format := imaging.FormatJPEG
+	if imaging.HasAlpha(img) {
+		format = imaging.FormatPNG
+	}
+	variants := make([]models.ReadMediaVariantDTO, 0)
+	store := func(name string, variant image.Image) error {
+		for _, f := range []string{format, imaging.FormatWebP} {
+			encoded, err := imaging.Encode(variant, f)
+			if err != nil {
+				return err
+			}
+			variantKey := fmt.Sprintf("%s_%s%s", strings.TrimSuffix(key, path.Ext(key)), name, utils.ImageExtension(f))
+			err = s.store.Put(context.Background(), variantKey, bytes.NewReader(encoded), int64(len(encoded)), f)
+			if err != nil {
+				return err
+			}
+			bounds := variant.Bounds()
+			variants = append(variants, models.ReadMediaVariantDTO{
+				Name:     name,
+				URL:      s.store.URL(variantKey),
+				MimeType: f,
+				Width:    bounds.Dx(),
+				Height:   bounds.Dy(),
+			})
+		}
+		return nil
+	}
+	if err := store("thumb", imaging.Thumbnail(img, thumbnailSize)); err != nil {
+		return err
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	for _, size := range mediaSizes {
+		if width <= size.size && height <= size.size {
+			continue
+		}
+		w, h := imaging.Fit(width, height, size.size, size.size)
+		if err := store(size.name, imaging.Resize(img, w, h)); err != nil {
+			return err
+		}
+	}
+	return s.repo.UpdateMediaVariants(id, imaging.Blurhash(img, 4, 3), variants)
+}
+
+func (s *MediaServiceImpl) GetMediaByID(id, userID uint64) (*models.ReadMediaDTO, error) {
+	return s.repo.GetMediaByID(id, userID)
+}
+
+func (s *MediaServiceImpl) UpdateMedia(id, ownerID uint64, dto models.UpdateMediaDTO) (*models.ReadMediaDTO, error) {
+	return s.repo.UpdateMedia(id, ownerID, dto)
+}
+
+func (s *MediaServiceImpl) OpenMediaFile(key string) (io.ReadCloser, error) {
+	return s.store.Get(context.Background(), key)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/imaging"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	assert.NoError(t, err, "Error encoding PNG")
+	return buf.Bytes()
+}
+
+func TestMediaServiceImpl_UploadMedia(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        []byte
+		storeCalled bool
+		repoCalled  bool
+		repoError   error
+		err         error
+	}{
+		{
+			name:        "Success upload",
+			data:        testPNG(t, 64, 32),
+			storeCalled: true,
+			repoCalled:  true,
+			err:         nil,
+		},
+		{
+			name: "File too large",
+			data: make([]byte, 2048),
+			err:  ErrMediaTooLarge,
+		},
+		{
+			name: "Not an image",
+			data: []byte("<html><body>hello</body></html>"),
+			err:  ErrUnsupportedMediaType,
+		},
+		{
+			name: "Too many pixels",
+			data: testPNG(t, 64, 33),
+			err:  ErrImageTooLarge,
+		},
+		{
+			name:        "Error on insert SQL removes blob",
+			data:        testPNG(t, 64, 32),
+			storeCalled: true,
+			repoCalled:  true,
+			repoError:   sql.ErrConnDone,
+			err:         sql.ErrConnDone,
+		},
+	}
+	cfg := config.GetConfig()
+	cfg.MediaMaxSize = 1024
+	cfg.MediaMaxPixels = 64 * 32
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockMediaRepository(ctrl)
+	store := mocks.NewMockBlobStore(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, repo: repo, store: store}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			readDTO := &models.ReadMediaDTO{ID: 1, URL: "/media/1.png", MimeType: "image/png", Width: 64, Height: 32}
+			if tc.storeCalled {
+				store.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(len(tc.data)), "image/png").Return(nil)
+				store.EXPECT().URL(gomock.Any()).Return("/media/1.png")
+			}
+			if tc.repoCalled {
+				repo.EXPECT().CreateMedia(gomock.Any()).DoAndReturn(func(dto models.CreateMediaDTO) (*models.ReadMediaDTO, error) {
+					assert.Equal(t, 64, dto.Width, "Width mismatch")
+					assert.Equal(t, 32, dto.Height, "Height mismatch")
+					assert.Equal(t, "image/png", dto.MimeType, "MIME type mismatch")
+					assert.Regexp(t, `^media/[0-9a-f-]+\.png$`, dto.StorageKey, "Storage key mismatch")
+					if tc.repoError != nil {
+						return nil, tc.repoError
+					}
+					return readDTO, nil
+				})
+			}
+			if tc.repoError != nil {
+				store.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+			}
+			media, err := s.UploadMedia(models.UploadMediaDTO{UserID: 1, Data: tc.data})
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			if tc.err == nil {
+				assert.Equal(t, readDTO, media, "Media mismatch")
+			}
+		})
+	}
+}
+
+func TestMediaServiceImpl_ProcessMedia(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockMediaRepository(ctrl)
+	store := mocks.NewMockBlobStore(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, repo: repo, store: store}
+
+	keys := make([]string, 0)
+	store.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key string, r io.Reader, size int64, contentType string) error {
+			data, err := io.ReadAll(r)
+			assert.Nil(t, err, "Error is not nil")
+			assert.Equal(t, size, int64(len(data)), "Size mismatch")
+			keys = append(keys, key+" "+contentType)
+			return nil
+		}).Times(6)
+	store.EXPECT().URL(gomock.Any()).DoAndReturn(func(key string) string { return "/files/" + key }).Times(6)
+	repo.EXPECT().UpdateMediaVariants(uint64(1), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(id uint64, blurhash string, variants []models.ReadMediaVariantDTO) error {
+			assert.Len(t, blurhash, 28, "Blurhash length mismatch")
+			assert.Equal(t, []models.ReadMediaVariantDTO{
+				{Name: "thumb", URL: "/files/media/1_thumb.jpg", MimeType: "image/jpeg", Width: 150, Height: 150},
+				{Name: "thumb", URL: "/files/media/1_thumb.webp", MimeType: "image/webp", Width: 150, Height: 150},
+				{Name: "small", URL: "/files/media/1_small.jpg", MimeType: "image/jpeg", Width: 320, Height: 160},
+				{Name: "small", URL: "/files/media/1_small.webp", MimeType: "image/webp", Width: 320, Height: 160},
+				{Name: "medium", URL: "/files/media/1_medium.jpg", MimeType: "image/jpeg", Width: 640, Height: 320},
+				{Name: "medium", URL: "/files/media/1_medium.webp", MimeType: "image/webp", Width: 640, Height: 320},
+			}, variants, "Variants mismatch")
+			return nil
+		})
+
+	var buf bytes.Buffer
+	img := image.NewNRGBA(image.Rect(0, 0, 800, 400))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	assert.NoError(t, png.Encode(&buf, img), "Error encoding PNG")
+	err := s.processMedia(1, "media/1.png", buf.Bytes())
+	assert.Nil(t, err, "Error is not nil")
+	assert.Contains(t, keys, "media/1_thumb.jpg image/jpeg", "Thumbnail was not stored")
+	assert.NotContains(t, keys, "media/1_large.jpg image/jpeg", "Variant larger than the original was stored")
+}
+
+func TestMediaServiceImpl_ProcessMediaTransparent(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockMediaRepository(ctrl)
+	store := mocks.NewMockBlobStore(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, repo: repo, store: store}
+
+	store.EXPECT().Put(gomock.Any(), "media/2_thumb.png", gomock.Any(), gomock.Any(), "image/png").Return(nil)
+	store.EXPECT().Put(gomock.Any(), "media/2_thumb.webp", gomock.Any(), gomock.Any(), "image/webp").Return(nil)
+	store.EXPECT().URL(gomock.Any()).Return("/files/thumb").Times(2)
+	repo.EXPECT().UpdateMediaVariants(uint64(2), gomock.Any(), gomock.Any()).Return(nil)
+
+	err := s.processMedia(2, "media/2.png", testPNG(t, 64, 32))
+	assert.Nil(t, err, "Error is not nil")
+}
+
+func TestMediaServiceImpl_ProcessMediaWebP(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockMediaRepository(ctrl)
+	store := mocks.NewMockBlobStore(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, repo: repo, store: store}
+
+	store.EXPECT().Put(gomock.Any(), "media/3_thumb.png", gomock.Any(), gomock.Any(), "image/png").Return(nil)
+	store.EXPECT().Put(gomock.Any(), "media/3_thumb.webp", gomock.Any(), gomock.Any(), "image/webp").Return(nil)
+	store.EXPECT().URL(gomock.Any()).Return("/files/thumb").Times(2)
+	repo.EXPECT().UpdateMediaVariants(uint64(3), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(id uint64, blurhash string, variants []models.ReadMediaVariantDTO) error {
+			assert.NotEmpty(t, blurhash, "Blurhash is empty")
+			return nil
+		})
+
+	data, err := imaging.Encode(image.NewRGBA(image.Rect(0, 0, 64, 32)), imaging.FormatWebP)
+	assert.NoError(t, err, "Error encoding WebP")
+	err = s.processMedia(3, "media/3.webp", data)
+	assert.Nil(t, err, "Error is not nil")
+}
+
+func TestMediaServiceImpl_GetMediaByID(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockMediaRepository(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, repo: repo}
+	readDTO := &models.ReadMediaDTO{ID: 1, URL: "/media/1.png"}
+	repo.EXPECT().GetMediaByID(uint64(1), uint64(2)).Return(readDTO, nil)
+	media, err := s.GetMediaByID(1, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, readDTO, media, "Media mismatch")
+}
+
+func TestMediaServiceImpl_UpdateMedia(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mocks.NewMockMediaRepository(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, repo: repo}
+	updateDTO := models.UpdateMediaDTO{AltText: "A gopher"}
+	readDTO := &models.ReadMediaDTO{ID: 1, URL: "/media/1.png", AltText: "A gopher"}
+	repo.EXPECT().UpdateMedia(uint64(1), uint64(2), updateDTO).Return(readDTO, nil)
+	media, err := s.UpdateMedia(1, 2, updateDTO)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, readDTO, media, "Media mismatch")
+}
+
+func TestMediaServiceImpl_OpenMediaFile(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mocks.NewMockBlobStore(ctrl)
+	s := &MediaServiceImpl{cfg: &cfg, store: store}
+	store.EXPECT().Get(gomock.Any(), "media/1.png").Return(io.NopCloser(bytes.NewReader([]byte("data"))), nil)
+	file, err := s.OpenMediaFile("media/1.png")
+	assert.Nil(t, err, "Error is not nil")
+	data, _ := io.ReadAll(file)
+	assert.Equal(t, "data", string(data), "File content mismatch")
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"unicode"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+const (
+	minMentionLength = 5
+	maxMentionLength = 30
+)
+
+// parseMentions finds @user_name tokens in text. Offsets and lengths are
+// counted in runes and include the leading @.
+func parseMentions(text string) []models.CreateMentionDTO {
+	runes := []rune(text)
+	var mentions []models.CreateMentionDTO
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isUserNameRune(runes[i-1])) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isUserNameRune(runes[j]) {
+			j++
+		}
+		name := runes[i+1 : j]
+		if len(name) >= minMentionLength && len(name) <= maxMentionLength && !unicode.IsDigit(name[0]) {
+			mentions = append(mentions, models.CreateMentionDTO{
+				UserName: string(name),
+				Offset:   i,
+				Length:   j - i,
+			})
+		}
+		i = j - 1
+	}
+	return mentions
+}
+
+func isUserNameRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		mentions []models.CreateMentionDTO
+	}{
+		{
+			name:     "No mentions",
+			text:     "Lorem ipsum dolor sit amet",
+			mentions: nil,
+		},
+		{
+			name: "Single mention",
+			text: "Hello @johndoe!",
+			mentions: []models.CreateMentionDTO{
+				{UserName: "johndoe", Offset: 6, Length: 8},
+			},
+		},
+		{
+			name: "Offsets are counted in runes",
+			text: "Привет, @johndoe и @jane_doe",
+			mentions: []models.CreateMentionDTO{
+				{UserName: "johndoe", Offset: 8, Length: 8},
+				{UserName: "jane_doe", Offset: 19, Length: 9},
+			},
+		},
+		{
+			name:     "Email address is not a mention",
+			text:     "Write to john@example.com",
+			mentions: nil,
+		},
+		{
+			name:     "Too short or starts with digit",
+			text:     "@john @1johndoe",
+			mentions: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.mentions, parseMentions(tc.text), "Mentions mismatch")
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+)
+
+type NotificationServiceImpl struct {
+	repo repository.NotificationRepository
+	cfg  *config.Config
+}
+
+func NewNotificationServiceImpl(repo repository.NotificationRepository, cfg *config.Config) *NotificationServiceImpl {
+	return &NotificationServiceImpl{repo: repo, cfg: cfg}
+}
+
+// GetNotificationsPage pages through notifications by the time they last
+// gained an actor, so a group that keeps growing moves back to the top.
+func (s *NotificationServiceImpl) GetNotificationsPage(
+	userID uint64,
+	cursor *models.Cursor,
+	limit uint64,
+) (*models.NotificationPageDTO, error) {
+	notifications, err := s.repo.GetNotifications(userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*models.Cursor, len(notifications))
+	for i, notification := range notifications {
+		keys[i] = &models.Cursor{CreatedAt: notification.UpdatedAt, ID: notification.ID}
+	}
+	from, to, next, prev := paginate(cursor, keys, limit)
+	return &models.NotificationPageDTO{Items: notifications[from:to], NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (s *NotificationServiceImpl) CountUnread(userID uint64) (uint64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+func (s *NotificationServiceImpl) MarkRead(id, userID uint64) error {
+	return s.repo.MarkRead(id, userID)
+}
+
+func (s *NotificationServiceImpl) MarkAllRead(userID uint64) error {
+	return s.repo.MarkAllRead(userID)
+}
+
+func (s *NotificationServiceImpl) GetPreferences(userID uint64) (models.NotificationPreferencesDTO, error) {
+	return s.repo.GetPreferences(userID)
+}
+
+// UpdatePreferences changes the given types and returns the full set.
+func (s *NotificationServiceImpl) UpdatePreferences(
+	userID uint64,
+	dto models.NotificationPreferencesDTO,
+) (models.NotificationPreferencesDTO, error) {
+	for notificationType := range dto {
+		if !isNotificationType(notificationType) {
+			return nil, ErrInvalidNotificationType
+		}
+	}
+	if err := s.repo.UpdatePreferences(userID, dto); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPreferences(userID)
+}
+
+func isNotificationType(notificationType string) bool {
+	for _, known := range models.NotificationTypes {
+		if known == notificationType {
+			return true
+		}
+	}
+	return false
+}
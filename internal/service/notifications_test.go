@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationServiceImpl_GetNotificationsPage(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockNotificationRepository(ctrl)
+	s := &NotificationServiceImpl{cfg: &cfg, repo: m}
+
+	now := time.Now()
+	notifications := []models.ReadNotificationDTO{
+		{ID: 3, Type: models.NotificationTypeLike, UpdatedAt: now},
+		{ID: 2, Type: models.NotificationTypeFollow, UpdatedAt: now.Add(-time.Minute)},
+		{ID: 1, Type: models.NotificationTypeReply, UpdatedAt: now.Add(-time.Hour)},
+	}
+	m.EXPECT().GetNotifications(uint64(1), nil, uint64(3)).Return(notifications, nil)
+	page, err := s.GetNotificationsPage(1, nil, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Len(t, page.Items, 2, "Page size mismatch")
+	assert.NotEmpty(t, page.NextCursor, "Next cursor is missing")
+	assert.Empty(t, page.PrevCursor, "First page has no previous page")
+}
+
+func TestNotificationServiceImpl_UpdatePreferences(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockNotificationRepository(ctrl)
+	s := &NotificationServiceImpl{cfg: &cfg, repo: m}
+
+	update := models.NotificationPreferencesDTO{models.NotificationTypeLike: false}
+	stored := models.NotificationPreferencesDTO{
+		models.NotificationTypeLike:          false,
+		models.NotificationTypeReply:         true,
+		models.NotificationTypeMention:       true,
+		models.NotificationTypeFollow:        true,
+		models.NotificationTypeFollowRequest: true,
+	}
+	m.EXPECT().UpdatePreferences(uint64(1), update).Return(nil)
+	m.EXPECT().GetPreferences(uint64(1)).Return(stored, nil)
+	preferences, err := s.UpdatePreferences(1, update)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, stored, preferences, "Preferences mismatch")
+
+	_, err = s.UpdatePreferences(1, models.NotificationPreferencesDTO{"quote": false})
+	assert.Equal(t, ErrInvalidNotificationType, err, "Unknown types should be rejected")
+}
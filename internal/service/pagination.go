@@ -0,0 +1,50 @@
+package service
+
+import (
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+)
+
+// paginate trims a page that was fetched with one extra row and returns the
+// bounds of the items to keep along with the cursors around them. keys hold
+// the position of every fetched item in display order; a nil key marks an
+// item that cannot anchor a cursor, such as a pinned post.
+func paginate(cursor *models.Cursor, keys []*models.Cursor, limit uint64) (from, to int, next, prev string) {
+	backward := cursor != nil && cursor.Backward
+	from, to = 0, len(keys)
+	hasMore := uint64(len(keys)) > limit
+	if hasMore {
+		// the extra row sits on the far side of the direction we walked
+		if backward {
+			from = len(keys) - int(limit)
+		} else {
+			to = int(limit)
+		}
+	}
+	var first, last *models.Cursor
+	for _, key := range keys[from:to] {
+		if key == nil {
+			continue
+		}
+		if first == nil {
+			first = key
+		}
+		last = key
+	}
+	if hasMore || backward {
+		// an empty cursor continues right after the pinned posts
+		var c models.Cursor
+		if last != nil {
+			c = models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+		next = utils.EncodeCursor(c)
+	}
+	if cursor != nil && (hasMore || !backward) {
+		if first != nil {
+			prev = utils.EncodeCursor(models.Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+		} else if cursor.ID > 0 {
+			prev = utils.EncodeCursor(models.Cursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID, Backward: true})
+		}
+	}
+	return from, to, next, prev
+}
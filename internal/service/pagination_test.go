@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := func(id uint64) *models.Cursor {
+		return &models.Cursor{CreatedAt: now.Add(-time.Duration(id) * time.Minute), ID: id}
+	}
+	encode := func(c *models.Cursor, backward bool) string {
+		return utils.EncodeCursor(models.Cursor{CreatedAt: c.CreatedAt, ID: c.ID, Backward: backward})
+	}
+	testCases := []struct {
+		name     string
+		cursor   *models.Cursor
+		keys     []*models.Cursor
+		limit    uint64
+		from, to int
+		next     string
+		prev     string
+	}{
+		{
+			name:  "First page with more items",
+			keys:  []*models.Cursor{key(1), key(2), key(3)},
+			limit: 2,
+			from:  0,
+			to:    2,
+			next:  encode(key(2), false),
+		},
+		{
+			name:  "Single page",
+			keys:  []*models.Cursor{key(1), key(2)},
+			limit: 2,
+			from:  0,
+			to:    2,
+		},
+		{
+			name:   "Last page going forward",
+			cursor: key(2),
+			keys:   []*models.Cursor{key(3)},
+			limit:  2,
+			from:   0,
+			to:     1,
+			prev:   encode(key(3), true),
+		},
+		{
+			name:   "Backward page with more items",
+			cursor: &models.Cursor{CreatedAt: key(5).CreatedAt, ID: 5, Backward: true},
+			keys:   []*models.Cursor{key(2), key(3), key(4)},
+			limit:  2,
+			from:   1,
+			to:     3,
+			next:   encode(key(4), false),
+			prev:   encode(key(3), true),
+		},
+		{
+			name:   "Backward page reaching the top",
+			cursor: &models.Cursor{CreatedAt: key(3).CreatedAt, ID: 3, Backward: true},
+			keys:   []*models.Cursor{key(1), key(2)},
+			limit:  2,
+			from:   0,
+			to:     2,
+			next:   encode(key(2), false),
+		},
+		{
+			name:  "Only pinned items on the first page",
+			keys:  []*models.Cursor{nil, nil, key(7)},
+			limit: 2,
+			from:  0,
+			to:    2,
+			next:  utils.EncodeCursor(models.Cursor{}),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to, next, prev := paginate(tc.cursor, tc.keys, tc.limit)
+			assert.Equal(t, tc.from, from, "From mismatch")
+			assert.Equal(t, tc.to, to, "To mismatch")
+			assert.Equal(t, tc.next, next, "Next cursor mismatch")
+			assert.Equal(t, tc.prev, prev, "Prev cursor mismatch")
+		})
+	}
+}
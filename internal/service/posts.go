@@ -1,9 +1,12 @@
 package service
 
 import (
+	"time"
+
 	"github.com/shekshuev/gophertalk-backend/internal/config"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
 	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
 )
 
 type PostServiceImpl struct {
@@ -15,12 +18,75 @@ func NewPostServiceImpl(repo repository.PostRepository, cfg *config.Config) *Pos
 	return &PostServiceImpl{repo: repo, cfg: cfg}
 }
 
+const maxPollDuration = 7 * 24 * time.Hour
+
 func (s *PostServiceImpl) GetAllPosts(dto models.FilterPostDTO) ([]models.ReadPostDTO, error) {
-	return s.repo.GetAllPosts(dto)
+	if dto.Search != "" {
+		query, err := search.Parse(dto.Search)
+		if err != nil {
+			return nil, err
+		}
+		dto.Query = query
+	}
+	posts, err := s.repo.GetAllPosts(dto)
+	if err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		hidePollResults(posts[i].Poll)
+	}
+	return posts, nil
+}
+
+func (s *PostServiceImpl) GetPostsPage(dto models.FilterPostDTO) (*models.PostPageDTO, error) {
+	limit := dto.Limit
+	dto.Limit, dto.Offset, dto.Paged = limit+1, 0, true
+	posts, err := s.GetAllPosts(dto)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*models.Cursor, len(posts))
+	for i, post := range posts {
+		if !post.Pinned {
+			keys[i] = &models.Cursor{CreatedAt: post.CreatedAt, ID: post.ID}
+		}
+	}
+	from, to, next, prev := paginate(dto.Cursor, keys, limit)
+	return &models.PostPageDTO{Items: posts[from:to], NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (s *PostServiceImpl) GetPostByID(id, userID uint64) (*models.ReadPostDTO, error) {
+	post, err := s.repo.GetPostByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	hidePollResults(post.Poll)
+	return post, nil
 }
 
 func (s *PostServiceImpl) CreatePost(post models.CreatePostDTO) (*models.ReadPostDTO, error) {
-	return s.repo.CreatePost(post)
+	if post.Poll != nil {
+		// the poll of a scheduled post opens when the post goes live
+		opensAt := time.Now()
+		if post.PublishAt != nil && post.PublishAt.After(opensAt) {
+			opensAt = *post.PublishAt
+		}
+		if !post.Poll.ClosesAt.After(opensAt) || post.Poll.ClosesAt.After(opensAt.Add(maxPollDuration)) {
+			return nil, ErrInvalidPollDuration
+		}
+	}
+	state, err := postState(post.Draft, post.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+	post.State = state
+	post.Mentions = parseMentions(post.Text)
+	readDTO, err := s.repo.CreatePost(post)
+	if err != nil {
+		return nil, err
+	}
+	hidePollResults(readDTO.Poll)
+	return readDTO, nil
 }
 
 func (s *PostServiceImpl) DeletePost(id, ownerID uint64) error {
@@ -38,3 +104,68 @@ func (s *PostServiceImpl) LikePost(id, likedByID uint64) error {
 func (s *PostServiceImpl) DislikePost(id, dislikedByID uint64) error {
 	return s.repo.DislikePost(id, dislikedByID)
 }
+
+func (s *PostServiceImpl) GetDrafts(userID, limit, offset uint64) ([]models.ReadPostDTO, error) {
+	return s.repo.GetDrafts(userID, limit, offset)
+}
+
+func (s *PostServiceImpl) UpdateDraft(id, ownerID uint64, dto models.UpdateDraftDTO) (*models.ReadPostDTO, error) {
+	state, err := postState(true, dto.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+	dto.State = state
+	dto.Mentions = parseMentions(dto.Text)
+	return s.repo.UpdateDraft(id, ownerID, dto)
+}
+
+func (s *PostServiceImpl) PublishPost(id, ownerID uint64) error {
+	return s.repo.PublishPost(id, ownerID)
+}
+
+func (s *PostServiceImpl) PinPost(id, ownerID uint64) error {
+	return s.repo.PinPost(id, ownerID)
+}
+
+func (s *PostServiceImpl) UnpinPost(id, ownerID uint64) error {
+	return s.repo.UnpinPost(id, ownerID)
+}
+
+func (s *PostServiceImpl) MuteConversation(id, userID uint64) error {
+	return s.repo.MuteConversation(id, userID)
+}
+
+func (s *PostServiceImpl) UnmuteConversation(id, userID uint64) error {
+	return s.repo.UnmuteConversation(id, userID)
+}
+
+// postState decides how a new or edited post is stored: a post with a
+// publish time is scheduled, otherwise it is either a draft or published now.
+func postState(draft bool, publishAt *time.Time) (string, error) {
+	if publishAt != nil {
+		if !publishAt.After(time.Now()) {
+			return "", ErrInvalidPublishAt
+		}
+		return models.PostStateScheduled, nil
+	}
+	if draft {
+		return models.PostStateDraft, nil
+	}
+	return models.PostStatePublished, nil
+}
+
+func (s *PostServiceImpl) VotePoll(postID, userID uint64, dto models.VotePollDTO) error {
+	return s.repo.VotePoll(postID, userID, dto.OptionIDs)
+}
+
+// hidePollResults removes vote totals from a poll the user has not voted in
+// yet, so that results do not influence the vote. Closed polls show totals.
+func hidePollResults(poll *models.ReadPollDTO) {
+	if poll == nil || poll.Closed || len(poll.OwnVotes) > 0 {
+		return
+	}
+	poll.VotersCount = nil
+	for i := range poll.Options {
+		poll.Options[i].VotesCount = nil
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/shekshuev/gophertalk-backend/internal/config"
 	"github.com/shekshuev/gophertalk-backend/internal/mocks"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+	"github.com/shekshuev/gophertalk-backend/internal/search"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -109,6 +112,25 @@ func TestPostServiceImpl_CreatePost(t *testing.T) {
 			},
 			hasError: false,
 		},
+		{
+			name: "Success create with mentions",
+			createDTO: models.CreatePostDTO{
+				Text:   "Hello @johndoe",
+				UserID: 1,
+				Mentions: []models.CreateMentionDTO{
+					{UserName: "johndoe", Offset: 6, Length: 8},
+				},
+			},
+			readDTO: &models.ReadPostDTO{
+				ID:        1,
+				Text:      "Hello @johndoe",
+				CreatedAt: time.Now(),
+				Entities: []models.ReadPostEntityDTO{
+					{Type: models.EntityTypeMention, Offset: 6, Length: 8, UserID: 2, UserName: "johndoe"},
+				},
+			},
+			hasError: false,
+		},
 		{
 			name: "Error on insert SQL",
 			createDTO: models.CreatePostDTO{
@@ -125,10 +147,12 @@ func TestPostServiceImpl_CreatePost(t *testing.T) {
 	s := &PostServiceImpl{cfg: &cfg, repo: m}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			expected := tc.createDTO
+			expected.State = models.PostStatePublished
 			if !tc.hasError {
-				m.EXPECT().CreatePost(tc.createDTO).Return(tc.readDTO, nil)
+				m.EXPECT().CreatePost(expected).Return(tc.readDTO, nil)
 			} else {
-				m.EXPECT().CreatePost(tc.createDTO).Return(nil, sql.ErrNoRows)
+				m.EXPECT().CreatePost(expected).Return(nil, sql.ErrNoRows)
 			}
 
 			user, err := s.CreatePost(tc.createDTO)
@@ -298,3 +322,276 @@ func TestPostServiceImpl_DislikePost(t *testing.T) {
 		})
 	}
 }
+
+func TestPostServiceImpl_CreatePostWithPoll(t *testing.T) {
+	publishAt := time.Now().Add(48 * time.Hour)
+	testCases := []struct {
+		name      string
+		closesAt  time.Time
+		publishAt *time.Time
+		err       error
+	}{
+		{
+			name:     "Success create with poll",
+			closesAt: time.Now().Add(24 * time.Hour),
+			err:      nil,
+		},
+		{
+			name:      "Success schedule with poll",
+			closesAt:  publishAt.Add(6 * 24 * time.Hour),
+			publishAt: &publishAt,
+			err:       nil,
+		},
+		{
+			name:      "Poll closes before the post is published",
+			closesAt:  time.Now().Add(24 * time.Hour),
+			publishAt: &publishAt,
+			err:       ErrInvalidPollDuration,
+		},
+		{
+			name:     "Poll already closed",
+			closesAt: time.Now().Add(-time.Minute),
+			err:      ErrInvalidPollDuration,
+		},
+		{
+			name:     "Poll closes too late",
+			closesAt: time.Now().Add(8 * 24 * time.Hour),
+			err:      ErrInvalidPollDuration,
+		},
+	}
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			createDTO := models.CreatePostDTO{
+				Text:      "Tabs or spaces?",
+				UserID:    1,
+				Poll:      &models.CreatePollDTO{Options: []string{"Tabs", "Spaces"}, ClosesAt: tc.closesAt},
+				PublishAt: tc.publishAt,
+			}
+			if tc.err == nil {
+				var zero uint
+				expected := createDTO
+				expected.State = models.PostStatePublished
+				if tc.publishAt != nil {
+					expected.State = models.PostStateScheduled
+				}
+				m.EXPECT().CreatePost(expected).Return(&models.ReadPostDTO{
+					ID:   1,
+					Text: "Tabs or spaces?",
+					Poll: &models.ReadPollDTO{
+						ID:          1,
+						ClosesAt:    tc.closesAt,
+						VotersCount: &zero,
+						Options:     []models.ReadPollOptionDTO{{ID: 1, Text: "Tabs", VotesCount: &zero}},
+					},
+				}, nil)
+			}
+			post, err := s.CreatePost(createDTO)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			if tc.err == nil {
+				assert.Nil(t, post.Poll.VotersCount, "Totals should be hidden before voting")
+				assert.Nil(t, post.Poll.Options[0].VotesCount, "Totals should be hidden before voting")
+			}
+		})
+	}
+}
+
+func TestPostServiceImpl_VotePoll(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	m.EXPECT().VotePoll(uint64(1), uint64(2), []uint64{3}).Return(nil)
+	err := s.VotePoll(1, 2, models.VotePollDTO{OptionIDs: []uint64{3}})
+	assert.Nil(t, err, "Error is not nil")
+}
+
+func TestHidePollResults(t *testing.T) {
+	count := uint(5)
+	testCases := []struct {
+		name   string
+		poll   models.ReadPollDTO
+		hidden bool
+	}{
+		{
+			name:   "Open poll without vote",
+			poll:   models.ReadPollDTO{},
+			hidden: true,
+		},
+		{
+			name:   "Open poll with vote",
+			poll:   models.ReadPollDTO{OwnVotes: []uint64{1}},
+			hidden: false,
+		},
+		{
+			name:   "Closed poll without vote",
+			poll:   models.ReadPollDTO{Closed: true},
+			hidden: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			poll := tc.poll
+			poll.VotersCount = &count
+			poll.Options = []models.ReadPollOptionDTO{{ID: 1, VotesCount: &count}}
+			hidePollResults(&poll)
+			assert.Equal(t, tc.hidden, poll.VotersCount == nil, "Voters count visibility mismatch")
+			assert.Equal(t, tc.hidden, poll.Options[0].VotesCount == nil, "Votes count visibility mismatch")
+		})
+	}
+}
+
+func TestPostServiceImpl_CreatePostState(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		name      string
+		draft     bool
+		publishAt *time.Time
+		state     string
+		err       error
+	}{
+		{name: "Published now", state: models.PostStatePublished},
+		{name: "Draft", draft: true, state: models.PostStateDraft},
+		{name: "Scheduled", publishAt: &future, state: models.PostStateScheduled},
+		{name: "Scheduled draft", draft: true, publishAt: &future, state: models.PostStateScheduled},
+		{name: "Scheduled in the past", publishAt: &past, err: ErrInvalidPublishAt},
+	}
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			createDTO := models.CreatePostDTO{Text: "Hello", UserID: 1, Draft: tc.draft, PublishAt: tc.publishAt}
+			if tc.err == nil {
+				expected := createDTO
+				expected.State = tc.state
+				m.EXPECT().CreatePost(expected).Return(&models.ReadPostDTO{ID: 1, State: tc.state}, nil)
+			}
+			post, err := s.CreatePost(createDTO)
+			assert.Equal(t, tc.err, err, "Error mismatch")
+			if tc.err == nil {
+				assert.Equal(t, tc.state, post.State, "State mismatch")
+			}
+		})
+	}
+}
+
+func TestPostServiceImpl_UpdateDraft(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+
+	expected := models.UpdateDraftDTO{
+		Text:     "Hi @johndoe",
+		State:    models.PostStateDraft,
+		Mentions: []models.CreateMentionDTO{{UserName: "johndoe", Offset: 3, Length: 8}},
+	}
+	readDTO := &models.ReadPostDTO{ID: 1, Text: "Hi @johndoe", State: models.PostStateDraft}
+	m.EXPECT().UpdateDraft(uint64(1), uint64(2), expected).Return(readDTO, nil)
+	post, err := s.UpdateDraft(1, 2, models.UpdateDraftDTO{Text: "Hi @johndoe"})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, readDTO, post, "Post mismatch")
+
+	past := time.Now().Add(-time.Hour)
+	_, err = s.UpdateDraft(1, 2, models.UpdateDraftDTO{Text: "Hi", PublishAt: &past})
+	assert.Equal(t, ErrInvalidPublishAt, err, "Error mismatch")
+}
+
+func TestPostServiceImpl_PublishPost(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	m.EXPECT().PublishPost(uint64(1), uint64(2)).Return(nil)
+	assert.Nil(t, s.PublishPost(1, 2), "Error is not nil")
+	m.EXPECT().GetDrafts(uint64(2), uint64(10), uint64(0)).Return([]models.ReadPostDTO{}, nil)
+	drafts, err := s.GetDrafts(2, 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Empty(t, drafts, "Drafts should be empty")
+}
+
+func TestPostServiceImpl_PinPost(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	m.EXPECT().PinPost(uint64(1), uint64(2)).Return(repository.ErrTooManyPinned)
+	assert.ErrorIs(t, s.PinPost(1, 2), repository.ErrTooManyPinned)
+	m.EXPECT().UnpinPost(uint64(1), uint64(2)).Return(nil)
+	assert.Nil(t, s.UnpinPost(1, 2), "Error is not nil")
+}
+
+func TestPostServiceImpl_GetAllPostsSearch(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	m.EXPECT().GetAllPosts(models.FilterPostDTO{
+		UserID: 1,
+		Search: "gophers from:alice",
+		Query: &search.Query{Nodes: []search.Node{
+			search.Term{Text: "gophers"},
+			search.From{UserName: "alice"},
+		}},
+		Limit: 10,
+	}).Return([]models.ReadPostDTO{}, nil)
+	_, err := s.GetAllPosts(models.FilterPostDTO{UserID: 1, Search: "gophers from:alice", Limit: 10})
+	assert.Nil(t, err, "Error is not nil")
+
+	_, err = s.GetAllPosts(models.FilterPostDTO{UserID: 1, Search: `since:yesterday "open`, Limit: 10})
+	var parseErr *search.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Len(t, parseErr.Issues, 2)
+}
+
+func TestPostServiceImpl_GetPostByID(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	votersCount := uint(3)
+	m.EXPECT().GetPostByID(uint64(1), uint64(2)).Return(&models.ReadPostDTO{
+		ID:   1,
+		Poll: &models.ReadPollDTO{ID: 5, ClosesAt: time.Now().Add(time.Hour), VotersCount: &votersCount},
+	}, nil)
+	post, err := s.GetPostByID(1, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Nil(t, post.Poll.VotersCount, "Poll results should be hidden")
+	m.EXPECT().GetPostByID(uint64(1), uint64(2)).Return(nil, repository.ErrNotFound)
+	_, err = s.GetPostByID(1, 2)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestPostServiceImpl_GetPostsPage(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockPostRepository(ctrl)
+	s := &PostServiceImpl{cfg: &cfg, repo: m}
+	now := time.Now()
+	posts := []models.ReadPostDTO{
+		{ID: 1, CreatedAt: now.Add(-time.Hour), Pinned: true},
+		{ID: 9, CreatedAt: now},
+		{ID: 8, CreatedAt: now.Add(-time.Minute)},
+	}
+	m.EXPECT().GetAllPosts(models.FilterPostDTO{UserID: 1, OwnerID: 2, Limit: 3, Paged: true}).Return(posts, nil)
+	page, err := s.GetPostsPage(models.FilterPostDTO{UserID: 1, OwnerID: 2, Limit: 2, Offset: 5})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, posts[:2], page.Items, "Items mismatch")
+	assert.Equal(t, utils.EncodeCursor(models.Cursor{CreatedAt: now, ID: 9}), page.NextCursor, "Next cursor mismatch")
+	assert.Empty(t, page.PrevCursor, "First page has no prev cursor")
+}
@@ -2,13 +2,34 @@ package service
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/shekshuev/gophertalk-backend/internal/models"
 )
 
 type UserService interface {
-	GetAllUsers(limit, offset uint64) ([]models.ReadUserDTO, error)
-	GetUserByID(id uint64) (*models.ReadUserDTO, error)
+	GetAllUsers(viewerID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	GetUsersPage(viewerID uint64, cursor *models.Cursor, limit uint64) (*models.UserPageDTO, error)
+	GetUserByID(id, viewerID uint64) (*models.ReadUserDTO, error)
+	GetUserByUserName(userName string, viewerID uint64) (*models.ReadUserDTO, error)
+	SearchUsers(viewerID uint64, q string, limit, offset uint64) ([]models.ReadUserDTO, error)
+	FollowUser(followerID, followingID uint64) (bool, error)
+	UnfollowUser(followerID, followingID uint64) error
+	GetFollows(dto models.FilterFollowDTO) ([]models.ReadUserDTO, error)
+	GetFollowsPage(dto models.FilterFollowDTO) (*models.UserPageDTO, error)
+	GetFollowRequests(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	ApproveFollowRequest(userID, requesterID uint64) error
+	RejectFollowRequest(userID, requesterID uint64) error
+	BlockUser(blockerID, blockedID uint64) error
+	UnblockUser(blockerID, blockedID uint64) error
+	GetBlockedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	MuteUser(userID, mutedID uint64) error
+	UnmuteUser(userID, mutedID uint64) error
+	GetMutedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	GetRecommendedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	GetMutedWords(userID uint64) ([]models.ReadMutedWordDTO, error)
+	CreateMutedWord(userID uint64, dto models.CreateMutedWordDTO) (*models.ReadMutedWordDTO, error)
+	DeleteMutedWord(id, userID uint64) error
 	UpdateUser(id uint64, user models.UpdateUserDTO) (*models.ReadUserDTO, error)
 	DeleteUser(id uint64) error
 }
@@ -20,12 +41,96 @@ type AuthService interface {
 
 type PostService interface {
 	GetAllPosts(dto models.FilterPostDTO) ([]models.ReadPostDTO, error)
+	GetPostsPage(dto models.FilterPostDTO) (*models.PostPageDTO, error)
+	GetPostByID(id, userID uint64) (*models.ReadPostDTO, error)
 	CreatePost(post models.CreatePostDTO) (*models.ReadPostDTO, error)
 	DeletePost(id, ownerID uint64) error
 	ViewPost(id, viewedByID uint64) error
 	LikePost(id, likedByID uint64) error
 	DislikePost(id, dislikedByID uint64) error
+	VotePoll(postID, userID uint64, dto models.VotePollDTO) error
+	GetDrafts(userID, limit, offset uint64) ([]models.ReadPostDTO, error)
+	UpdateDraft(id, ownerID uint64, dto models.UpdateDraftDTO) (*models.ReadPostDTO, error)
+	PublishPost(id, ownerID uint64) error
+	PinPost(id, ownerID uint64) error
+	UnpinPost(id, ownerID uint64) error
+	MuteConversation(id, userID uint64) error
+	UnmuteConversation(id, userID uint64) error
+}
+
+type ListService interface {
+	CreateList(dto models.CreateListDTO) (*models.ReadListDTO, error)
+	GetListByID(id, viewerID uint64) (*models.ReadListDTO, error)
+	GetLists(dto models.FilterListDTO) ([]models.ReadListDTO, error)
+	UpdateList(id, ownerID uint64, dto models.UpdateListDTO) (*models.ReadListDTO, error)
+	DeleteList(id, ownerID uint64) error
+	AddListMember(id, ownerID, userID uint64) error
+	RemoveListMember(id, ownerID, userID uint64) error
+	GetListMembers(id, viewerID, limit, offset uint64) ([]models.ReadUserDTO, error)
+	SubscribeList(id, userID uint64) error
+	UnsubscribeList(id, userID uint64) error
+}
+
+type NotificationService interface {
+	GetNotificationsPage(userID uint64, cursor *models.Cursor, limit uint64) (*models.NotificationPageDTO, error)
+	CountUnread(userID uint64) (uint64, error)
+	MarkRead(id, userID uint64) error
+	MarkAllRead(userID uint64) error
+	GetPreferences(userID uint64) (models.NotificationPreferencesDTO, error)
+	UpdatePreferences(userID uint64, dto models.NotificationPreferencesDTO) (models.NotificationPreferencesDTO, error)
+}
+
+type WebhookService interface {
+	CreateWebhook(dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error)
+	GetWebhooks(userID uint64) ([]models.ReadWebhookDTO, error)
+	GetWebhookByID(id, userID uint64) (*models.ReadWebhookDTO, error)
+	UpdateWebhook(id, userID uint64, dto models.UpdateWebhookDTO) (*models.ReadWebhookDTO, error)
+	DeleteWebhook(id, userID uint64) error
+	GetDeliveries(webhookID, userID, limit, offset uint64) ([]models.ReadWebhookDeliveryDTO, error)
+	Redeliver(id, webhookID, userID uint64) (*models.ReadWebhookDeliveryDTO, error)
+}
+
+type ChatService interface {
+	CreateDirectChat(userID, recipientID uint64) (*models.ReadChatDTO, bool, error)
+	CreateGroupChat(dto models.CreateGroupChatDTO) (*models.ReadChatDTO, error)
+	UpdateChat(id, userID uint64, dto models.UpdateChatDTO) (*models.ReadChatDTO, error)
+	AddChatMember(id, userID, memberID uint64) error
+	UpdateChatMember(id, userID, memberID uint64, dto models.UpdateChatMemberDTO) error
+	RemoveChatMember(id, userID, memberID uint64) error
+	LeaveChat(id, userID uint64) error
+	GetChatsPage(userID uint64, cursor *models.Cursor, limit uint64) (*models.ChatPageDTO, error)
+	GetChatByID(id, userID uint64) (*models.ReadChatDTO, error)
+	CountUnread(userID uint64) (uint64, error)
+	MarkRead(id, userID, messageID uint64) error
+	MuteChat(id, userID uint64) error
+	UnmuteChat(id, userID uint64) error
+	GetMessagesPage(chatID, userID uint64, cursor *models.Cursor, limit uint64) (*models.MessagePageDTO, error)
+	SendMessage(dto models.CreateMessageDTO) (*models.ReadMessageDTO, error)
+	UnsendMessage(id, chatID, senderID uint64) error
+	DeleteMessage(id, chatID, userID uint64) error
+}
+
+type MediaService interface {
+	UploadMedia(dto models.UploadMediaDTO) (*models.ReadMediaDTO, error)
+	GetMediaByID(id, userID uint64) (*models.ReadMediaDTO, error)
+	UpdateMedia(id, ownerID uint64, dto models.UpdateMediaDTO) (*models.ReadMediaDTO, error)
+	OpenMediaFile(key string) (io.ReadCloser, error)
 }
 
 var ErrUserNotFound = fmt.Errorf("user not found")
 var ErrWrongPassword = fmt.Errorf("wrong password")
+var ErrMediaTooLarge = fmt.Errorf("media file is too large")
+var ErrUnsupportedMediaType = fmt.Errorf("unsupported media type")
+var ErrImageTooLarge = fmt.Errorf("image has too many pixels")
+var ErrInvalidPollDuration = fmt.Errorf("poll must close in the future and within 7 days")
+var ErrInvalidPublishAt = fmt.Errorf("publish_at must be in the future")
+var ErrFollowSelf = fmt.Errorf("users cannot follow themselves")
+var ErrBlockSelf = fmt.Errorf("users cannot block themselves")
+var ErrMuteSelf = fmt.Errorf("users cannot mute themselves")
+var ErrInvalidMutedWord = fmt.Errorf("muted word must contain a letter or digit, hashtags only letters, digits and underscores")
+var ErrInvalidMuteExpiry = fmt.Errorf("expires_at must be in the future")
+var ErrInvalidNotificationType = fmt.Errorf("unknown notification type")
+var ErrInvalidWebhookEvent = fmt.Errorf("unknown webhook event")
+var ErrAdminOnly = fmt.Errorf("only admins can do this")
+var ErrWebhookAddress = fmt.Errorf("webhook address is not public")
+var ErrMessageSelf = fmt.Errorf("users cannot message themselves")
@@ -1,12 +1,21 @@
 package service
 
 import (
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/shekshuev/gophertalk-backend/internal/config"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
 	"github.com/shekshuev/gophertalk-backend/internal/repository"
 	"github.com/shekshuev/gophertalk-backend/internal/utils"
 )
 
+var (
+	hashtagPattern = regexp.MustCompile(`^#[\p{L}\p{N}_]+$`)
+	keywordPattern = regexp.MustCompile(`[\p{L}\p{N}]`)
+)
+
 type UserServiceImpl struct {
 	repo repository.UserRepository
 	cfg  *config.Config
@@ -16,12 +25,135 @@ func NewUserServiceImpl(repo repository.UserRepository, cfg *config.Config) *Use
 	return &UserServiceImpl{repo: repo, cfg: cfg}
 }
 
-func (s *UserServiceImpl) GetAllUsers(limit, offset uint64) ([]models.ReadUserDTO, error) {
-	return s.repo.GetAllUsers(limit, offset)
+func (s *UserServiceImpl) GetAllUsers(viewerID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	return s.repo.GetAllUsers(viewerID, limit, offset)
+}
+
+func (s *UserServiceImpl) GetUsersPage(viewerID uint64, cursor *models.Cursor, limit uint64) (*models.UserPageDTO, error) {
+	users, err := s.repo.GetUsersPage(viewerID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*models.Cursor, len(users))
+	for i, user := range users {
+		keys[i] = &models.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+	}
+	from, to, next, prev := paginate(cursor, keys, limit)
+	return &models.UserPageDTO{Items: users[from:to], NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (s *UserServiceImpl) GetUserByID(id, viewerID uint64) (*models.ReadUserDTO, error) {
+	return s.repo.GetUserByID(id, viewerID)
+}
+
+func (s *UserServiceImpl) GetUserByUserName(userName string, viewerID uint64) (*models.ReadUserDTO, error) {
+	return s.repo.GetProfileByUserName(userName, viewerID)
+}
+
+func (s *UserServiceImpl) SearchUsers(viewerID uint64, q string, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	return s.repo.SearchUsers(viewerID, q, limit, offset)
+}
+
+// FollowUser reports whether the follow waits for the owner's approval.
+func (s *UserServiceImpl) FollowUser(followerID, followingID uint64) (bool, error) {
+	if followerID == followingID {
+		return false, ErrFollowSelf
+	}
+	return s.repo.FollowUser(followerID, followingID)
+}
+
+func (s *UserServiceImpl) UnfollowUser(followerID, followingID uint64) error {
+	return s.repo.UnfollowUser(followerID, followingID)
+}
+
+func (s *UserServiceImpl) GetFollows(dto models.FilterFollowDTO) ([]models.ReadUserDTO, error) {
+	return s.repo.GetFollows(dto)
+}
+
+func (s *UserServiceImpl) GetFollowsPage(dto models.FilterFollowDTO) (*models.UserPageDTO, error) {
+	limit := dto.Limit
+	dto.Limit = limit + 1
+	users, err := s.repo.GetFollows(dto)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*models.Cursor, len(users))
+	for i, user := range users {
+		keys[i] = &models.Cursor{CreatedAt: *user.FollowedAt, ID: user.ID}
+	}
+	from, to, next, prev := paginate(dto.Cursor, keys, limit)
+	return &models.UserPageDTO{Items: users[from:to], NextCursor: next, PrevCursor: prev}, nil
+}
+
+func (s *UserServiceImpl) GetFollowRequests(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	return s.repo.GetFollowRequests(userID, limit, offset)
+}
+
+func (s *UserServiceImpl) ApproveFollowRequest(userID, requesterID uint64) error {
+	return s.repo.ApproveFollowRequest(userID, requesterID)
+}
+
+func (s *UserServiceImpl) RejectFollowRequest(userID, requesterID uint64) error {
+	return s.repo.RejectFollowRequest(userID, requesterID)
+}
+
+func (s *UserServiceImpl) BlockUser(blockerID, blockedID uint64) error {
+	if blockerID == blockedID {
+		return ErrBlockSelf
+	}
+	return s.repo.BlockUser(blockerID, blockedID)
+}
+
+func (s *UserServiceImpl) UnblockUser(blockerID, blockedID uint64) error {
+	return s.repo.UnblockUser(blockerID, blockedID)
+}
+
+func (s *UserServiceImpl) GetBlockedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	return s.repo.GetBlockedUsers(userID, limit, offset)
+}
+
+func (s *UserServiceImpl) MuteUser(userID, mutedID uint64) error {
+	if userID == mutedID {
+		return ErrMuteSelf
+	}
+	return s.repo.MuteUser(userID, mutedID)
+}
+
+func (s *UserServiceImpl) UnmuteUser(userID, mutedID uint64) error {
+	return s.repo.UnmuteUser(userID, mutedID)
+}
+
+func (s *UserServiceImpl) GetMutedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	return s.repo.GetMutedUsers(userID, limit, offset)
+}
+
+func (s *UserServiceImpl) GetRecommendedUsers(userID, limit, offset uint64) ([]models.ReadUserDTO, error) {
+	return s.repo.GetRecommendedUsers(userID, limit, offset)
+}
+
+func (s *UserServiceImpl) GetMutedWords(userID uint64) ([]models.ReadMutedWordDTO, error) {
+	return s.repo.GetMutedWords(userID)
+}
+
+// CreateMutedWord stores the word lower-cased so muting is case-insensitive
+// and the same word cannot be muted twice.
+func (s *UserServiceImpl) CreateMutedWord(userID uint64, dto models.CreateMutedWordDTO) (*models.ReadMutedWordDTO, error) {
+	dto.Word = strings.ToLower(strings.TrimSpace(dto.Word))
+	if strings.HasPrefix(dto.Word, "#") {
+		if !hashtagPattern.MatchString(dto.Word) {
+			return nil, ErrInvalidMutedWord
+		}
+	} else if !keywordPattern.MatchString(dto.Word) {
+		return nil, ErrInvalidMutedWord
+	}
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidMuteExpiry
+	}
+	return s.repo.CreateMutedWord(userID, dto)
 }
 
-func (s *UserServiceImpl) GetUserByID(id uint64) (*models.ReadUserDTO, error) {
-	return s.repo.GetUserByID(id)
+func (s *UserServiceImpl) DeleteMutedWord(id, userID uint64) error {
+	return s.repo.DeleteMutedWord(id, userID)
 }
 
 func (s *UserServiceImpl) UpdateUser(id uint64, user models.UpdateUserDTO) (*models.ReadUserDTO, error) {
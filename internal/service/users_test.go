@@ -9,6 +9,7 @@ import (
 	"github.com/shekshuev/gophertalk-backend/internal/config"
 	"github.com/shekshuev/gophertalk-backend/internal/mocks"
 	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -56,11 +57,11 @@ func TestUserServiceImpl_GetAllUsers(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if !tc.hasError {
-				m.EXPECT().GetAllUsers(uint64(100), uint64(0)).Return(tc.readDTOs, nil)
+				m.EXPECT().GetAllUsers(uint64(1), uint64(100), uint64(0)).Return(tc.readDTOs, nil)
 			} else {
-				m.EXPECT().GetAllUsers(uint64(100), uint64(0)).Return(nil, sql.ErrNoRows)
+				m.EXPECT().GetAllUsers(uint64(1), uint64(100), uint64(0)).Return(nil, sql.ErrNoRows)
 			}
-			users, err := s.GetAllUsers(uint64(100), uint64(0))
+			users, err := s.GetAllUsers(uint64(1), uint64(100), uint64(0))
 			if tc.hasError {
 				assert.NotNil(t, err, "Error is nil")
 			} else {
@@ -107,12 +108,12 @@ func TestUserServiceImpl_GetUserByID(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if !tc.hasError {
-				m.EXPECT().GetUserByID(tc.id).Return(tc.readDTO, nil)
+				m.EXPECT().GetUserByID(tc.id, uint64(1)).Return(tc.readDTO, nil)
 			} else {
-				m.EXPECT().GetUserByID(tc.id).Return(nil, sql.ErrNoRows)
+				m.EXPECT().GetUserByID(tc.id, uint64(1)).Return(nil, sql.ErrNoRows)
 			}
 
-			user, err := s.GetUserByID(tc.id)
+			user, err := s.GetUserByID(tc.id, uint64(1))
 			if tc.hasError {
 				assert.NotNil(t, err, "Error is nil")
 				assert.Nil(t, user, "User should be nil")
@@ -226,3 +227,125 @@ func TestUserServiceImpl_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserServiceImpl_GetUsersPage(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockUserRepository(ctrl)
+	s := &UserServiceImpl{cfg: &cfg, repo: m}
+	now := time.Now()
+	cursor := &models.Cursor{CreatedAt: now, ID: 1}
+	users := []models.ReadUserDTO{
+		{ID: 2, CreatedAt: now.Add(time.Minute)},
+		{ID: 3, CreatedAt: now.Add(2 * time.Minute)},
+	}
+	m.EXPECT().GetUsersPage(uint64(1), cursor, uint64(3)).Return(users, nil)
+	page, err := s.GetUsersPage(1, cursor, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, users, page.Items, "Items mismatch")
+	assert.Empty(t, page.NextCursor, "Last page has no next cursor")
+	assert.Equal(t, utils.EncodeCursor(models.Cursor{CreatedAt: users[0].CreatedAt, ID: 2, Backward: true}), page.PrevCursor, "Prev cursor mismatch")
+}
+
+func TestUserServiceImpl_SearchUsers(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockUserRepository(ctrl)
+	s := &UserServiceImpl{cfg: &cfg, repo: m}
+	users := []models.ReadUserDTO{{ID: 1, UserName: "john"}}
+	m.EXPECT().SearchUsers(uint64(1), "joh", uint64(10), uint64(0)).Return(users, nil)
+	found, err := s.SearchUsers(1, "joh", 10, 0)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, users, found, "Users mismatch")
+	m.EXPECT().GetProfileByUserName("john", uint64(1)).Return(&users[0], nil)
+	user, err := s.GetUserByUserName("john", 1)
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, &users[0], user, "User mismatch")
+}
+
+func TestUserServiceImpl_FollowUser(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockUserRepository(ctrl)
+	s := &UserServiceImpl{cfg: &cfg, repo: m}
+	_, err := s.FollowUser(1, 1)
+	assert.ErrorIs(t, err, ErrFollowSelf)
+	m.EXPECT().FollowUser(uint64(1), uint64(2)).Return(true, nil)
+	pending, err := s.FollowUser(1, 2)
+	assert.Nil(t, err, "Error is not nil")
+	assert.True(t, pending, "Request should be pending")
+	m.EXPECT().UnfollowUser(uint64(1), uint64(2)).Return(nil)
+	assert.Nil(t, s.UnfollowUser(1, 2), "Error is not nil")
+}
+
+func TestUserServiceImpl_BlockUser(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockUserRepository(ctrl)
+	s := &UserServiceImpl{cfg: &cfg, repo: m}
+	assert.ErrorIs(t, s.BlockUser(1, 1), ErrBlockSelf)
+	m.EXPECT().BlockUser(uint64(1), uint64(2)).Return(nil)
+	assert.Nil(t, s.BlockUser(1, 2), "Error is not nil")
+	m.EXPECT().UnblockUser(uint64(1), uint64(2)).Return(nil)
+	assert.Nil(t, s.UnblockUser(1, 2), "Error is not nil")
+}
+
+func TestUserServiceImpl_CreateMutedWord(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockUserRepository(ctrl)
+	s := &UserServiceImpl{cfg: &cfg, repo: m}
+	past := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		name string
+		dto  models.CreateMutedWordDTO
+		want string
+		err  error
+	}{
+		{name: "Keyword is lower-cased", dto: models.CreateMutedWordDTO{Word: "  Election "}, want: "election"},
+		{name: "Hashtag", dto: models.CreateMutedWordDTO{Word: "#Spoilers"}, want: "#spoilers"},
+		{name: "Hashtag with punctuation", dto: models.CreateMutedWordDTO{Word: "#no.way"}, err: ErrInvalidMutedWord},
+		{name: "Only punctuation", dto: models.CreateMutedWordDTO{Word: "?!"}, err: ErrInvalidMutedWord},
+		{name: "Expired", dto: models.CreateMutedWordDTO{Word: "news", ExpiresAt: &past}, err: ErrInvalidMuteExpiry},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == nil {
+				m.EXPECT().CreateMutedWord(uint64(1), models.CreateMutedWordDTO{Word: tc.want}).
+					Return(&models.ReadMutedWordDTO{ID: 1, Word: tc.want}, nil)
+			}
+			word, err := s.CreateMutedWord(1, tc.dto)
+			assert.ErrorIs(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, tc.want, word.Word, "Word mismatch")
+			}
+		})
+	}
+	assert.ErrorIs(t, s.MuteUser(1, 1), ErrMuteSelf)
+}
+
+func TestUserServiceImpl_GetFollowsPage(t *testing.T) {
+	cfg := config.GetConfig()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockUserRepository(ctrl)
+	s := &UserServiceImpl{cfg: &cfg, repo: m}
+	now := time.Now()
+	first, second, third := now, now.Add(-time.Minute), now.Add(-2*time.Minute)
+	users := []models.ReadUserDTO{
+		{ID: 4, FollowedAt: &first},
+		{ID: 3, FollowedAt: &second},
+		{ID: 2, FollowedAt: &third},
+	}
+	m.EXPECT().GetFollows(models.FilterFollowDTO{UserID: 1, ViewerID: 1, Limit: 3}).Return(users, nil)
+	page, err := s.GetFollowsPage(models.FilterFollowDTO{UserID: 1, ViewerID: 1, Limit: 2})
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, users[:2], page.Items, "Items mismatch")
+	assert.Equal(t, utils.EncodeCursor(models.Cursor{CreatedAt: second, ID: 3}), page.NextCursor, "Next cursor mismatch")
+	assert.Empty(t, page.PrevCursor, "First page has no previous cursor")
+}
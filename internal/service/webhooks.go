@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/shekshuev/gophertalk-backend/internal/repository"
+)
+
+const (
+	WebhookEventHeader     = "X-GopherTalk-Event"
+	WebhookDeliveryHeader  = "X-GopherTalk-Delivery"
+	WebhookTimestampHeader = "X-GopherTalk-Timestamp"
+	WebhookSignatureHeader = "X-GopherTalk-Signature"
+)
+
+type WebhookServiceImpl struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    *config.Config
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewWebhookServiceImpl(repo repository.WebhookRepository, cfg *config.Config) *WebhookServiceImpl {
+	dialer := &net.Dialer{Timeout: cfg.WebhookTimeout, Control: dialPublicOnly}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: cfg.WebhookTimeout,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &WebhookServiceImpl{repo: repo, client: &http.Client{Timeout: cfg.WebhookTimeout, Transport: transport}, cfg: cfg}
+}
+
+// dialPublicOnly refuses connections to loopback, private, link-local and
+// other addresses that are not reachable from the internet, so webhooks
+// cannot be pointed at the internal network. It runs on the address being
+// dialed, after name resolution and for every redirect, which covers host
+// names resolving to such addresses as well.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddress, host)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which IsPrivate does not
+// cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if ip[0] == 0 || sharedAddressSpace.Contains(ip) {
+			return false
+		}
+	}
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// SignWebhook returns the signature sent with a delivery: the hex encoded
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed with the webhook's
+// secret. Receivers should compute it the same way and compare.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookServiceImpl) isAdmin(userID uint64) bool {
+	for _, adminID := range s.cfg.AdminUserIDs {
+		if adminID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWebhookEvents(events []string) error {
+	for _, event := range events {
+		known := false
+		for _, webhookEvent := range models.WebhookEvents {
+			known = known || event == webhookEvent
+		}
+		if !known {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
+
+// CreateWebhook generates the secret the deliveries are signed with. It is
+// returned this once. Only admins may register webhooks.
+func (s *WebhookServiceImpl) CreateWebhook(dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	if !s.isAdmin(dto.UserID) {
+		return nil, ErrAdminOnly
+	}
+	if err := validateWebhookEvents(dto.Events); err != nil {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	dto.Secret = hex.EncodeToString(secret)
+	return s.repo.CreateWebhook(dto)
+}
+
+func (s *WebhookServiceImpl) GetWebhooks(userID uint64) ([]models.ReadWebhookDTO, error) {
+	return s.repo.GetWebhooks(userID)
+}
+
+func (s *WebhookServiceImpl) GetWebhookByID(id, userID uint64) (*models.ReadWebhookDTO, error) {
+	return s.repo.GetWebhookByID(id, userID)
+}
+
+// UpdateWebhook is left to admins like CreateWebhook, so that a user who is
+// no longer one cannot point their webhooks elsewhere.
+func (s *WebhookServiceImpl) UpdateWebhook(id, userID uint64, dto models.UpdateWebhookDTO) (*models.ReadWebhookDTO, error) {
+	if !s.isAdmin(userID) {
+		return nil, ErrAdminOnly
+	}
+	if err := validateWebhookEvents(dto.Events); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateWebhook(id, userID, dto)
+}
+
+func (s *WebhookServiceImpl) DeleteWebhook(id, userID uint64) error {
+	return s.repo.DeleteWebhook(id, userID)
+}
+
+// GetDeliveries answers with ErrNotFound rather than an empty page when the
+// webhook is not the user's.
+func (s *WebhookServiceImpl) GetDeliveries(webhookID, userID, limit, offset uint64) ([]models.ReadWebhookDeliveryDTO, error) {
+	if _, err := s.repo.GetWebhookByID(webhookID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveries(webhookID, userID, limit, offset)
+}
+
+func (s *WebhookServiceImpl) Redeliver(id, webhookID, userID uint64) (*models.ReadWebhookDeliveryDTO, error) {
+	return s.repo.Redeliver(id, webhookID, userID)
+}
+
+// DeliverDue sends the deliveries that are due, in parallel, and records the
+// outcome of every attempt. It returns how many it attempted.
+func (s *WebhookServiceImpl) DeliverDue() (int, error) {
+	// the lease outlasts every attempt of the batch
+	leaseUntil := time.Now().Add(2*s.cfg.WebhookTimeout + time.Minute)
+	deliveries, err := s.repo.ClaimDeliveries(s.cfg.WebhookBatchSize, leaseUntil)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery models.PendingWebhookDeliveryDTO) {
+			defer wg.Done()
+			attempt := s.send(delivery)
+			if err := s.repo.RecordAttempt(attempt); err != nil {
+				log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (s *WebhookServiceImpl) send(delivery models.PendingWebhookDeliveryDTO) models.WebhookAttemptDTO {
+	attempt := models.WebhookAttemptDTO{DeliveryID: delivery.ID}
+	body, err := json.Marshal(models.WebhookPayloadDTO{
+		ID:        delivery.ID,
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GopherTalk-Webhook")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(delivery.Secret, timestamp, body))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.NextAttemptAt = s.retryAt(delivery.Attempts + 1)
+		return attempt
+	}
+	// only the status is kept: the body is none of our business and must not
+	// be shown back to whoever registered the endpoint
+	resp.Body.Close()
+	attempt.ResponseStatus = &resp.StatusCode
+	attempt.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !attempt.Succeeded {
+		attempt.Error = resp.Status
+		attempt.NextAttemptAt = s.retryAt(delivery.Attempts + 1)
+	}
+	return attempt
+}
+
+// retryAt backs off exponentially from WebhookRetryBase up to
+// WebhookRetryMax. It returns nil once WebhookMaxAttempts were made.
+func (s *WebhookServiceImpl) retryAt(attempts uint) *time.Time {
+	if int(attempts) >= s.cfg.WebhookMaxAttempts {
+		return nil
+	}
+	delay := s.cfg.WebhookRetryBase
+	for i := uint(1); i < attempts && delay < s.cfg.WebhookRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > s.cfg.WebhookRetryMax {
+		delay = s.cfg.WebhookRetryMax
+	}
+	next := time.Now().Add(delay)
+	return &next
+}
+
+// StartDeliveryJob sends due deliveries every WebhookInterval until Close.
+func (s *WebhookServiceImpl) StartDeliveryJob() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	ticker := time.NewTicker(s.cfg.WebhookInterval)
+	go func() {
+		defer close(s.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.DeliverDue(); err != nil {
+					log.Printf("Failed to deliver webhooks: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the delivery job and waits for the batch being sent.
+func (s *WebhookServiceImpl) Close() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shekshuev/gophertalk-backend/internal/config"
+	"github.com/shekshuev/gophertalk-backend/internal/mocks"
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookServiceImpl_CreateWebhook(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.AdminUserIDs = []uint64{9}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockWebhookRepository(ctrl)
+	s := NewWebhookServiceImpl(m, &cfg)
+
+	m.EXPECT().CreateWebhook(gomock.Any()).DoAndReturn(func(dto models.CreateWebhookDTO) (*models.ReadWebhookDTO, error) {
+		assert.Len(t, dto.Secret, 64, "Secret should be generated")
+		return &models.ReadWebhookDTO{ID: 1, Secret: dto.Secret}, nil
+	})
+	webhook, err := s.CreateWebhook(models.CreateWebhookDTO{UserID: 9, URL: "https://example.com", Events: []string{"post.created"}})
+	assert.Nil(t, err, "Error is not nil")
+	assert.NotEmpty(t, webhook.Secret, "Secret should be returned")
+
+	_, err = s.CreateWebhook(models.CreateWebhookDTO{UserID: 9, URL: "https://example.com", Events: []string{"post.liked"}})
+	assert.Equal(t, ErrInvalidWebhookEvent, err, "Unknown event should be rejected")
+
+	_, err = s.CreateWebhook(models.CreateWebhookDTO{UserID: 1, URL: "https://example.com", Events: []string{"post.created"}})
+	assert.Equal(t, ErrAdminOnly, err, "Only admins can register webhooks")
+
+	m.EXPECT().CreateWebhook(gomock.Any()).Return(&models.ReadWebhookDTO{ID: 2, AllUsers: true}, nil)
+	_, err = s.CreateWebhook(models.CreateWebhookDTO{UserID: 9, URL: "https://example.com", Events: []string{"post.created"}, AllUsers: true})
+	assert.Nil(t, err, "Admin should watch all users")
+}
+
+func TestWebhookServiceImpl_DeliverDue(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.WebhookMaxAttempts = 3
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockWebhookRepository(ctrl)
+	s := NewWebhookServiceImpl(m, &cfg)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		if r.Header.Get(WebhookSignatureHeader) != SignWebhook("secret", timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload models.WebhookPayloadDTO
+		json.Unmarshal(body, &payload)
+		if payload.Event == models.WebhookEventPostDeleted {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	// the test server listens on loopback, which the real client refuses
+	s.client = server.Client()
+
+	now := time.Now()
+	m.EXPECT().ClaimDeliveries(cfg.WebhookBatchSize, gomock.Any()).Return([]models.PendingWebhookDeliveryDTO{
+		{ID: 1, URL: server.URL, Secret: "secret", Event: models.WebhookEventPostCreated, Payload: []byte(`{"id":1}`), CreatedAt: now},
+		{ID: 2, URL: server.URL, Secret: "wrong", Event: models.WebhookEventPostCreated, Payload: []byte(`{"id":1}`), CreatedAt: now},
+		{ID: 3, URL: server.URL, Secret: "secret", Event: models.WebhookEventPostDeleted, Payload: []byte(`{"id":1}`), Attempts: 2, CreatedAt: now},
+	}, nil)
+	done := make(chan models.WebhookAttemptDTO, 3)
+	m.EXPECT().RecordAttempt(gomock.Any()).Times(3).DoAndReturn(func(dto models.WebhookAttemptDTO) error {
+		done <- dto
+		return nil
+	})
+	attempts := map[uint64]models.WebhookAttemptDTO{}
+	count, err := s.DeliverDue()
+	assert.Nil(t, err, "Error is not nil")
+	assert.Equal(t, 3, count, "Deliveries count mismatch")
+	for i := 0; i < 3; i++ {
+		attempt := <-done
+		attempts[attempt.DeliveryID] = attempt
+	}
+
+	assert.True(t, attempts[1].Succeeded, "Signed delivery should succeed")
+	assert.Equal(t, http.StatusNoContent, *attempts[1].ResponseStatus, "Status mismatch")
+
+	assert.False(t, attempts[2].Succeeded, "Badly signed delivery should fail")
+	assert.NotNil(t, attempts[2].NextAttemptAt, "Failed delivery should be retried")
+
+	assert.False(t, attempts[3].Succeeded, "Failing endpoint should fail")
+	assert.Nil(t, attempts[3].NextAttemptAt, "Delivery should give up after the last attempt")
+}
+
+func TestWebhookServiceImpl_sendRefusesInternalAddresses(t *testing.T) {
+	cfg := config.GetConfig()
+	s := NewWebhookServiceImpl(nil, &cfg)
+
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	attempt := s.send(models.PendingWebhookDeliveryDTO{ID: 1, URL: server.URL, Secret: "secret", Payload: []byte(`{}`)})
+	assert.False(t, requested, "Loopback endpoint should not be reached")
+	assert.False(t, attempt.Succeeded, "Delivery should fail")
+	assert.Contains(t, attempt.Error, ErrWebhookAddress.Error(), "Error mismatch")
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{ip: "93.184.216.34", public: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "10.1.2.3"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "169.254.169.254"},
+		{ip: "100.64.0.1"},
+		{ip: "0.0.0.0"},
+		{ip: "fd00:ec2::254"},
+		{ip: "fe80::1"},
+		{ip: "::ffff:127.0.0.1"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.public, isPublicIP(net.ParseIP(tc.ip)), "Mismatch for %s", tc.ip)
+	}
+}
+
+func TestWebhookServiceImpl_retryAt(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.WebhookRetryBase = time.Minute
+	cfg.WebhookRetryMax = 5 * time.Minute
+	cfg.WebhookMaxAttempts = 10
+	s := NewWebhookServiceImpl(nil, &cfg)
+
+	tests := []struct {
+		attempts uint
+		delay    time.Duration
+	}{
+		{attempts: 1, delay: time.Minute},
+		{attempts: 2, delay: 2 * time.Minute},
+		{attempts: 3, delay: 4 * time.Minute},
+		{attempts: 4, delay: 5 * time.Minute},
+		{attempts: 9, delay: 5 * time.Minute},
+	}
+	for _, tc := range tests {
+		next := s.retryAt(tc.attempts)
+		assert.WithinDuration(t, time.Now().Add(tc.delay), *next, time.Second, "Delay mismatch after %d attempts", tc.attempts)
+	}
+	assert.Nil(t, s.retryAt(10), "Delivery should give up after the last attempt")
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type LocalBlobStore struct {
+	root    string
+	baseURL string
+}
+
+func NewLocalBlobStore(root, baseURL string) *LocalBlobStore {
+	return &LocalBlobStore{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s *LocalBlobStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+func (s *LocalBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (s *LocalBlobStore) Delete(_ context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || cleaned[1:] != key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocalBlobStore(t.TempDir(), "/v1.0/media/files/")
+
+	err := s.Put(ctx, "media/test.png", bytes.NewReader([]byte("content")), 7, "image/png")
+	assert.NoError(t, err, "Error putting blob")
+
+	r, err := s.Get(ctx, "media/test.png")
+	assert.NoError(t, err, "Error getting blob")
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "content", string(data), "Blob content mismatch")
+	assert.Equal(t, "/v1.0/media/files/media/test.png", s.URL("media/test.png"), "URL mismatch")
+
+	assert.NoError(t, s.Delete(ctx, "media/test.png"), "Error deleting blob")
+	_, err = s.Get(ctx, "media/test.png")
+	assert.ErrorIs(t, err, ErrBlobNotFound, "Blob should be deleted")
+
+	for _, key := range []string{"", "../escape.png", "media/../../escape.png", "/absolute.png"} {
+		err := s.Put(ctx, key, bytes.NewReader(nil), 0, "image/png")
+		assert.ErrorIs(t, err, ErrInvalidKey, "Key %q should be rejected", key)
+	}
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3TimeFormat    = "20060102T150405Z"
+	s3DateFormat    = "20060102"
+	emptyBodySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3BlobStore talks to any S3-compatible service (AWS S3, MinIO, etc.)
+// using path-style URLs and Signature Version 4.
+type S3BlobStore struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3BlobStore(endpoint, region, bucket, accessKey, secretKey, publicURL string) *S3BlobStore {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if publicURL == "" {
+		publicURL = endpoint + "/" + bucket
+	}
+	return &S3BlobStore{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := s.do(req, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrBlobNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s.responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+func (s *S3BlobStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *S3BlobStore) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	if key == "" || strings.Contains(key, "..") {
+		return nil, ErrInvalidKey
+	}
+	u, err := url.Parse(s.endpoint + "/" + s.bucket + "/" + key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+}
+
+func (s *S3BlobStore) do(req *http.Request, body []byte) (*http.Response, error) {
+	payloadHash := emptyBodySHA256
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", s.now().UTC().Format(s3TimeFormat))
+	signRequest(req, payloadHash, s.accessKey, s.secretKey, s.region)
+	return s.client.Do(req)
+}
+
+func (s *S3BlobStore) responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+// signRequest adds a SigV4 Authorization header. The request must already
+// carry the x-amz-date header; host and all other set headers are signed.
+func signRequest(req *http.Request, payloadHash, accessKey, secretKey, region string) {
+	amzDate := req.Header.Get("x-amz-date")
+	date := amzDate[:len(s3DateFormat)]
+
+	headers := map[string]string{"host": req.Host}
+	if req.Host == "" {
+		headers["host"] = req.URL.Host
+	}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "authorization" || lower == "user-agent" {
+			continue
+		}
+		headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + s3Service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s3Algorithm, accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := values[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, url.QueryEscape(k)+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
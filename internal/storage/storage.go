@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+var ErrBlobNotFound = fmt.Errorf("blob not found")
+var ErrInvalidKey = fmt.Errorf("invalid blob key")
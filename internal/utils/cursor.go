@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a cursor into an opaque URL-safe token.
+func EncodeCursor(cursor models.Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*models.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor models.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
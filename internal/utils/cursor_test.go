@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shekshuev/gophertalk-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	testCases := []struct {
+		name    string
+		token   string
+		cursor  *models.Cursor
+		wantErr bool
+	}{
+		{
+			name:   "Round trip",
+			cursor: &models.Cursor{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC), ID: 42, Backward: true},
+		},
+		{
+			name:    "Not base64",
+			token:   "???",
+			wantErr: true,
+		},
+		{
+			name:    "Not JSON",
+			token:   "bm90IGpzb24",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := tc.token
+			if tc.cursor != nil {
+				token = EncodeCursor(*tc.cursor)
+				assert.NotContains(t, token, "=", "Token should not be padded")
+			}
+			cursor, err := DecodeCursor(token)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCursor)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tc.cursor.CreatedAt.Equal(cursor.CreatedAt), "Time mismatch")
+			assert.Equal(t, tc.cursor.ID, cursor.ID, "ID mismatch")
+			assert.Equal(t, tc.cursor.Backward, cursor.Backward, "Direction mismatch")
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	MimeJPEG = "image/jpeg"
+	MimePNG  = "image/png"
+	MimeGIF  = "image/gif"
+	MimeWebP = "image/webp"
+)
+
+var ErrUnsupportedImage = fmt.Errorf("unsupported image format")
+var ErrMalformedImage = fmt.Errorf("malformed image")
+
+var imageExtensions = map[string]string{
+	MimeJPEG: ".jpg",
+	MimePNG:  ".png",
+	MimeGIF:  ".gif",
+	MimeWebP: ".webp",
+}
+
+func DetectImageType(data []byte) (string, error) {
+	mimeType := http.DetectContentType(data)
+	if _, ok := imageExtensions[mimeType]; !ok {
+		return "", ErrUnsupportedImage
+	}
+	return mimeType, nil
+}
+
+func ImageExtension(mimeType string) string {
+	return imageExtensions[mimeType]
+}
+
+func ImageSize(data []byte, mimeType string) (int, int, error) {
+	if mimeType == MimeWebP {
+		return webpSize(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, ErrMalformedImage
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// StripImageMetadata removes EXIF and XMP blocks, which may carry GPS
+// coordinates and camera details, without re-encoding the pixels.
+func StripImageMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case MimeJPEG:
+		return stripJPEGMetadata(data)
+	case MimePNG:
+		return stripPNGMetadata(data)
+	case MimeWebP:
+		return stripWebPMetadata(data)
+	default:
+		return data, nil
+	}
+}
+
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMalformedImage
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, ErrMalformedImage
+		}
+		marker := data[i+1]
+		if marker == 0xDA {
+			// start of scan: the rest is entropy-coded image data
+			return append(out, data[i:]...), nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrMalformedImage
+		}
+		// APP1 holds EXIF and XMP, APP13 holds Photoshop IRB/IPTC
+		if marker != 0xE1 && marker != 0xED {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return nil, ErrMalformedImage
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMalformedImage
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrMalformedImage
+		}
+		chunkType := string(data[i+4 : i+8])
+		switch chunkType {
+		case "eXIf", "tEXt", "iTXt", "zTXt":
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if chunkType == "IEND" {
+			return out, nil
+		}
+	}
+	return nil, ErrMalformedImage
+}
+
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformedImage
+	}
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	i := 12
+	for i+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + size + size%2
+		if end > len(data) {
+			return nil, ErrMalformedImage
+		}
+		chunkType := string(data[i : i+4])
+		switch chunkType {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			// clear the EXIF and XMP presence flags
+			chunk[8] &^= 0x08 | 0x04
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, ErrMalformedImage
+	}
+	chunk := data[12:]
+	switch string(chunk[:4]) {
+	case "VP8X":
+		w := int(chunk[12]) | int(chunk[13])<<8 | int(chunk[14])<<16
+		h := int(chunk[15]) | int(chunk[16])<<8 | int(chunk[17])<<16
+		return w + 1, h + 1, nil
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(chunk[9:13])
+		return int(bits&0x3FFF) + 1, int((bits>>14)&0x3FFF) + 1, nil
+	case "VP8 ":
+		w := int(binary.LittleEndian.Uint16(chunk[14:16]) & 0x3FFF)
+		h := int(binary.LittleEndian.Uint16(chunk[16:18]) & 0x3FFF)
+		return w, h, nil
+	}
+	return 0, 0, ErrMalformedImage
+}
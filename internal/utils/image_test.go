@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func jpegWithExif(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, testImage(32, 16), nil))
+	data := buf.Bytes()
+	exif := []byte("Exif\x00\x00GPS-SECRET")
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(exif)+2))
+	segment = append(segment, exif...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func pngWithText(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(32, 16)))
+	data := buf.Bytes()
+	payload := []byte("tEXtComment\x00GPS-SECRET")
+	chunk := make([]byte, 4)
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)-4))
+	chunk = append(chunk, payload...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(payload))
+	chunk = append(chunk, crc...)
+	// insert right after the IHDR chunk
+	ihdrEnd := 8 + 12 + 13
+	return append(append(append([]byte{}, data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+func TestDetectImageType(t *testing.T) {
+	mimeType, err := DetectImageType(jpegWithExif(t))
+	assert.NoError(t, err)
+	assert.Equal(t, MimeJPEG, mimeType)
+
+	mimeType, err = DetectImageType(pngWithText(t))
+	assert.NoError(t, err)
+	assert.Equal(t, MimePNG, mimeType)
+
+	_, err = DetectImageType([]byte("<html><body>not an image</body></html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+func TestStripImageMetadata(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		mimeType string
+	}{
+		{name: "JPEG with EXIF", data: jpegWithExif(t), mimeType: MimeJPEG},
+		{name: "PNG with text chunk", data: pngWithText(t), mimeType: MimePNG},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, bytes.Contains(tc.data, []byte("GPS-SECRET")))
+			stripped, err := StripImageMetadata(tc.data, tc.mimeType)
+			assert.NoError(t, err, "Error stripping metadata")
+			assert.False(t, bytes.Contains(stripped, []byte("GPS-SECRET")), "Metadata was not stripped")
+			_, _, err = image.Decode(bytes.NewReader(stripped))
+			assert.NoError(t, err, "Stripped image should still decode")
+		})
+	}
+
+	_, err := StripImageMetadata([]byte("garbage"), MimeJPEG)
+	assert.ErrorIs(t, err, ErrMalformedImage)
+}
+
+func TestImageSize(t *testing.T) {
+	w, h, err := ImageSize(pngWithText(t), MimePNG)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, w)
+	assert.Equal(t, 16, h)
+
+	// 1x1 lossless WebP
+	webp := []byte{
+		'R', 'I', 'F', 'F', 0x1a, 0, 0, 0, 'W', 'E', 'B', 'P',
+		'V', 'P', '8', 'L', 0x0d, 0, 0, 0, 0x2f, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	w, h, err = ImageSize(webp, MimeWebP)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, w)
+	assert.Equal(t, 1, h)
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+var ErrQueueFull = errors.New("worker queue is full")
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+// Pool runs submitted jobs on a fixed number of goroutines. Jobs wait in a
+// bounded queue; when it is full Submit fails instead of blocking the caller.
+type Pool struct {
+	jobs   chan func()
+	wg     sync.WaitGroup
+	lock   sync.RWMutex
+	closed bool
+}
+
+func NewPool(workers, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool{jobs: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.run()
+	}
+	return p
+}
+
+func (p *Pool) run() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		p.exec(job)
+	}
+}
+
+func (p *Pool) exec(job func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Print("Worker job panicked: ", r)
+		}
+	}()
+	job()
+}
+
+func (p *Pool) Submit(job func()) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish.
+func (p *Pool) Close() {
+	p.lock.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.lock.Unlock()
+	p.wg.Wait()
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_Submit(t *testing.T) {
+	p := NewPool(4, 100)
+	var count int64
+	for i := 0; i < 100; i++ {
+		err := p.Submit(func() { atomic.AddInt64(&count, 1) })
+		assert.Nil(t, err, "Error is not nil")
+	}
+	p.Close()
+	assert.Equal(t, int64(100), count, "Not all jobs were executed")
+	assert.Equal(t, ErrPoolClosed, p.Submit(func() {}), "Closed pool accepted a job")
+}
+
+func TestPool_QueueFull(t *testing.T) {
+	p := NewPool(1, 1)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	assert.Nil(t, p.Submit(func() {
+		close(started)
+		<-release
+	}))
+	<-started
+	assert.Nil(t, p.Submit(func() {}), "Queue should accept one job")
+	assert.Equal(t, ErrQueueFull, p.Submit(func() {}), "Queue should be full")
+	close(release)
+	p.Close()
+}
+
+func TestPool_Panic(t *testing.T) {
+	p := NewPool(1, 2)
+	var done int64
+	assert.Nil(t, p.Submit(func() { panic("boom") }))
+	assert.Nil(t, p.Submit(func() { atomic.AddInt64(&done, 1) }))
+	p.Close()
+	assert.Equal(t, int64(1), done, "Worker did not survive panic")
+}